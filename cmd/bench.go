@@ -85,8 +85,11 @@ func benchmarkFile(file string) error {
 		return fmt.Errorf("failed to get file info: %w", err)
 	}
 
-	// Create reader with default config
-	reader, err := pkg.NewReader(f, pkg.DefaultConfig())
+	// Create reader with default config, reusing the record buffer
+	// since rows are only counted
+	cfg := pkg.DefaultConfig()
+	cfg.ReuseRecord = true
+	reader, err := pkg.NewReader(f, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create reader: %w", err)
 	}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ooyeku/csv_parser/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	countTSV      bool
+	countNoHeader bool
+)
+
+// countCmd represents the count command
+var countCmd = &cobra.Command{
+	Use:   "count [file]",
+	Short: "Count the rows of a CSV file",
+	Long: `Stream through a CSV file and print its number of data rows and columns,
+without loading it into memory. A quoted field spanning several lines is part
+of one row, so the count can differ from the file's line count.
+
+Example:
+  csv_parser count data.csv
+  csv_parser count --no-header data.csv`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filePath := args[0]
+
+		file, err := pkg.OpenMaybeCompressed(filePath)
+		if err != nil {
+			return fmt.Errorf("error opening file: %w", err)
+		}
+		defer func(file io.ReadCloser) {
+			err := file.Close()
+			if err != nil {
+				fmt.Printf("Error closing file: %v\n", err)
+			}
+		}(file)
+
+		cfg := pkg.DefaultConfig()
+		if countTSV {
+			cfg = pkg.TSVConfig()
+		}
+		cfg.NoHeader = countNoHeader
+		rows, fields, err := pkg.CountRecords(file, cfg)
+		if err != nil {
+			return fmt.Errorf("error reading records: %w", err)
+		}
+
+		fmt.Printf("Rows: %d\n", rows)
+		fmt.Printf("Columns: %d\n", fields)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(countCmd)
+	countCmd.Flags().BoolVar(&countTSV, "tsv", false, "Read tab-separated values")
+	countCmd.Flags().BoolVar(&countNoHeader, "no-header", false, "Count the first record as a row")
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ooyeku/csv_parser/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffKeys   []string
+	diffOutput string
+	diffTSV    bool
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff [old.csv] [new.csv]",
+	Short: "Show the rows added, removed and changed between two CSV files",
+	Long: `Compare two versions of a CSV file, matching rows by one or more key columns
+whose values are unique in each file. Reports:
+- Columns added or removed between the files
+- Rows only in the new file (added) or only in the old file (removed)
+- Rows whose values changed, with the old and new value of each changed column
+
+Values are compared over the columns both files have, so reordering columns is
+not a change.
+
+Example:
+  csv_parser diff yesterday.csv today.csv --key id
+  csv_parser diff --key date,store --output json old.csv new.csv`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := strings.ToLower(diffOutput)
+		if output != "table" && output != "json" {
+			return fmt.Errorf("unknown output format %q (use table or json)", diffOutput)
+		}
+		if len(diffKeys) == 0 {
+			return fmt.Errorf("no key columns given, use --key")
+		}
+
+		cfg := pkg.DefaultConfig()
+		if diffTSV {
+			cfg = pkg.TSVConfig()
+		}
+		oldTable, err := readDiffTable(args[0], cfg)
+		if err != nil {
+			return err
+		}
+		newTable, err := readDiffTable(args[1], cfg)
+		if err != nil {
+			return err
+		}
+
+		diffs, err := oldTable.DiffKeys(newTable, diffKeys)
+		if err != nil {
+			return fmt.Errorf("error comparing files: %w", err)
+		}
+		added, removed := oldTable.DiffHeaders(newTable)
+
+		if output == "json" {
+			return printDiffJSON(oldTable, newTable, diffs, added, removed)
+		}
+		printDiffTable(oldTable, newTable, diffs, added, removed)
+		return nil
+	},
+}
+
+// readDiffTable reads one of the files being compared
+func readDiffTable(path string, cfg pkg.Config) (*pkg.Table, error) {
+	file, err := pkg.OpenMaybeCompressed(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+	table, err := pkg.ReadTable(file, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return table, nil
+}
+
+// printDiffTable prints the schema changes and a summary, then a table with
+// one line per added or removed row and per changed value
+func printDiffTable(oldTable, newTable *pkg.Table, diffs []pkg.RowDiff, added, removed []string) {
+	if len(added) > 0 {
+		fmt.Printf("Columns added: %s\n", strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		fmt.Printf("Columns removed: %s\n", strings.Join(removed, ", "))
+	}
+
+	counts := make(map[pkg.DiffKind]int)
+	for _, d := range diffs {
+		counts[d.Kind]++
+	}
+	fmt.Printf("Rows added: %d, removed: %d, changed: %d\n",
+		counts[pkg.RowAdded], counts[pkg.RowRemoved], counts[pkg.RowChanged])
+	if len(diffs) == 0 {
+		return
+	}
+
+	result := pkg.NewTable([]string{"change", strings.Join(diffKeys, ","), "column", "old", "new"})
+	for _, d := range diffs {
+		if d.Kind != pkg.RowChanged {
+			_ = result.AddRow([]string{d.Kind.String(), d.Key, "", "", ""})
+			continue
+		}
+		for _, col := range d.Columns {
+			oldIdx, _ := oldTable.ColumnIndex(col)
+			newIdx, _ := newTable.ColumnIndex(col)
+			_ = result.AddRow([]string{d.Kind.String(), d.Key, col, d.Old[oldIdx], d.New[newIdx]})
+		}
+	}
+	fmt.Println()
+	_, _ = result.WriteFormatted(os.Stdout, pkg.DefaultFormat())
+}
+
+// diffReport is the JSON form of the differences between two files
+type diffReport struct {
+	AddedColumns   []string        `json:"added_columns"`
+	RemovedColumns []string        `json:"removed_columns"`
+	Rows           []diffReportRow `json:"rows"`
+}
+
+type diffReportRow struct {
+	Change  string            `json:"change"`
+	Key     string            `json:"key"`
+	Columns []string          `json:"columns,omitempty"`
+	Old     map[string]string `json:"old,omitempty"`
+	New     map[string]string `json:"new,omitempty"`
+}
+
+// printDiffJSON prints the differences as a JSON object, with each row as an
+// object keyed by column name
+func printDiffJSON(oldTable, newTable *pkg.Table, diffs []pkg.RowDiff, added, removed []string) error {
+	report := diffReport{
+		AddedColumns:   append([]string{}, added...),
+		RemovedColumns: append([]string{}, removed...),
+		Rows:           make([]diffReportRow, 0, len(diffs)),
+	}
+	for _, d := range diffs {
+		report.Rows = append(report.Rows, diffReportRow{
+			Change:  d.Kind.String(),
+			Key:     d.Key,
+			Columns: d.Columns,
+			Old:     rowObject(oldTable.Headers, d.Old),
+			New:     rowObject(newTable.Headers, d.New),
+		})
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// rowObject maps headers to the values of row, or returns nil for a nil row
+func rowObject(headers, row []string) map[string]string {
+	if row == nil {
+		return nil
+	}
+	obj := make(map[string]string, len(headers))
+	for i, h := range headers {
+		obj[h] = row[i]
+	}
+	return obj
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringSliceVarP(&diffKeys, "key", "k", nil, "Key column(s) identifying a row, e.g. id or date,store")
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "table", "Output format (table, json)")
+	diffCmd.Flags().BoolVar(&diffTSV, "tsv", false, "Read tab-separated values")
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiffCommand(t *testing.T) {
+	dir := t.TempDir()
+	oldFile := filepath.Join(dir, "old.csv")
+	newFile := filepath.Join(dir, "new.csv")
+	if err := os.WriteFile(oldFile, []byte("id,name,salary\n1,John,1000\n2,Jane,2000\n3,Bob,1500\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	// Jane's salary and name changed, Bob removed, Amy added and a column added
+	if err := os.WriteFile(newFile, []byte("id,name,salary,dept\n1,John,1000,ops\n2,Janet,2500,dev\n4,Amy,1800,ops\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	diffKeys, diffOutput = []string{"id"}, "json"
+	defer func() { diffKeys, diffOutput = nil, "table" }()
+
+	printed, runErr := captureStdout(t, func() error {
+		return diffCmd.RunE(diffCmd, []string{oldFile, newFile})
+	})
+	if runErr != nil {
+		t.Fatalf("diff error = %v", runErr)
+	}
+
+	var report diffReport
+	if err := json.Unmarshal(printed, &report); err != nil {
+		t.Fatalf("diff output %q is not JSON: %v", printed, err)
+	}
+	if !reflect.DeepEqual(report.AddedColumns, []string{"dept"}) || len(report.RemovedColumns) != 0 {
+		t.Errorf("columns added = %v, removed = %v, want [dept] and none", report.AddedColumns, report.RemovedColumns)
+	}
+	want := []diffReportRow{
+		{Change: "changed", Key: "2", Columns: []string{"name", "salary"},
+			Old: map[string]string{"id": "2", "name": "Jane", "salary": "2000"},
+			New: map[string]string{"id": "2", "name": "Janet", "salary": "2500", "dept": "dev"}},
+		{Change: "removed", Key: "3", Old: map[string]string{"id": "3", "name": "Bob", "salary": "1500"}},
+		{Change: "added", Key: "4", New: map[string]string{"id": "4", "name": "Amy", "salary": "1800", "dept": "ops"}},
+	}
+	if !reflect.DeepEqual(report.Rows, want) {
+		t.Errorf("diff rows = %+v, want %+v", report.Rows, want)
+	}
+
+	diffKeys = []string{"missing"}
+	if _, err := captureStdout(t, func() error { return diffCmd.RunE(diffCmd, []string{oldFile, newFile}) }); err == nil {
+		t.Error("diff with a missing key column should fail")
+	}
+}
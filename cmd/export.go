@@ -11,20 +11,38 @@ import (
 )
 
 var (
-	format string
+	format          string
+	exportDelimiter string
+	exportQuote     string
+	exportCRLF      bool
+	exportQuoteAll  bool
+	exportTSV       bool
+	exportInDelim   string
+	exportAppend    bool
+	exportSheet     string
+	exportFreeze    bool
+	exportDryRun    bool
 )
 
 // exportCmd represents the export command
 var exportCmd = &cobra.Command{
-	Use:   "export [input.csv] [output.json|html]",
+	Use:   "export [input.csv] [output.json|jsonl|html|csv|tsv|md|xlsx]",
 	Short: "Export CSV data to different formats",
-	Long: `Export CSV data to different formats (JSON, HTML).
+	Long: `Export CSV data to different formats (JSON, JSON Lines, HTML, CSV, TSV, Markdown, Excel).
 Automatically detects output format from file extension.
 
 Example:
   csv_parser export data.csv output.json
+  csv_parser export data.csv output.jsonl
   csv_parser export data.csv output.html
-  csv_parser export --format=json data.csv output.txt`,
+  csv_parser export data.csv output.md
+  csv_parser export --sheet Sales --freeze-header data.csv output.xlsx
+  csv_parser export --delimiter=";" data.csv output.csv
+  csv_parser export --tsv data.tsv output.csv
+  csv_parser export --input-delimiter=";" data.csv output.csv
+  csv_parser export --append batch.csv daily.csv
+  csv_parser export --format=json data.csv output.txt
+  csv_parser export --dry-run data.csv output.json`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		inputFile := args[0]
@@ -37,32 +55,89 @@ Example:
 			switch ext {
 			case ".json":
 				exportFormat = "json"
+			case ".jsonl", ".ndjson":
+				exportFormat = "jsonl"
 			case ".html":
 				exportFormat = "html"
+			case ".csv":
+				exportFormat = "csv"
+			case ".tsv":
+				exportFormat = "tsv"
+			case ".md", ".markdown":
+				exportFormat = "md"
+			case ".xlsx":
+				exportFormat = "xlsx"
 			default:
 				return fmt.Errorf("unknown output format: %s", ext)
 			}
 		}
 
+		if exportAppend && exportFormat != "csv" && exportFormat != "tsv" {
+			return fmt.Errorf("--append only supports csv and tsv output")
+		}
+
+		outDelimiter, err := pkg.ParseDelimiter(exportDelimiter)
+		if err != nil {
+			return err
+		}
+		inDelimiter, err := pkg.ParseDelimiter(exportInDelim)
+		if err != nil {
+			return fmt.Errorf("input %w", err)
+		}
+		outQuote, err := pkg.ParseQuote(exportQuote)
+		if err != nil {
+			return err
+		}
+		if outQuote == 0 && (exportFormat == "csv" || exportFormat == "tsv") {
+			return fmt.Errorf("quote must not be empty for CSV output")
+		}
+
 		// Read input CSV
-		input, err := os.Open(inputFile)
+		input, err := pkg.OpenMaybeCompressed(inputFile)
 		if err != nil {
 			return fmt.Errorf("error opening input file: %w", err)
 		}
 		defer input.Close()
 
 		// Parse CSV
-		table, err := pkg.ReadTable(input, pkg.DefaultConfig())
+		inCfg := pkg.DefaultConfig()
+		inCfg.Delimiter = inDelimiter
+		if exportTSV {
+			inCfg = pkg.TSVConfig()
+		}
+		table, err := pkg.ReadTable(input, inCfg)
 		if err != nil {
 			return fmt.Errorf("error reading CSV: %w", err)
 		}
 
-		// Create output file
-		output, err := os.Create(outputFile)
+		if exportDryRun {
+			fmt.Printf("Dry run: would export %d rows and %d columns to %s as %s\n",
+				len(table.Rows), len(table.Headers), outputFile, exportFormat)
+			types := table.GetTypes()
+			for i, h := range table.Headers {
+				fmt.Printf("%d. %s (%v)\n", i+1, h, types[i])
+			}
+			return nil
+		}
+
+		// Create output file, or open it for appending
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if exportAppend {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		output, err := os.OpenFile(outputFile, flags, 0o644)
 		if err != nil {
 			return fmt.Errorf("error creating output file: %w", err)
 		}
 		defer output.Close()
+		existing := false
+		if exportAppend {
+			info, err := output.Stat()
+			if err != nil {
+				return fmt.Errorf("error reading output file: %w", err)
+			}
+			existing = info.Size() > 0
+		}
 
 		// Export based on format
 		switch exportFormat {
@@ -70,10 +145,39 @@ Example:
 			if err := table.ExportToJSON(output); err != nil {
 				return fmt.Errorf("error exporting to JSON: %w", err)
 			}
+		case "jsonl", "ndjson":
+			if err := table.ExportToJSONL(output); err != nil {
+				return fmt.Errorf("error exporting to JSON Lines: %w", err)
+			}
 		case "html":
 			if err := table.ExportToHTML(output); err != nil {
 				return fmt.Errorf("error exporting to HTML: %w", err)
 			}
+		case "csv", "tsv":
+			cfg := pkg.DefaultConfig()
+			cfg.Delimiter = outDelimiter
+			if exportFormat == "tsv" {
+				cfg.Delimiter = '\t'
+			}
+			cfg.Quote = outQuote
+			if exportCRLF {
+				cfg.LineTerminator = "\r\n"
+			}
+			cfg.QuoteAll = exportQuoteAll
+			// A file being appended to already has its header
+			cfg.SkipHeaderOnWrite = existing
+			if err := table.WriteCSV(output, cfg); err != nil {
+				return fmt.Errorf("error exporting to CSV: %w", err)
+			}
+		case "md", "markdown":
+			if err := table.ExportToMarkdown(output); err != nil {
+				return fmt.Errorf("error exporting to Markdown: %w", err)
+			}
+		case "xlsx":
+			opts := pkg.XLSXOptions{SheetName: exportSheet, FreezeHeader: exportFreeze}
+			if err := table.ExportToXLSX(output, opts); err != nil {
+				return fmt.Errorf("error exporting to Excel: %w", err)
+			}
 		default:
 			return fmt.Errorf("unsupported format: %s", exportFormat)
 		}
@@ -85,5 +189,15 @@ Example:
 
 func init() {
 	rootCmd.AddCommand(exportCmd)
-	exportCmd.Flags().StringVarP(&format, "format", "f", "", "Export format (json, html)")
+	exportCmd.Flags().StringVarP(&format, "format", "f", "", "Export format (json, jsonl, html, csv, tsv, md, xlsx)")
+	exportCmd.Flags().StringVarP(&exportDelimiter, "delimiter", "d", ",", "Field delimiter for CSV output (\\t or tab for a tab)")
+	exportCmd.Flags().StringVar(&exportInDelim, "input-delimiter", ",", "Field delimiter of the input (\\t or tab for a tab)")
+	exportCmd.Flags().BoolVar(&exportTSV, "tsv", false, "Read the input as tab-separated values")
+	exportCmd.Flags().StringVarP(&exportQuote, "quote", "q", "\"", "Quote character for CSV output")
+	exportCmd.Flags().BoolVar(&exportCRLF, "crlf", false, "End CSV records with \\r\\n instead of \\n")
+	exportCmd.Flags().BoolVar(&exportQuoteAll, "quote-all", false, "Quote every field in CSV output")
+	exportCmd.Flags().StringVar(&exportSheet, "sheet", "", "Worksheet name for xlsx output (default Sheet1)")
+	exportCmd.Flags().BoolVar(&exportFreeze, "freeze-header", false, "Keep the header row in view in xlsx output")
+	exportCmd.Flags().BoolVar(&exportDryRun, "dry-run", false, "Read the input and report the rows, columns and types without writing output")
+	exportCmd.Flags().BoolVar(&exportAppend, "append", false, "Append CSV rows to the output file, writing the header only if it is empty")
 }
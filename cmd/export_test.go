@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportDryRun(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "data.csv")
+	if err := os.WriteFile(input, []byte("id,name,score\n1,Ann,9.5\n2,Bob,7\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	output := filepath.Join(dir, "out.json")
+
+	exportDryRun = true
+	defer func() { exportDryRun = false }()
+
+	printed, runErr := captureStdout(t, func() error {
+		return exportCmd.RunE(exportCmd, []string{input, output})
+	})
+	if runErr != nil {
+		t.Fatalf("export --dry-run error = %v", runErr)
+	}
+	for _, want := range []string{"2 rows and 3 columns", "1. id (integer)", "2. name (string)", "3. score (float)"} {
+		if !strings.Contains(string(printed), want) {
+			t.Errorf("output %q does not contain %q", printed, want)
+		}
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("export --dry-run created %s (stat error %v)", output, err)
+	}
+}
+
+func TestExportEmptyQuote(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "data.csv")
+	if err := os.WriteFile(input, []byte("id,name\n1,Ann\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	exportQuote = ""
+	defer func() { exportQuote = "\"" }()
+
+	// Only CSV output uses the quote
+	if err := exportCmd.RunE(exportCmd, []string{input, filepath.Join(dir, "out.json")}); err != nil {
+		t.Errorf("export to JSON with an empty quote error = %v", err)
+	}
+	err := exportCmd.RunE(exportCmd, []string{input, filepath.Join(dir, "out.csv")})
+	if err == nil || !strings.Contains(err.Error(), "quote must not be empty") {
+		t.Errorf("export to CSV with an empty quote error = %v, want empty quote error", err)
+	}
+}
+
+// captureStdout runs fn and returns what it printed to stdout
+func captureStdout(t *testing.T, fn func() error) ([]byte, error) {
+	t.Helper()
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+	os.Stdout = w
+	runErr := fn()
+	os.Stdout = stdout
+	w.Close()
+	printed, _ := io.ReadAll(r)
+	return printed, runErr
+}
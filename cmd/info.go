@@ -2,7 +2,7 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"io"
 
 	"github.com/ooyeku/csv_parser/pkg"
 	"github.com/spf13/cobra"
@@ -15,47 +15,100 @@ var infoCmd = &cobra.Command{
 	Long: `Display basic information about a CSV file including:
 - Number of rows
 - Number of columns
+- Fields per row, flagging ragged rows with too few or too many fields
+- Approximate memory needed to load the file as a table
 - Sample of first few rows
+- Line endings, flagging files that mix them
 - Detected delimiter (if different from default)
 
 Example:
-  csv_parser info data.csv`,
+  csv_parser info data.csv
+  csv_parser info --no-header data.csv
+  csv_parser info --tsv data.tsv`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		filePath := args[0]
 
 		// Open the file
-		file, err := os.Open(filePath)
+		file, err := pkg.OpenMaybeCompressed(filePath)
 		if err != nil {
 			return fmt.Errorf("error opening file: %w", err)
 		}
-		defer func(file *os.File) {
+		defer func(file io.ReadCloser) {
 			err := file.Close()
 			if err != nil {
 				fmt.Printf("Error closing file: %v\n", err)
 			}
 		}(file)
 
-		// Create reader with default config
+		// Keep only the first rows in memory and just count the rest. Ragged
+		// rows are fitted to the header rather than rejected, so they can be
+		// reported.
 		cfg := pkg.DefaultConfig()
-		table, err := pkg.ReadTable(file, cfg)
+		if infoTSV {
+			cfg = pkg.TSVConfig()
+		}
+		cfg.ReuseRecord = true
+		cfg.NoHeader = infoNoHeader
+		cfg.OnRagged = pkg.RaggedTruncate
+		reader, err := pkg.NewReader(file, cfg)
+		if err != nil {
+			return fmt.Errorf("error creating reader: %w", err)
+		}
+		table, err := reader.ToTableN(infoSampleRows)
 		if err != nil {
 			return fmt.Errorf("error reading table: %w", err)
 		}
+		ragged := reader.RaggedRows()
+		for {
+			record, err := reader.ReadRecord()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("error reading record: %w", err)
+			}
+			if len(record) != len(table.Headers) {
+				ragged++
+			}
+		}
+		totalRows, totalFields := reader.RecordsRead(), reader.FieldsRead()
+		if !cfg.NoHeader && totalRows > 0 {
+			// Leave out the header
+			totalRows--
+			totalFields -= int64(len(table.Headers))
+		}
 
 		// Display information
 		fmt.Printf("File: %s\n", filePath)
-		fmt.Printf("Total Rows: %d\n", len(table.Rows))
+		fmt.Printf("Total Rows: %d\n", totalRows)
 		fmt.Printf("Total Columns: %d\n", len(table.Headers))
+		fmt.Printf("Total Fields: %d\n", totalFields)
+		if minFields, maxFields := reader.FieldCountRange(); minFields == maxFields {
+			fmt.Printf("Fields per Row: %d\n", maxFields)
+		} else {
+			fmt.Printf("Fields per Row: %d to %d (%d ragged rows)\n", minFields, maxFields, ragged)
+		}
+		if sampled := len(table.Rows); sampled > 0 {
+			// Scale the sample's size up to the whole file
+			estimate := table.MemoryEstimate() * totalRows / int64(sampled)
+			fmt.Printf("Memory to Load: ~%s\n", pkg.FormatBytes(estimate))
+		}
+		lf, crlf, cr := reader.LineEndingStats()
+		fmt.Printf("Line Endings: %d LF, %d CRLF, %d CR", lf, crlf, cr)
+		if pkg.MixedLineEndings(lf, crlf, cr) {
+			fmt.Print(" (mixed)")
+		}
+		fmt.Println()
 
 		fmt.Println("\nColumn Information:")
-		for i, header := range table.Headers {
-			colType, _ := table.GetColumnType(header)
-			col, _ := table.GetColumn(header)
-
+		if totalRows > int64(len(table.Rows)) {
+			fmt.Printf("(types inferred from the first %d rows)\n", len(table.Rows))
+		}
+		for i, col := range table.Columns() {
 			// Get sample of unique values
 			uniqueVals := make(map[string]struct{})
-			for _, v := range col[:m(len(col), 5)] {
+			for _, v := range col.Values[:m(len(col.Values), 5)] {
 				uniqueVals[v] = struct{}{}
 			}
 			samples := make([]string, 0, len(uniqueVals))
@@ -63,8 +116,8 @@ Example:
 				samples = append(samples, v)
 			}
 
-			fmt.Printf("%d. %s\n", i+1, header)
-			fmt.Printf("   Type: %v\n", colType)
+			fmt.Printf("%d. %s\n", i+1, col.Name)
+			fmt.Printf("   Type: %v\n", col.Type)
 			fmt.Printf("   Sample Values: %v\n", samples)
 		}
 
@@ -76,6 +129,15 @@ Example:
 	},
 }
 
+var (
+	infoNoHeader bool
+	infoTSV      bool
+)
+
+// infoSampleRows caps the rows info keeps in memory for type detection and
+// the preview
+const infoSampleRows = 1000
+
 func previewTable(t *pkg.Table) string {
 	preview := pkg.NewTable(t.Headers)
 	for i := 0; i < m(5, len(t.Rows)); i++ {
@@ -96,4 +158,7 @@ func m(a, b int) int {
 
 func init() {
 	rootCmd.AddCommand(infoCmd)
+
+	infoCmd.Flags().BoolVar(&infoNoHeader, "no-header", false, "Treat the first row as data and name columns col1, col2, ...")
+	infoCmd.Flags().BoolVar(&infoTSV, "tsv", false, "Read tab-separated values")
 }
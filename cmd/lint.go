@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ooyeku/csv_parser/pkg"
+	"github.com/spf13/cobra"
+)
+
+var lintSample int
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint [file]",
+	Short: "Report likely problems in a CSV file without failing",
+	Long: `Stream through a CSV file and report warnings such as:
+- Rows whose field count differs from the header
+- Values with leading or trailing whitespace
+- Columns mixing numbers, booleans and text
+- Empty or duplicate headers
+- A UTF-8 byte order mark or mixed line endings
+
+Warnings never fail the command; only a file that cannot be parsed does.
+With --sample N only the first N rows are linted, so problems further into
+the file are not reported.
+
+Example:
+  csv_parser lint data.csv
+  csv_parser lint --sample 1000 data.csv`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filePath := args[0]
+
+		file, err := pkg.OpenMaybeCompressed(filePath)
+		if err != nil {
+			return fmt.Errorf("error opening file: %w", err)
+		}
+		defer func(file io.ReadCloser) {
+			err := file.Close()
+			if err != nil {
+				fmt.Printf("Error closing file: %v\n", err)
+			}
+		}(file)
+
+		maxRows := -1
+		if lintSample > 0 {
+			maxRows = lintSample
+		}
+		warnings := pkg.LintCSVN(file, pkg.DefaultConfig(), maxRows)
+
+		fmt.Printf("File: %s\n", filePath)
+		if lintSample > 0 {
+			fmt.Printf("Sampled: first %d rows at most\n", lintSample)
+		}
+		if len(warnings) == 0 {
+			fmt.Println("No problems found.")
+			return nil
+		}
+
+		fmt.Printf("\n%d findings:\n", len(warnings))
+		failed := false
+		for _, w := range warnings {
+			fmt.Printf("- %s\n", w)
+			if w.Severity == pkg.SeverityError {
+				failed = true
+			}
+		}
+		if failed {
+			return fmt.Errorf("lint stopped: file could not be parsed")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.Flags().IntVar(&lintSample, "sample", 0, "Lint only the first N rows (0 lints every row)")
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ooyeku/csv_parser/pkg"
+	"github.com/spf13/cobra"
+)
+
+var mergeTSV bool
+
+// mergeCmd represents the merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge [output.csv] [input.csv...]",
+	Short: "Combine CSV files with the same columns into one",
+	Long: `Combine CSV files that share the same header into a single file, keeping
+one header row followed by the rows of each input in the order given. Inputs
+whose headers differ from the first input's are rejected.
+
+Example:
+  csv_parser merge all.csv jan.csv feb.csv mar.csv
+  csv_parser merge --tsv all.tsv jan.tsv feb.tsv`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputFile, inputs := args[0], args[1:]
+
+		cfg := pkg.DefaultConfig()
+		if mergeTSV {
+			cfg = pkg.TSVConfig()
+		}
+		table, err := pkg.ReadTables(inputs, cfg)
+		if err != nil {
+			return fmt.Errorf("error reading input: %w", err)
+		}
+
+		output, err := os.Create(outputFile)
+		if err != nil {
+			return fmt.Errorf("error creating output file: %w", err)
+		}
+		defer output.Close()
+		if err := table.WriteCSV(output, cfg); err != nil {
+			return fmt.Errorf("error writing output: %w", err)
+		}
+		if err := output.Close(); err != nil {
+			return fmt.Errorf("error writing output: %w", err)
+		}
+
+		fmt.Printf("Merged %d rows from %d files into %s\n", len(table.Rows), len(inputs), outputFile)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+	mergeCmd.Flags().BoolVar(&mergeTSV, "tsv", false, "Read and write tab-separated values")
+}
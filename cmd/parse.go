@@ -4,15 +4,24 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/ooyeku/csv_parser/pkg"
 	"github.com/spf13/cobra"
 )
 
 var (
-	delimiter string
-	quote     string
-	trim      bool
+	delimiter    string
+	quote        string
+	trim         bool
+	parseHead    int
+	parseTail    int
+	parseColumns []string
+	parseOutput  string
+	whitespace   bool
+	fixedWidths  []int
+	parseTSV     bool
+	outputDelim  string
 )
 
 // parseCmd represents the parse command
@@ -24,17 +33,24 @@ delimiter, quote character, and whitespace trimming.
 
 Example:
   csv_parser parse data.csv
-  csv_parser parse --delimiter=";" --quote="'" data.csv`,
+  csv_parser parse --delimiter=";" --quote="'" data.csv
+  csv_parser parse --tsv data.tsv
+  csv_parser parse --head 10 --columns name,email data.csv
+  csv_parser parse --tail 5 data.csv
+  csv_parser parse --output json data.csv
+  csv_parser parse --delimiter ";" --output csv --output-delimiter "," data.csv
+  csv_parser parse --whitespace report.txt
+  csv_parser parse --widths 10,12,3 report.txt`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		filePath := args[0]
 
 		// Open the file
-		file, err := os.Open(filePath)
+		file, err := pkg.OpenMaybeCompressed(filePath)
 		if err != nil {
 			return fmt.Errorf("error opening file: %w", err)
 		}
-		defer func(file *os.File) {
+		defer func(file io.ReadCloser) {
 			err := file.Close()
 			if err != nil {
 				fmt.Printf("Error closing file: %v\n", err)
@@ -42,38 +58,69 @@ Example:
 		}(file)
 
 		// Create config
+		delim, err := pkg.ParseDelimiter(delimiter)
+		if err != nil {
+			return err
+		}
+		if parseTSV {
+			delim = '\t'
+		}
+		quoteChar, err := pkg.ParseQuote(quote)
+		if err != nil {
+			return err
+		}
 		cfg := pkg.Config{
-			Delimiter:   []rune(delimiter)[0],
-			Quote:       []rune(quote)[0],
+			Delimiter:   delim,
+			Quote:       quoteChar,
 			TrimLeading: trim,
-		}
 
-		// Create reader
-		reader, err := pkg.NewReader(file, cfg)
-		if err != nil {
-			return fmt.Errorf("error creating reader: %w", err)
+			WhitespaceDelimited: whitespace,
+			FixedWidths:         fixedWidths,
 		}
 
-		// Read and display records
-		for {
-			record, err := reader.ReadRecord()
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				return fmt.Errorf("error reading record: %w", err)
+		// Read and display the header and the selected records
+		opts := pkg.SelectOptions{Head: parseHead, Tail: parseTail, Columns: parseColumns}
+		output := strings.ToLower(parseOutput)
+		if output == "tsv" || output == "csv" {
+			// Records are printed as they are, so ragged rows need no fixing;
+			// json and table output still reject them
+			cfg.OnRagged = pkg.RaggedKeep
+		}
+		switch output {
+		case "tsv":
+			err = pkg.StreamSelect(file, cfg, opts, func(record []string) error {
+				fmt.Println(strings.Join(record, "\t"))
+				return nil
+			})
+		case "csv":
+			outCfg := pkg.DefaultConfig()
+			if outCfg.Delimiter, err = pkg.ParseDelimiter(outputDelim); err != nil {
+				return err
 			}
-
-			// Print the record
-			for i, field := range record {
-				if i > 0 {
-					fmt.Print("\t")
-				}
-				fmt.Print(field)
+			var w *pkg.Writer
+			if w, err = pkg.NewWriter(os.Stdout, outCfg); err != nil {
+				return err
+			}
+			err = pkg.StreamSelect(file, cfg, opts, w.Write)
+			if err == nil {
+				err = w.Flush()
+			}
+		case "json", "table":
+			var table *pkg.Table
+			if table, err = pkg.ReadSelection(file, cfg, opts); err != nil {
+				break
 			}
-			fmt.Println()
+			if output == "json" {
+				err = table.ExportToJSON(os.Stdout)
+			} else {
+				_, err = table.WriteFormatted(os.Stdout, pkg.DefaultFormat())
+			}
+		default:
+			return fmt.Errorf("unknown output format %q (use tsv, csv, json or table)", parseOutput)
+		}
+		if err != nil {
+			return fmt.Errorf("error reading records: %w", err)
 		}
-
 		return nil
 	},
 }
@@ -82,7 +129,15 @@ func init() {
 	rootCmd.AddCommand(parseCmd)
 
 	// Add flags
-	parseCmd.Flags().StringVarP(&delimiter, "delimiter", "d", ",", "Field delimiter character")
-	parseCmd.Flags().StringVarP(&quote, "quote", "q", "\"", "Quote character")
+	parseCmd.Flags().StringVarP(&delimiter, "delimiter", "d", ",", "Field delimiter character (\\t or tab for a tab)")
+	parseCmd.Flags().BoolVar(&parseTSV, "tsv", false, "Read tab-separated values")
+	parseCmd.Flags().StringVarP(&quote, "quote", "q", "\"", "Quote character (empty to disable quoting)")
 	parseCmd.Flags().BoolVarP(&trim, "trim", "t", false, "Trim leading whitespace in unquoted fields")
+	parseCmd.Flags().IntVar(&parseHead, "head", 0, "Show only the first N rows")
+	parseCmd.Flags().IntVar(&parseTail, "tail", 0, "Show only the last N rows")
+	parseCmd.Flags().StringVarP(&parseOutput, "output", "o", "tsv", "Output format (tsv, csv, json, table)")
+	parseCmd.Flags().StringVar(&outputDelim, "output-delimiter", ",", "Field delimiter for --output csv (\\t or tab for a tab)")
+	parseCmd.Flags().BoolVarP(&whitespace, "whitespace", "w", false, "Split fields on runs of spaces and tabs")
+	parseCmd.Flags().IntSliceVar(&fixedWidths, "widths", nil, "Read fixed-width columns of these byte widths")
+	parseCmd.Flags().StringSliceVar(&parseColumns, "columns", nil, "Show only these columns, in this order")
 }
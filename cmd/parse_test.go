@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRagged(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "ragged.csv")
+	if err := os.WriteFile(input, []byte("a,b,c\n1,2\n3,4,5,6\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	defer func() { parseOutput = "tsv" }()
+
+	// Ragged records are printed as they are
+	for output, want := range map[string]string{
+		"tsv": "a\tb\tc\n1\t2\n3\t4\t5\t6\n",
+		"csv": "a,b,c\n1,2\n3,4,5,6\n",
+	} {
+		parseOutput = output
+		printed, err := captureStdout(t, func() error { return parseCmd.RunE(parseCmd, []string{input}) })
+		if err != nil {
+			t.Fatalf("parse --output %s error = %v", output, err)
+		}
+		if string(printed) != want {
+			t.Errorf("parse --output %s printed %q, want %q", output, printed, want)
+		}
+	}
+
+	// A table cannot hold them
+	parseOutput = "json"
+	if _, err := captureStdout(t, func() error { return parseCmd.RunE(parseCmd, []string{input}) }); err == nil {
+		t.Error("parse --output json of a ragged file should fail")
+	}
+}
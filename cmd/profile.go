@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ooyeku/csv_parser/pkg"
+	"github.com/spf13/cobra"
+)
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile [file]",
+	Short: "Report per-column statistics for a CSV file",
+	Long: `Load a CSV file and report, for every column:
+- Detected type
+- Null count and percentage
+- Number of distinct values
+- Minimum and maximum
+- Mean, median and standard deviation of numeric columns
+- The most frequent values of other columns
+
+Example:
+  csv_parser profile data.csv
+  csv_parser profile --tsv data.tsv`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filePath := args[0]
+
+		file, err := pkg.OpenMaybeCompressed(filePath)
+		if err != nil {
+			return fmt.Errorf("error opening file: %w", err)
+		}
+		defer func(file io.ReadCloser) {
+			err := file.Close()
+			if err != nil {
+				fmt.Printf("Error closing file: %v\n", err)
+			}
+		}(file)
+
+		cfg := pkg.DefaultConfig()
+		if profileTSV {
+			cfg = pkg.TSVConfig()
+		}
+		table, err := pkg.ReadTable(file, cfg)
+		if err != nil {
+			return fmt.Errorf("error reading table: %w", err)
+		}
+
+		fmt.Printf("File: %s\n", filePath)
+		fmt.Printf("Rows: %d\n", len(table.Rows))
+		fmt.Printf("Columns: %d\n\n", len(table.Headers))
+		fmt.Println(pkg.ProfileTable(table).Format(pkg.DefaultFormat()))
+		return nil
+	},
+}
+
+var profileTSV bool
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.Flags().BoolVar(&profileTSV, "tsv", false, "Read tab-separated values")
+}
@@ -2,14 +2,18 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"strconv"
 
 	"github.com/ooyeku/csv_parser/pkg"
 	"github.com/spf13/cobra"
 )
 
-var strict bool
+var (
+	strict         bool
+	schemaFile     string
+	validateSample int
+)
 
 // validateCmd represents the validate command
 var validateCmd = &cobra.Command{
@@ -20,19 +24,29 @@ var validateCmd = &cobra.Command{
 - Proper quote and delimiter usage
 - No malformed rows
 
+With --sample N only the first N rows are read and validated, a quick check
+for large files before a full pass.
+
+With --schema, values are also checked against a JSON column spec such as:
+  [{"name": "age", "type": "integer", "required": true, "min": 0, "max": 120},
+   {"name": "email", "pattern": "[^@]+@[^@]+"},
+   {"name": "status", "allowed_values": ["active", "inactive"]}]
+
 Example:
   csv_parser validate data.csv
-  csv_parser validate --strict data.csv`,
+  csv_parser validate --strict data.csv
+  csv_parser validate --schema schema.json data.csv
+  csv_parser validate --sample 1000 data.csv`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		filePath := args[0]
 
 		// Open the file
-		file, err := os.Open(filePath)
+		file, err := pkg.OpenMaybeCompressed(filePath)
 		if err != nil {
 			return fmt.Errorf("error opening file: %w", err)
 		}
-		defer func(file *os.File) {
+		defer func(file io.ReadCloser) {
 			err := file.Close()
 			if err != nil {
 				fmt.Printf("Error closing file: %v\n", err)
@@ -41,50 +55,41 @@ Example:
 
 		// Create reader with default config
 		cfg := pkg.DefaultConfig()
-		table, err := pkg.ReadTable(file, cfg)
+		maxRows := -1
+		if validateSample > 0 {
+			maxRows = validateSample
+		}
+		reader, err := pkg.NewReader(file, cfg)
+		if err != nil {
+			return err
+		}
+		table, err := reader.ToTableN(maxRows)
 		if err != nil {
 			return fmt.Errorf("error reading table: %w", err)
 		}
+		// Only a record after the sample means later rows went unchecked
+		sampled := false
+		if validateSample > 0 && len(table.Rows) == validateSample {
+			_, err := reader.ReadRecord()
+			sampled = err != io.EOF
+		}
 
-		var errors []string
+		errors := table.Validate(strict)
 
-		// Validate column types
-		for _, header := range table.Headers {
-			colType, _ := table.GetColumnType(header)
-			col, _ := table.GetColumn(header)
-
-			for i, val := range col {
-				switch colType {
-				case pkg.TypeInteger:
-					if _, err := strconv.ParseInt(val, 10, 64); err != nil && val != "" {
-						errors = append(errors, fmt.Sprintf("Row %d, Column %s: Invalid integer value %q",
-							i+1, header, val))
-					}
-				case pkg.TypeFloat:
-					if _, err := strconv.ParseFloat(val, 64); err != nil && val != "" {
-						errors = append(errors, fmt.Sprintf("Row %d, Column %s: Invalid float value %q",
-							i+1, header, val))
-					}
-				case pkg.TypeBoolean:
-					if val != "" && val != "true" && val != "false" {
-						errors = append(errors, fmt.Sprintf("Row %d, Column %s: Invalid boolean value %q",
-							i+1, header, val))
-					}
-				default:
-					panic("unhandled default case")
-				}
-
-				// In strict mode, check for empty fields
-				if strict && val == "" {
-					errors = append(errors, fmt.Sprintf("Row %d, Column %s: Empty field not allowed in strict mode",
-						i+1, header))
-				}
+		if schemaFile != "" {
+			schema, err := loadSchema(schemaFile)
+			if err != nil {
+				return err
 			}
+			errors = append(errors, table.ValidateSchema(schema)...)
 		}
 
 		// Display results
 		fmt.Printf("File: %s\n", filePath)
 		fmt.Printf("Rows processed: %d\n", len(table.Rows))
+		if sampled {
+			fmt.Printf("Sampled: first %d rows only; later rows were not checked\n", validateSample)
+		}
 		fmt.Printf("Columns: %d\n", len(table.Headers))
 
 		if len(errors) > 0 {
@@ -111,5 +116,16 @@ Example:
 func init() {
 	rootCmd.AddCommand(validateCmd)
 	validateCmd.Flags().BoolVarP(&strict, "strict", "s", false,
-		"Enable strict validation (no empty fields allowed)")
+		"Enable strict validation (no empty fields allowed, except in entirely empty columns)")
+	validateCmd.Flags().StringVar(&schemaFile, "schema", "", "JSON schema file declaring column constraints")
+	validateCmd.Flags().IntVar(&validateSample, "sample", 0, "Validate only the first N rows (0 validates every row)")
+}
+
+func loadSchema(path string) (pkg.Schema, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening schema: %w", err)
+	}
+	defer file.Close()
+	return pkg.LoadSchema(file)
 }
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateSample(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "data.csv")
+	if err := os.WriteFile(input, []byte("id,name\n1,Ann\n2,Bob\n3,Cy\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	defer func() { validateSample = 0 }()
+
+	// The note is shown only if rows were left unchecked
+	for sample, wantNote := range map[int]bool{2: true, 3: false, 5: false} {
+		validateSample = sample
+		printed, err := captureStdout(t, func() error { return validateCmd.RunE(validateCmd, []string{input}) })
+		if err != nil {
+			t.Fatalf("validate --sample %d error = %v", sample, err)
+		}
+		if got := strings.Contains(string(printed), "Sampled:"); got != wantNote {
+			t.Errorf("validate --sample %d printed the sample note = %v, want %v:\n%s", sample, got, wantNote, printed)
+		}
+	}
+}
@@ -105,6 +105,7 @@ func main() {
 		AlternateRows:  false,
 		MaxColumnWidth: 25,
 		Alignment:      []string{"left", "right", "right", "right"},
+		CellStyle:      experienceColor,
 	}
 	fmt.Println(experienceTable.Format(experienceFormat))
 
@@ -122,14 +123,24 @@ func main() {
 	fmt.Println(ageGroups.Format(ageFormat))
 }
 
-// Helper function to safely get column index
-func getColIndex(t *pkg.Table, header string) int {
-	for i, h := range t.Headers {
-		if h == header {
-			return i
-		}
+// experienceColor shades the experience column from green to magenta as the
+// average tenure grows
+func experienceColor(row, col int, value string) string {
+	if col != 1 {
+		return ""
+	}
+	years, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case years >= 5:
+		return pkg.Magenta
+	case years >= 2:
+		return pkg.Yellow
+	default:
+		return pkg.Green
 	}
-	return -1
 }
 
 // analyzeExperience creates a new table with experience-based analysis
@@ -139,9 +150,9 @@ func analyzeExperience(t *pkg.Table) *pkg.Table {
 
 	// Group employees by department
 	deptMap := make(map[string][][]string)
-	deptIdx := getColIndex(t, "department")
-	dateIdx := getColIndex(t, "join_date")
-	salaryIdx := getColIndex(t, "salary")
+	deptIdx, _ := t.ColumnIndex("department")
+	dateIdx, _ := t.ColumnIndex("join_date")
+	salaryIdx, _ := t.ColumnIndex("salary")
 
 	for _, row := range t.Rows {
 		dept := row[deptIdx]
@@ -184,8 +195,8 @@ func createAgeGroups(t *pkg.Table) *pkg.Table {
 	ageTable := pkg.NewTable([]string{"age_group", "count", "avg_salary"})
 	groups := make(map[string][]float64)
 
-	ageIdx := getColIndex(t, "age")
-	salaryIdx := getColIndex(t, "salary")
+	ageIdx, _ := t.ColumnIndex("age")
+	salaryIdx, _ := t.ColumnIndex("salary")
 
 	// Group employees by age range
 	for _, row := range t.Rows {
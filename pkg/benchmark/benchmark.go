@@ -130,3 +130,22 @@ func generateWideCSV(rows, cols int) BenchData {
 		FileSize: int64(len(content)),
 	}
 }
+
+// generateGroupedCSV generates a CSV with a low-cardinality key column for
+// grouping benchmarks
+func generateGroupedCSV(rows, groups int) BenchData {
+	var sb strings.Builder
+	sb.WriteString("id,group,value,score\n")
+
+	for i := 0; i < rows; i++ {
+		sb.WriteString(fmt.Sprintf("%d,group_%d,%d,%d.5\n",
+			i, i%groups, i%1000, i%97))
+	}
+
+	content := sb.String()
+	return BenchData{
+		Name:     fmt.Sprintf("grouped_%dk_%dgroups", rows/1000, groups),
+		Content:  content,
+		FileSize: int64(len(content)),
+	}
+}
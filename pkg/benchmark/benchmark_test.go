@@ -1,6 +1,8 @@
 package benchmark
 
 import (
+	"fmt"
+	"io"
 	"strings"
 	"testing"
 
@@ -58,6 +60,11 @@ func BenchmarkCSVParserWithConfig(b *testing.B) {
 			Quote:       '"',
 			TrimLeading: true,
 		},
+		"reuse_record": {
+			Delimiter:   ',',
+			Quote:       '"',
+			ReuseRecord: true,
+		},
 	}
 
 	// Use complex data for config testing
@@ -116,3 +123,202 @@ func BenchmarkCSVParserMemory(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkCSVParserReuseRecord(b *testing.B) {
+	// Compare allocations with and without record reuse
+	data := generateSimpleCSV(10000)
+
+	for _, reuse := range []bool{false, true} {
+		name := "fresh_records"
+		if reuse {
+			name = "reuse_record"
+		}
+		b.Run(name, func(b *testing.B) {
+			cfg := pkg.DefaultConfig()
+			cfg.ReuseRecord = reuse
+			b.ResetTimer()
+			b.ReportAllocs()
+			b.SetBytes(data.FileSize)
+
+			for i := 0; i < b.N; i++ {
+				reader, err := pkg.NewReader(strings.NewReader(data.Content), cfg)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				for {
+					_, err := reader.ReadRecord()
+					if err != nil {
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReadTableParallel(b *testing.B) {
+	data := generateWideCSV(100000, 100)
+	input := strings.NewReader(data.Content)
+
+	b.Run("serial", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(data.FileSize)
+		for i := 0; i < b.N; i++ {
+			if _, err := pkg.ReadTable(strings.NewReader(data.Content), pkg.DefaultConfig()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(data.FileSize)
+		for i := 0; i < b.N; i++ {
+			if _, err := pkg.ReadTableParallel(input, data.FileSize, pkg.DefaultConfig(), 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkStreamGroupBy(b *testing.B) {
+	data := generateGroupedCSV(500000, 100)
+	aggs := map[string]string{"value": "sum", "score": "avg", pkg.CountAll: "count"}
+	specs := []pkg.AggSpec{
+		{Column: pkg.CountAll, Func: "count"},
+		{Column: "score", Func: "avg"},
+		{Column: "value", Func: "sum"},
+	}
+
+	b.Run("in_memory", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(data.FileSize)
+		for i := 0; i < b.N; i++ {
+			table, err := pkg.ReadTable(strings.NewReader(data.Content), pkg.DefaultConfig())
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := table.GroupBy([]string{"group"}, aggs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("streaming", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(data.FileSize)
+		for i := 0; i < b.N; i++ {
+			if _, err := pkg.StreamGroupBy(strings.NewReader(data.Content), pkg.DefaultConfig(), []string{"group"}, specs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkFormatCachedWidths(b *testing.B) {
+	data := generateWideCSV(100000, 10)
+	table, err := pkg.ReadTable(strings.NewReader(data.Content), pkg.DefaultConfig())
+	if err != nil {
+		b.Fatal(err)
+	}
+	opts := pkg.FormatOptions{Style: pkg.DefaultStyle}
+
+	// Every render of a fresh copy computes the widths from scratch
+	b.Run("first_render", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			fresh := table.Copy()
+			b.StartTimer()
+			_ = fresh.Format(opts)
+		}
+	})
+
+	// Re-rendering an unchanged table reuses the cached widths
+	b.Run("second_render", func(b *testing.B) {
+		_ = table.Format(opts)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = table.Format(opts)
+		}
+	})
+
+	b.Run("column_widths", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = table.ColumnWidths()
+		}
+	})
+
+	// Streaming the output avoids building the whole table as one string
+	b.Run("write_formatted", func(b *testing.B) {
+		_ = table.Format(opts)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := table.WriteFormatted(io.Discard, opts); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkAppendRows(b *testing.B) {
+	// Mixes integer, string and empty columns, so only some columns stop early
+	data := generateComplexCSV(100000)
+	source, err := pkg.ReadTable(strings.NewReader(data.Content), pkg.DefaultConfig())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("add_row", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			table := pkg.NewTable(source.Headers)
+			for _, row := range source.Rows {
+				if err := table.AddRow(row); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("append_rows", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			table := pkg.NewTable(source.Headers)
+			if err := table.AppendRows(source.Rows); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkBufferSize(b *testing.B) {
+	data := generateComplexCSV(10000)
+
+	for _, size := range []int{4 * 1024, 64 * 1024, 1024 * 1024} {
+		b.Run(fmt.Sprintf("%dKB", size/1024), func(b *testing.B) {
+			cfg := pkg.DefaultConfig()
+			cfg.BufferSize = size
+			cfg.ReuseRecord = true
+			b.ReportAllocs()
+			b.SetBytes(data.FileSize)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				reader, err := pkg.NewReader(strings.NewReader(data.Content), cfg)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for {
+					if _, err := reader.ReadRecord(); err != nil {
+						break
+					}
+				}
+			}
+		})
+	}
+}
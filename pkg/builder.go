@@ -0,0 +1,103 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+)
+
+// TableBuilder reads a table from a Reader one row at a time. Rows returned
+// by Next are kept, so the caller can stop iterating at any point and call
+// Build to read the rest into a Table.
+type TableBuilder struct {
+	r       *Reader
+	table   *Table
+	pending []string // first record when it is data rather than a header
+	err     error
+	done    bool
+}
+
+// NewTableFromReader reads the header from r and returns a builder for the
+// rows that follow. The first record is the header unless the reader's
+// config sets NoHeader.
+func NewTableFromReader(r *Reader) (*TableBuilder, error) {
+	first, err := r.ReadRecord()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read headers: %w", err)
+	}
+	first = append([]string(nil), first...)
+	headers, err := tableHeaders(r.cfg, first)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &TableBuilder{r: r, table: NewTable(headers)}
+	b.table.setFormats(r.cfg)
+	if r.cfg.NoHeader {
+		b.pending = first
+	}
+	return b, nil
+}
+
+// Headers returns the table's headers
+func (b *TableBuilder) Headers() []string {
+	return b.table.Headers
+}
+
+// Next reads the next row. It returns ok == false at the end of the input
+// or after an error, which is also available from Err. The returned row is
+// owned by the table and must not be modified.
+func (b *TableBuilder) Next() (row []string, ok bool, err error) {
+	if b.err != nil || b.done {
+		return nil, false, b.err
+	}
+
+	numCols := len(b.table.Headers)
+	for {
+		record := b.pending
+		if record != nil {
+			b.pending = nil
+		} else if record, err = b.r.ReadRecord(); err == io.EOF {
+			b.done = true
+			return nil, false, nil
+		} else if err != nil {
+			b.err = fmt.Errorf("failed to read record: %w", err)
+			return nil, false, b.err
+		} else if b.r.cfg.ReuseRecord {
+			record = append([]string(nil), record...)
+		}
+
+		record, keep, err := b.r.fitRow(record, numCols)
+		if err != nil {
+			b.err = err
+			return nil, false, b.err
+		}
+		if !keep {
+			// Left out under RaggedSkip
+			continue
+		}
+		if err := b.table.AddRow(record); err != nil {
+			b.err = fmt.Errorf("failed to add row: %w", err)
+			return nil, false, b.err
+		}
+		return record, true, nil
+	}
+}
+
+// Err returns the first error met while reading rows, if any
+func (b *TableBuilder) Err() error {
+	return b.err
+}
+
+// Build reads any remaining rows and returns the table holding every row,
+// including those already returned by Next
+func (b *TableBuilder) Build() (*Table, error) {
+	for {
+		_, ok, err := b.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return b.table, nil
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package pkg
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Column is one column of a table with its detected type
+type Column struct {
+	Name   string
+	Type   ColumnType
+	Values []string // Copied from the table, in row order
+}
+
+// Columns returns every column of the table in header order
+func (t *Table) Columns() []Column {
+	cols := make([]Column, len(t.Headers))
+	for i, h := range t.Headers {
+		values := make([]string, len(t.Rows))
+		for r, row := range t.Rows {
+			values[r] = row[i]
+		}
+		cols[i] = Column{Name: h, Type: t.types[i], Values: values}
+	}
+	return cols
+}
+
+// GetColumnFloats parses a column as float64 values. It returns the values
+// that parsed, in row order, and the row indices (0-based) of the cells that
+// did not, including empty cells, so callers can tell which rows were
+// skipped.
+func (t *Table) GetColumnFloats(name string) ([]float64, []int, error) {
+	return parseColumn(t, name, t.numbers.ParseFloat)
+}
+
+// GetColumnInts parses a column as int64 values, like GetColumnFloats
+func (t *Table) GetColumnInts(name string) ([]int64, []int, error) {
+	return parseColumn(t, name, func(s string) (int64, error) {
+		return t.numbers.ParseInt(s, 64)
+	})
+}
+
+// GetColumnBools parses a column as booleans, like GetColumnFloats. Values
+// are matched case-insensitively, so "TRUE" and "False" are accepted, as are
+// the table's boolean words.
+func (t *Table) GetColumnBools(name string) ([]bool, []int, error) {
+	return parseColumn(t, name, func(s string) (bool, error) {
+		if b, ok := t.bools.parse(s); ok {
+			return b, nil
+		}
+		return strconv.ParseBool(strings.ToLower(s))
+	})
+}
+
+// GetColumnTimes parses a column as times, like GetColumnFloats. Each cell
+// is parsed with layout, or with the layouts ToStructs accepts if layout is
+// empty.
+func (t *Table) GetColumnTimes(name, layout string) ([]time.Time, []int, error) {
+	return parseColumn(t, name, func(s string) (time.Time, error) {
+		return parseTime(s, layout)
+	})
+}
+
+// parseColumn applies parse to every cell of the named column
+func parseColumn[T any](t *Table, name string, parse func(string) (T, error)) ([]T, []int, error) {
+	col, err := t.GetColumn(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	vals := make([]T, 0, len(col))
+	var bad []int
+	for i, cell := range col {
+		v, err := parse(strings.TrimSpace(cell))
+		if err != nil {
+			bad = append(bad, i)
+			continue
+		}
+		vals = append(vals, v)
+	}
+	return vals, bad, nil
+}
+
+// defaultCoerceFraction is used when CoerceOptions.MinFraction is not set
+const defaultCoerceFraction = 0.95
+
+// CoerceOptions controls CoerceTypes
+type CoerceOptions struct {
+	// MinFraction is the share of non-null values that must parse as a type
+	// for the column to take it. Defaults to 0.95.
+	MinFraction float64
+
+	// BlankInvalid replaces values that do not parse as the new type with
+	// empty (null) values.
+	BlankInvalid bool
+}
+
+// CoerceTypes re-examines each string column and gives it the first of
+// integer, float and boolean that enough of its non-null values parse as,
+// so a few stray values no longer force a column to be text. It returns the
+// row indices (0-based) of the non-conforming cells per coerced column.
+func (t *Table) CoerceTypes(opts CoerceOptions) map[string][]int {
+	minFraction := opts.MinFraction
+	if minFraction <= 0 {
+		minFraction = defaultCoerceFraction
+	}
+
+	invalid := make(map[string][]int)
+	for col, header := range t.Headers {
+		if t.types[col] != TypeString {
+			continue
+		}
+
+		nonNull := 0
+		counts := make(map[ColumnType]int)
+		for _, row := range t.Rows {
+			val := strings.TrimSpace(row[col])
+			if DetectType(val) == TypeNull {
+				continue
+			}
+			nonNull++
+			for _, ct := range []ColumnType{TypeInteger, TypeFloat, TypeBoolean} {
+				if t.matchesType(val, ct) {
+					counts[ct]++
+				}
+			}
+		}
+		if nonNull == 0 {
+			continue
+		}
+
+		for _, ct := range []ColumnType{TypeInteger, TypeFloat, TypeBoolean} {
+			if float64(counts[ct])/float64(nonNull) < minFraction {
+				continue
+			}
+			t.types[col] = ct
+			var bad []int
+			for i, row := range t.Rows {
+				val := strings.TrimSpace(row[col])
+				if DetectType(val) == TypeNull || t.matchesType(val, ct) {
+					continue
+				}
+				bad = append(bad, i)
+				if opts.BlankInvalid {
+					t.setCell(i, col, "")
+					t.widths = nil
+				}
+			}
+			invalid[header] = bad
+			break
+		}
+	}
+	return invalid
+}
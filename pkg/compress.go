@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+// gzipMagic is the header that starts every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// isGzip reports whether br starts with a gzip header, without consuming it
+func isGzip(br *bufio.Reader) bool {
+	magic, err := br.Peek(len(gzipMagic))
+	return err == nil && bytes.Equal(magic, gzipMagic)
+}
+
+// isGzipAt reports whether r starts with a gzip header
+func isGzipAt(r io.ReaderAt) bool {
+	magic := make([]byte, len(gzipMagic))
+	n, _ := r.ReadAt(magic, 0)
+	return bytes.Equal(magic[:n], gzipMagic)
+}
+
+// compressedFile closes both the decompressor and the underlying file
+type compressedFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (c *compressedFile) Close() error {
+	err := c.Reader.Close()
+	if cerr := c.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// OpenMaybeCompressed opens the file at path for reading. If the file starts
+// with a gzip header, whatever its extension, the returned reader yields the
+// decompressed data. Closing the reader closes the file.
+func OpenMaybeCompressed(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	magic := make([]byte, len(gzipMagic))
+	n, err := io.ReadFull(file, magic)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if !bytes.Equal(magic[:n], gzipMagic) {
+		return file, nil
+	}
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error opening gzip stream: %w", err)
+	}
+	return &compressedFile{Reader: zr, file: file}, nil
+}
@@ -0,0 +1,127 @@
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// CrossTabNull is the category used for empty and null values in CrossTab
+const CrossTabNull = "(null)"
+
+// CrossTabTotal names the totals row and column added by CrossTabWithOptions
+const CrossTabTotal = "Total"
+
+// CrossTabOptions controls CrossTabWithOptions
+type CrossTabOptions struct {
+	// Totals adds a Total column with each row's count and a Total row with
+	// each column's count and the grand total
+	Totals bool
+}
+
+// CrossTab counts how often each pair of values of rowCol and colCol occurs.
+// The result has one row per distinct value of rowCol and one column per
+// distinct value of colCol, both in the order they are first seen; its
+// first column is named rowCol. Empty and null values are counted under
+// CrossTabNull.
+func (t *Table) CrossTab(rowCol, colCol string) (*Table, error) {
+	return t.CrossTabWithOptions(rowCol, colCol, CrossTabOptions{})
+}
+
+// CrossTabWithOptions is like CrossTab with optional totals. It fails if a
+// value of colCol is the name rowCol, or, with totals, if CrossTabTotal is a
+// value of either column or the name rowCol, since the result could not tell
+// those rows and columns apart.
+func (t *Table) CrossTabWithOptions(rowCol, colCol string, opts CrossTabOptions) (*Table, error) {
+	rowIdx, ok := t.index[rowCol]
+	if !ok {
+		return nil, fmt.Errorf("column %q not found", rowCol)
+	}
+	colIdx, ok := t.index[colCol]
+	if !ok {
+		return nil, fmt.Errorf("column %q not found", colCol)
+	}
+
+	category := func(v string) string {
+		if DetectType(v) == TypeNull {
+			return CrossTabNull
+		}
+		return v
+	}
+
+	var rowKeys, colKeys []string
+	rowPos := make(map[string]int)
+	colPos := make(map[string]int)
+	var counts [][]int
+	for _, row := range t.Rows {
+		r, c := category(row[rowIdx]), category(row[colIdx])
+		ri, ok := rowPos[r]
+		if !ok {
+			ri = len(rowKeys)
+			rowPos[r] = ri
+			rowKeys = append(rowKeys, r)
+			counts = append(counts, make([]int, len(colKeys)))
+		}
+		ci, ok := colPos[c]
+		if !ok {
+			ci = len(colKeys)
+			colPos[c] = ci
+			colKeys = append(colKeys, c)
+			for i := range counts {
+				counts[i] = append(counts[i], 0)
+			}
+		}
+		counts[ri][ci]++
+	}
+
+	if _, ok := colPos[rowCol]; ok {
+		return nil, fmt.Errorf("value %q of column %q is also the name of the first column", rowCol, colCol)
+	}
+	if opts.Totals {
+		if rowCol == CrossTabTotal {
+			return nil, fmt.Errorf("column %q clashes with the %s column", rowCol, CrossTabTotal)
+		}
+		if _, ok := rowPos[CrossTabTotal]; ok {
+			return nil, fmt.Errorf("value %q of column %q clashes with the %s row", CrossTabTotal, rowCol, CrossTabTotal)
+		}
+		if _, ok := colPos[CrossTabTotal]; ok {
+			return nil, fmt.Errorf("value %q of column %q clashes with the %s column", CrossTabTotal, colCol, CrossTabTotal)
+		}
+	}
+
+	headers := append([]string{rowCol}, colKeys...)
+	if opts.Totals {
+		headers = append(headers, CrossTabTotal)
+	}
+	result := NewTable(headers)
+
+	colTotals := make([]int, len(colKeys))
+	grandTotal := 0
+	for i, key := range rowKeys {
+		row := []string{key}
+		rowTotal := 0
+		for j, n := range counts[i] {
+			row = append(row, strconv.Itoa(n))
+			rowTotal += n
+			colTotals[j] += n
+		}
+		grandTotal += rowTotal
+		if opts.Totals {
+			row = append(row, strconv.Itoa(rowTotal))
+		}
+		if err := result.AddRow(row); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Totals {
+		row := []string{CrossTabTotal}
+		for _, n := range colTotals {
+			row = append(row, strconv.Itoa(n))
+		}
+		row = append(row, strconv.Itoa(grandTotal))
+		if err := result.AddRow(row); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffKind describes how a row differs between two tables
+type DiffKind int
+
+const (
+	RowAdded DiffKind = iota
+	RowRemoved
+	RowChanged
+)
+
+// String returns the kind's name
+func (k DiffKind) String() string {
+	switch k {
+	case RowAdded:
+		return "added"
+	case RowRemoved:
+		return "removed"
+	case RowChanged:
+		return "changed"
+	}
+	return fmt.Sprintf("DiffKind(%d)", int(k))
+}
+
+// RowDiff is one difference found by Diff
+type RowDiff struct {
+	Kind    DiffKind
+	Key     string   // Value of the key column, or of the key columns joined by commas
+	Old     []string // Row in the original table, nil if added
+	New     []string // Row in the other table, nil if removed
+	Columns []string // Columns whose values changed, for RowChanged
+}
+
+// Diff compares t with other, matching rows by the values in the key column,
+// which must be unique in both tables. Values are compared by column name
+// over the columns the tables share, so a column order change alone is not
+// a difference. Removed and changed rows are reported in t's row order,
+// followed by added rows in other's order.
+func (t *Table) Diff(other *Table, key string) ([]RowDiff, error) {
+	return t.DiffKeys(other, []string{key})
+}
+
+// DiffKeys is like Diff, but matches rows by the combined values of several
+// key columns, such as a date and a store ID, which together must be unique
+// in both tables
+func (t *Table) DiffKeys(other *Table, keys []string) ([]RowDiff, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no key columns given")
+	}
+	oldKeys := make([]int, len(keys))
+	newKeys := make([]int, len(keys))
+	for i, key := range keys {
+		idx, ok := t.index[key]
+		if !ok {
+			return nil, fmt.Errorf("key column %q not found", key)
+		}
+		oldKeys[i] = idx
+		if idx, ok = other.index[key]; !ok {
+			return nil, fmt.Errorf("key column %q not found in other table", key)
+		}
+		newKeys[i] = idx
+	}
+
+	newRows, err := rowsByKey(other, newKeys)
+	if err != nil {
+		return nil, fmt.Errorf("other table: %w", err)
+	}
+	if _, err := rowsByKey(t, oldKeys); err != nil {
+		return nil, err
+	}
+
+	// Columns present in both tables, in t's order
+	type colPair struct {
+		name     string
+		old, new int
+	}
+	var shared []colPair
+	for i, h := range t.Headers {
+		if j, ok := other.index[h]; ok {
+			shared = append(shared, colPair{h, i, j})
+		}
+	}
+
+	var diffs []RowDiff
+	seen := make(map[string]bool, len(t.Rows))
+	for _, oldRow := range t.Rows {
+		k := rowKey(oldRow, oldKeys, "\x00")
+		seen[k] = true
+		newRow, ok := newRows[k]
+		if !ok {
+			diffs = append(diffs, RowDiff{Kind: RowRemoved, Key: rowKey(oldRow, oldKeys, ","), Old: oldRow})
+			continue
+		}
+		var changed []string
+		for _, c := range shared {
+			if oldRow[c.old] != newRow[c.new] {
+				changed = append(changed, c.name)
+			}
+		}
+		if len(changed) > 0 {
+			diffs = append(diffs, RowDiff{Kind: RowChanged, Key: rowKey(oldRow, oldKeys, ","), Old: oldRow, New: newRow, Columns: changed})
+		}
+	}
+	for _, newRow := range other.Rows {
+		if !seen[rowKey(newRow, newKeys, "\x00")] {
+			diffs = append(diffs, RowDiff{Kind: RowAdded, Key: rowKey(newRow, newKeys, ","), New: newRow})
+		}
+	}
+	return diffs, nil
+}
+
+// DiffHeaders compares the columns of t and other by name, returning the
+// columns only other has and those only t has, each in its table's order.
+// Diff compares values only over the columns both tables have.
+func (t *Table) DiffHeaders(other *Table) (added, removed []string) {
+	for _, h := range other.Headers {
+		if _, ok := t.index[h]; !ok {
+			added = append(added, h)
+		}
+	}
+	for _, h := range t.Headers {
+		if _, ok := other.index[h]; !ok {
+			removed = append(removed, h)
+		}
+	}
+	return added, removed
+}
+
+// rowKey joins the values of the key columns of row with sep
+func rowKey(row []string, keys []int, sep string) string {
+	if len(keys) == 1 {
+		return row[keys[0]]
+	}
+	parts := make([]string, len(keys))
+	for i, idx := range keys {
+		parts[i] = row[idx]
+	}
+	return strings.Join(parts, sep)
+}
+
+// rowsByKey maps the values of the key columns of each row to the row,
+// failing on duplicates
+func rowsByKey(t *Table, keys []int) (map[string][]string, error) {
+	rows := make(map[string][]string, len(t.Rows))
+	for i, row := range t.Rows {
+		k := rowKey(row, keys, "\x00")
+		if _, dup := rows[k]; dup {
+			if len(keys) == 1 {
+				return nil, fmt.Errorf("row %d: duplicate key %q in column %q", i+1, k, t.Headers[keys[0]])
+			}
+			names := make([]string, len(keys))
+			for j, idx := range keys {
+				names[j] = t.Headers[idx]
+			}
+			return nil, fmt.Errorf("row %d: duplicate key %q in columns %s", i+1, rowKey(row, keys, ","),
+				strings.Join(names, ", "))
+		}
+		rows[k] = row
+	}
+	return rows, nil
+}
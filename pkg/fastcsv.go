@@ -2,31 +2,237 @@ package pkg
 
 import (
 	"bufio"
-	_ "errors"
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
-	"sync"
+	"unicode/utf8"
 )
 
 // Config holds the settings for our CSV parser.
 type Config struct {
-	Delimiter   rune   // e.g. ',' or ';'
-	Quote       rune   // e.g. '"'
-	TrimLeading bool   // trim leading whitespace of unquoted fields
-	Null        string // e.g. "\N" or "NULL"
-	Comment     rune   // Comment character for line skipping
+	Delimiter    rune   // e.g. ',' or ';'
+	Quote        rune   // e.g. '"', or 0 to disable quoting
+	TrimLeading  bool   // trim leading whitespace of unquoted fields
+	TrimTrailing bool   // trim trailing whitespace of unquoted fields
+	TrimSpace    bool   // trim leading and trailing whitespace of unquoted fields
+	Null         string // e.g. "\N" or "NULL"
+	Comment      rune   // Comment character for line skipping
+
+	// AllowInlineComments lets Comment also begin any unquoted field, in
+	// which case the rest of that line is ignored. By default Comment is only
+	// recognized as the first byte of a physical line. A Comment character in
+	// the middle of a field is always treated as data.
+	AllowInlineComments bool
+
+	// ReuseRecord makes ReadRecord return a slice backed by a buffer owned by
+	// the Reader. The slice is only valid until the next call to ReadRecord,
+	// so callers that retain records must copy them.
+	ReuseRecord bool
+
+	// TrimTrailingEmptyField drops a single empty field at the end of a record
+	// when it has one field more than the header, as produced by "a,b,c,".
+	// An empty last header is dropped as well. Used by ToTable and ReadTable.
+	TrimTrailingEmptyField bool
+	// PadShortRecords pads records with fewer fields than the header with
+	// empty strings instead of failing. Used by ToTable and ReadTable.
+	PadShortRecords bool
+	// OnRagged selects what ToTable, ReadTable and the streaming functions
+	// (StreamGroupBy, ReservoirSample, StreamSelect, TransformStream and
+	// TranscodeStream) do with records that still have the wrong number of
+	// fields after the options above. The default, RaggedError, fails with
+	// ErrFieldCount.
+	OnRagged RaggedPolicy
+
+	// NoHeader treats the first record as data. Headers are generated as
+	// col1, col2, ... to match the number of fields in the first record.
+	NoHeader bool
+
+	// SkipRows skips this many physical lines at the start of the input, such
+	// as a title or export timestamp above the header. Quotes in skipped lines
+	// have no effect.
+	SkipRows int
+
+	// HeaderRows combines the first HeaderRows records into a single header
+	// record when greater than 1, joining the non-empty values of each column
+	// with "_": a "price" above "USD" becomes "price_USD". The combined header
+	// counts as one record. Ignored with NoHeader.
+	HeaderRows int
+
+	// StrictRFC4180 rejects input that RFC 4180 does not allow: a quote
+	// inside an unquoted field, anything but a delimiter or line break after
+	// a closing quote, and records whose field count differs from the first
+	// record's. Violations are reported with their Position. The ragged-row
+	// options cannot relax the field count check.
+	StrictRFC4180 bool
+
+	// DuplicateHeaders controls what ToTable and ReadTable do when a header
+	// name appears more than once.
+	DuplicateHeaders DuplicateHeaderPolicy
+
+	// AutoDecompress makes NewReader detect gzip-compressed input by its
+	// header and decompress it transparently.
+	AutoDecompress bool
+
+	// LineTerminator ends each record written by Table.WriteCSV: "\n" or
+	// "\r\n". Empty means "\n". Readers accept either.
+	LineTerminator string
+
+	// SkipHeaderOnWrite makes Table.WriteCSV and TranscodeStream leave out
+	// the header row, for appending rows to a file that already has one
+	SkipHeaderOnWrite bool
+
+	// QuoteAll makes Writer quote every field, not only those that need it
+	QuoteAll bool
+
+	// QuoteEmpty makes Writer write empty fields as two quotes. Importers
+	// such as PostgreSQL read an unquoted empty field as NULL and a quoted
+	// one as an empty string.
+	QuoteEmpty bool
+
+	// WhitespaceDelimited separates fields by runs of spaces and tabs instead
+	// of Delimiter. Whitespace at the start and end of a line is ignored, as
+	// are blank lines; quoted fields may still contain whitespace.
+	WhitespaceDelimited bool
+
+	// FixedWidths reads each line as fixed-width columns of the given byte
+	// lengths instead of delimited fields. Fields are trimmed of surrounding
+	// spaces and tabs unless RawFields is set, fields past the end of a short
+	// line are empty, and bytes past the last column are ignored. Quotes have
+	// no special meaning.
+	FixedWidths []int
+
+	// BufferSize is the size in bytes of the Reader's input buffer, 64KB if
+	// zero. Larger buffers mean fewer reads from slow sources, smaller ones
+	// less memory per Reader. Sizes below 16 bytes are raised to 16.
+	BufferSize int
+
+	// KeepRawLine makes the Reader keep the unparsed text of each record for
+	// Reader.RawLine, at the cost of copying every byte read.
+	KeepRawLine bool
+
+	// RawFields returns every field exactly as it appears in the input.
+	// Records are split where they would be without it, but quoted fields
+	// keep their surrounding quotes and doubled quotes, and trimming and Null
+	// substitution are not applied. Headers are kept verbatim as well.
+	RawFields bool
+
+	// BoolTrue and BoolFalse list words read as true and false besides
+	// "true" and "false", such as "yes" and "no" or "Y" and "N", matched
+	// case-insensitively. Columns of such words are detected as booleans and
+	// exported to JSON as booleans. Numbers are only read as booleans when
+	// listed, as in BoolTrue: []string{"1"}, since a 0/1 column is usually a
+	// count.
+	BoolTrue  []string
+	BoolFalse []string
+
+	// NumberFormat describes numbers written with thousands separators,
+	// another decimal separator or currency symbols. Tables read with this
+	// config use it for type detection and statistics. The zero value
+	// accepts plain numbers only.
+	NumberFormat NumberFormat
 }
 
+// DuplicateHeaderPolicy selects how repeated header names are handled
+type DuplicateHeaderPolicy int
+
+const (
+	// DuplicateHeadersAllow keeps repeated names as they are. Lookups by
+	// name then only find the last column with that name.
+	DuplicateHeadersAllow DuplicateHeaderPolicy = iota
+	// DuplicateHeadersError fails with ErrDuplicateHeader
+	DuplicateHeadersError
+	// DuplicateHeadersRename adds a numeric suffix to repeated names, so
+	// "value,value" becomes "value,value_2"
+	DuplicateHeadersRename
+)
+
+// RaggedPolicy selects how records with more or fewer fields than the header
+// are handled. Reader.RaggedRows reports how many records were adjusted.
+type RaggedPolicy int
+
+const (
+	// RaggedError fails with ErrFieldCount
+	RaggedError RaggedPolicy = iota
+	// RaggedPad pads short records with empty fields, like PadShortRecords.
+	// Long records still fail, since their extra fields would be lost.
+	RaggedPad
+	// RaggedTruncate drops the extra fields of long records and pads short
+	// ones, so every record fits
+	RaggedTruncate
+	// RaggedSkip leaves out records with the wrong number of fields
+	RaggedSkip
+	// RaggedKeep passes records on with the fields they have. Only
+	// StreamSelect and TranscodeStream accept it, since they need not fit
+	// every record to the header; everything else rejects the records as
+	// RaggedError does.
+	RaggedKeep
+)
+
 // DefaultConfig returns a default config with comma delimiter, double-quote, etc.
 func DefaultConfig() Config {
 	return Config{
-		Delimiter:   ',',
-		Quote:       '"',
-		TrimLeading: false,
-		Null:        "", // No null string by default
-		Comment:     0,  // No comment character by default
+		Delimiter:    ',',
+		Quote:        '"',
+		TrimLeading:  false,
+		TrimTrailing: false,
+		TrimSpace:    false,
+		Null:         "", // No null string by default
+		Comment:      0,  // No comment character by default
+
+		DuplicateHeaders: DuplicateHeadersRename,
+		AutoDecompress:   true,
+		LineTerminator:   "\n",
+	}
+}
+
+// TSVConfig returns DefaultConfig with a tab delimiter, for tab-separated
+// values. Quoted fields are still recognized, since that is how spreadsheets
+// export values containing tabs or line breaks.
+func TSVConfig() Config {
+	cfg := DefaultConfig()
+	cfg.Delimiter = '\t'
+	return cfg
+}
+
+// ParseDelimiter converts a delimiter as typed on a command line to a rune.
+// Besides a single character it accepts `\t` or "tab" for a tab, since a
+// literal tab is awkward to type in a shell, and `\\` for a backslash. The
+// delimiter must be a single-byte (ASCII) character.
+func ParseDelimiter(s string) (rune, error) {
+	switch s {
+	case "":
+		return 0, fmt.Errorf("delimiter must not be empty")
+	case `\t`, "tab":
+		return '\t', nil
+	case `\\`:
+		return '\\', nil
+	}
+	return parseFlagRune("delimiter", s)
+}
+
+// ParseQuote converts a quote character as typed on a command line to a
+// rune. An empty string disables quoting and returns 0.
+func ParseQuote(s string) (rune, error) {
+	if s == "" {
+		return 0, nil
 	}
+	return parseFlagRune("quote", s)
+}
+
+// parseFlagRune returns the single ASCII character in s
+func parseFlagRune(name, s string) (rune, error) {
+	r := []rune(s)
+	if len(r) != 1 {
+		return 0, fmt.Errorf("%s %q must be a single character", name, s)
+	}
+	if r[0] >= utf8.RuneSelf {
+		return 0, fmt.Errorf("%s %q must be an ASCII character", name, s)
+	}
+	return r[0], nil
 }
 
 // Reader provides a streaming CSV parser.
@@ -37,9 +243,9 @@ type Reader struct {
 	err   error
 
 	// State
-	inQuotes         bool
-	endOfField       bool
-	lastCharWasQuote bool
+	inQuotes   bool
+	endOfField bool // a delimiter was read, so another (possibly empty) field follows
+	quoteEnd   int  // length of field when its closing quote was read, -1 if unquoted
 
 	// Statistics
 	record        []string
@@ -47,34 +253,75 @@ type Reader struct {
 	currentRowNum int64
 	currentColNum int
 	bytesRead     int64
+	lineNum       int64  // physical lines fully consumed
+	endings       [3]int // line endings seen: "\n", "\r\n" and lone "\r"
+	prevCR        bool   // the last byte read was '\r'
+	raw           []byte // unparsed bytes of the current record, with KeepRawLine
+	recordLine    int64  // physical line on which the current record starts
+	fieldsPerRec  int    // field count of the first record, used in strict mode
+	ragged        int64  // records padded, truncated or skipped to fit the header
+	started       bool   // SkipRows and HeaderRows have been applied
+	records       int64  // records read, header included
+	fields        int64  // fields in those records
+	minFields     int    // fewest fields in a record
+	maxFields     int    // most fields in a record
 }
 
-// Pool for record slices
-var recordPool = sync.Pool{
-	New: func() interface{} {
-		return make([]string, 0, 16) // Initial capacity
-	},
-}
+var (
+	// ErrUnterminatedQuote is returned when the input ends inside a quoted field
+	ErrUnterminatedQuote = errors.New("unterminated quoted field")
+	// ErrFieldCount is returned when a record has a different number of fields than the header
+	ErrFieldCount = errors.New("wrong number of fields")
+	// ErrDuplicateHeader is returned when a header name is repeated and
+	// Config.DuplicateHeaders is DuplicateHeadersError
+	ErrDuplicateHeader = errors.New("duplicate header")
+	// ErrBareQuote is returned in strict mode when a quote appears inside an
+	// unquoted field
+	ErrBareQuote = errors.New("bare quote in unquoted field")
+	// ErrTrailingQuote is returned in strict mode when a closing quote is
+	// followed by something other than a delimiter or line break
+	ErrTrailingQuote = errors.New("extraneous character after closing quote")
+)
 
-// Add field buffer pooling
-var fieldPool = sync.Pool{
-	New: func() interface{} {
-		b := make([]byte, 0, 256)
-		return &b // Return pointer to slice
-	},
-}
+// Input buffer sizes for Config.BufferSize. The parser peeks at most two
+// bytes ahead, which any buffer bufio allows can hold.
+const (
+	defaultBufferSize = 64 * 1024
+	minBufferSize     = 16
+)
 
 // NewReader creates a new Reader with the given io.Reader and config.
 func NewReader(rd io.Reader, cfg Config) (*Reader, error) {
 	if cfg.Delimiter == cfg.Quote || cfg.Delimiter == cfg.Comment {
 		return nil, fmt.Errorf("delimiter, quote, and comment must be distinct")
 	}
-	if cfg.Quote == 0 {
-		cfg.Quote = '"' // Force default quote if disabled
+	if len(cfg.FixedWidths) > 0 {
+		if cfg.WhitespaceDelimited {
+			return nil, fmt.Errorf("fixed widths and whitespace delimiting cannot be combined")
+		}
+		for _, w := range cfg.FixedWidths {
+			if w <= 0 {
+				return nil, fmt.Errorf("fixed column widths must be positive, got %d", w)
+			}
+		}
+	}
+	size := cfg.BufferSize
+	if size == 0 {
+		size = defaultBufferSize
+	}
+	size = max(size, minBufferSize)
+	br := bufio.NewReaderSize(rd, size)
+	if cfg.AutoDecompress && isGzip(br) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("error opening gzip stream: %w", err)
+		}
+		br = bufio.NewReaderSize(zr, size)
 	}
 	return &Reader{
-		r:             bufio.NewReaderSize(rd, 64*1024), // 64KB buffer, can be tuned
+		r:             br,
 		cfg:           cfg,
+		quoteEnd:      -1,
 		currentRowNum: 0,
 		currentColNum: 0,
 		bytesRead:     0,
@@ -82,22 +329,51 @@ func NewReader(rd io.Reader, cfg Config) (*Reader, error) {
 }
 
 // ReadRecord reads one record (a slice of string fields) from the CSV stream.
-// It returns nil, io.EOF at the end of the stream, or an error.
+// It returns nil, io.EOF at the end of the stream, or an error. Errors for
+// malformed input include the Position at which they were detected.
+// Unless Config.ReuseRecord is set, each call returns a newly allocated slice.
+// Line breaks inside quoted fields, including "\r\n", are kept verbatim and
+// do not end the record.
 func (cr *Reader) ReadRecord() ([]string, error) {
 	if cr.err != nil {
 		return nil, cr.err
 	}
+	if !cr.started {
+		cr.started = true
+		for i := 0; i < cr.cfg.SkipRows; i++ {
+			cr.skipLine()
+		}
+		if cr.cfg.HeaderRows > 1 && !cr.cfg.NoHeader {
+			return cr.readHeaderRows()
+		}
+	}
+	if len(cr.cfg.FixedWidths) > 0 {
+		return cr.readFixedRecord()
+	}
 
 	// Reset state
+	cr.raw = cr.raw[:0]
 	cr.field = cr.field[:0]
-	cr.record = recordPool.Get().([]string)[:0]
+	cr.quoteEnd = -1
+	cr.endOfField = false
+	if cr.cfg.ReuseRecord {
+		cr.record = cr.record[:0]
+	} else {
+		cr.record = make([]string, 0, len(cr.currentRecord))
+	}
 	cr.currentColNum = 0
+	cr.recordLine = cr.lineNum + 1
+	atLineStart := true
 
 	for {
-		b, err := cr.r.ReadByte()
+		b, err := cr.readByte()
 		if err == io.EOF {
-			// If we have some data in the field buffer, finalize that field.
-			if len(cr.field) > 0 || cr.endOfField || cr.inQuotes {
+			if cr.inQuotes {
+				return nil, cr.syntaxError(ErrUnterminatedQuote)
+			}
+			// Finalize the last field if it has data, follows a delimiter
+			// (as in "a,b,"), or was quoted (as in `""`)
+			if len(cr.field) > 0 || cr.endOfField || cr.quoteEnd >= 0 {
 				cr.commitField()
 			}
 			// We have reached the end of file
@@ -105,111 +381,385 @@ func (cr *Reader) ReadRecord() ([]string, error) {
 				// No more records
 				return nil, io.EOF
 			}
-			cr.currentRecord = cr.record
-			cr.currentRowNum++
-			return cr.record, nil
+			return cr.finishRecord()
 		}
 		if err != nil {
 			cr.err = err
 			return nil, err
 		}
 
-		cr.bytesRead++
-
 		// Handle comments
-		if cr.cfg.Comment != 0 && b == byte(cr.cfg.Comment) && !cr.inQuotes && len(cr.field) == 0 && len(cr.record) == 0 {
-			// Skip until end of line
-			for {
-				b, err := cr.r.ReadByte()
-				if err != nil || b == '\n' || b == '\r' {
-					if b == '\r' {
-						// Check for \n in Windows line endings
-						if next, err := cr.r.Peek(1); err == nil && len(next) > 0 && next[0] == '\n' {
-							_, _ = cr.r.ReadByte()
-						}
-					}
-					break
-				}
+		if cr.isCommentStart(b, atLineStart) {
+			cr.skipLine()
+			if len(cr.record) > 0 {
+				// An inline comment ends the record it appears in
+				return cr.finishRecord()
 			}
+			cr.recordLine = cr.lineNum + 1
+			cr.raw = cr.raw[:0]
+			atLineStart = true
 			continue
 		}
+		atLineStart = false
+
+		whitespace := cr.cfg.WhitespaceDelimited && (b == ' ' || b == '\t')
+		if cr.cfg.StrictRFC4180 && cr.quoteEnd >= 0 && !cr.inQuotes && !whitespace &&
+			b != byte(cr.cfg.Delimiter) && b != '\n' && b != '\r' {
+			return nil, cr.syntaxError(ErrTrailingQuote)
+		}
 
 		switch {
-		case b == byte(cr.cfg.Delimiter) && !cr.inQuotes:
+		case whitespace && !cr.inQuotes:
+			// A run of whitespace ends the field before it, if any
+			if len(cr.field) > 0 || cr.quoteEnd >= 0 {
+				cr.commitField()
+			}
+		case b == byte(cr.cfg.Delimiter) && !cr.inQuotes && !cr.cfg.WhitespaceDelimited:
 			cr.commitField()
-		case b == byte(cr.cfg.Quote):
+			cr.endOfField = true
+		case cr.cfg.Quote != 0 && b == byte(cr.cfg.Quote):
 			if !cr.inQuotes {
 				// If we're not currently in quotes, entering a quote
 				// Only do so if the field is empty or we've just started
-				if len(cr.field) == 0 {
+				if cr.atFieldStart() {
 					cr.inQuotes = true
+					if cr.cfg.RawFields {
+						cr.field = append(cr.field, b)
+					}
 					continue
 				}
+				if cr.cfg.StrictRFC4180 {
+					return nil, cr.syntaxError(ErrBareQuote)
+				}
+				// A quote inside an unquoted field is data
+				cr.field = append(cr.field, b)
+				continue
 			} else {
 				// We are in quotes
 				// Check next character to see if it's an escaped quote
 				peekByte, err := cr.r.Peek(1)
 				if err == nil && len(peekByte) > 0 && peekByte[0] == byte(cr.cfg.Quote) {
 					// Escaped quote, consume it and add a quote to the field
-					_, _ = cr.r.ReadByte() // consume next
+					_, _ = cr.readByte() // consume next
+					if cr.cfg.RawFields {
+						cr.field = append(cr.field, b)
+					}
 					cr.field = append(cr.field, byte(cr.cfg.Quote))
 					continue
 				} else {
 					// End quote
 					cr.inQuotes = false
-					cr.lastCharWasQuote = true
+					if cr.cfg.RawFields {
+						cr.field = append(cr.field, b)
+					}
+					cr.quoteEnd = len(cr.field)
 					continue
 				}
 			}
-			// If we get here, it's just a normal character
-			fallthrough
 
 		case (b == '\n' || b == '\r') && !cr.inQuotes:
 			// End of record
 			// If we read '\r', check for the next one being '\n' to handle Windows line endings
 			if b == '\r' {
 				if next, err := cr.r.Peek(1); err == nil && len(next) > 0 && next[0] == '\n' {
-					_, _ = cr.r.ReadByte() // consume '\n'
+					_, _ = cr.readByte() // consume '\n'
 				}
 			}
+			if cr.cfg.WhitespaceDelimited {
+				// Trailing whitespace leaves no empty field, and blank lines
+				// are skipped
+				if len(cr.field) > 0 || cr.quoteEnd >= 0 {
+					cr.commitField()
+				}
+				if len(cr.record) == 0 {
+					cr.recordLine = cr.lineNum + 1
+					cr.raw = cr.raw[:0]
+					atLineStart = true
+					continue
+				}
+				return cr.finishRecord()
+			}
 			cr.commitField()
-			cr.currentRecord = cr.record
-			cr.currentRowNum++
-			return cr.record, nil
+			return cr.finishRecord()
 
 		default:
 			// Regular character
-			// Optionally handle trimming if TrimLeading is set
-			if cr.cfg.TrimLeading && len(cr.field) == 0 && !cr.inQuotes && (b == ' ' || b == '\t') {
+			// Optionally handle trimming if TrimLeading or TrimSpace is set
+			if (cr.cfg.TrimLeading || cr.cfg.TrimSpace) && !cr.cfg.RawFields && len(cr.field) == 0 && !cr.inQuotes && (b == ' ' || b == '\t') {
 				// skip leading whitespace if not in quotes
 				continue
 			}
 			cr.field = append(cr.field, b)
-			cr.lastCharWasQuote = false
+		}
+	}
+}
+
+// readHeaderRows reads the first Config.HeaderRows records and combines them
+// into one header record, column by column
+func (cr *Reader) readHeaderRows() ([]string, error) {
+	// The combined header counts as one record
+	records, fields, minFields, maxFields := cr.records, cr.fields, cr.minFields, cr.maxFields
+	var parts [][]string
+	for len(parts) < cr.cfg.HeaderRows {
+		record, err := cr.ReadRecord()
+		if err == io.EOF && len(parts) > 0 {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, append([]string(nil), record...))
+	}
+
+	n := 0
+	for _, p := range parts {
+		n = max(n, len(p))
+	}
+	header := make([]string, n)
+	for i := range header {
+		var names []string
+		for _, p := range parts {
+			if i < len(p) && p[i] != "" {
+				names = append(names, p[i])
+			}
+		}
+		header[i] = strings.Join(names, "_")
+	}
+	cr.currentRowNum -= int64(len(parts) - 1)
+	cr.records, cr.fields, cr.minFields, cr.maxFields = records, fields, minFields, maxFields
+	cr.countRecord(len(header))
+	cr.currentRecord = header
+	return header, nil
+}
+
+// atFieldStart reports whether nothing but whitespace that trimming would
+// skip has been read into the current field, so a quote opens a quoted field
+func (cr *Reader) atFieldStart() bool {
+	if len(cr.field) == 0 {
+		return true
+	}
+	return cr.cfg.RawFields && (cr.cfg.TrimLeading || cr.cfg.TrimSpace) &&
+		len(bytes.Trim(cr.field, " \t")) == 0
+}
+
+// readFixedRecord reads the next non-blank, non-comment line as a record of
+// Config.FixedWidths columns
+func (cr *Reader) readFixedRecord() ([]string, error) {
+	if cr.cfg.ReuseRecord {
+		cr.record = cr.record[:0]
+	} else {
+		cr.record = make([]string, 0, len(cr.cfg.FixedWidths))
+	}
+	cr.currentColNum = 0
+
+	for {
+		cr.recordLine = cr.lineNum + 1
+		cr.raw = cr.raw[:0]
+		cr.field = cr.field[:0]
+		eof := false
+		for {
+			b, err := cr.readByte()
+			if err == io.EOF {
+				eof = true
+				break
+			}
+			if err != nil {
+				cr.err = err
+				return nil, err
+			}
+			if b == '\n' {
+				break
+			}
+			if b == '\r' {
+				if next, err := cr.r.Peek(1); err == nil && len(next) > 0 && next[0] == '\n' {
+					_, _ = cr.readByte()
+				}
+				break
+			}
+			cr.field = append(cr.field, b)
+		}
+
+		line := cr.field
+		if len(strings.TrimSpace(string(line))) == 0 ||
+			(cr.cfg.Comment != 0 && line[0] == byte(cr.cfg.Comment)) {
+			if eof {
+				return nil, io.EOF
+			}
+			continue
+		}
+
+		start := 0
+		for _, w := range cr.cfg.FixedWidths {
+			end := min(start+w, len(line))
+			str := string(line[min(start, end):end])
+			if !cr.cfg.RawFields {
+				str = strings.Trim(str, " \t")
+				if cr.cfg.Null != "" && str == cr.cfg.Null {
+					str = ""
+				}
+			}
+			cr.record = append(cr.record, str)
+			cr.currentColNum++
+			start += w
+		}
+		cr.field = cr.field[:0]
+		return cr.finishRecord()
+	}
+}
+
+// readByte reads the next byte from the input, keeping the byte offset and
+// physical line count up to date
+func (cr *Reader) readByte() (byte, error) {
+	b, err := cr.r.ReadByte()
+	if err != nil {
+		return b, err
+	}
+	cr.bytesRead++
+	if cr.cfg.KeepRawLine {
+		cr.raw = append(cr.raw, b)
+	}
+	if b == '\n' {
+		cr.lineNum++
+		if cr.prevCR {
+			cr.endings[1]++
+		} else {
+			cr.endings[0]++
+		}
+	} else if b == '\r' {
+		// A lone '\r' ends a line; for "\r\n" the '\n' is counted instead
+		if next, err := cr.r.Peek(1); err != nil || len(next) == 0 || next[0] != '\n' {
+			cr.lineNum++
+			cr.endings[2]++
+		}
+	}
+	cr.prevCR = b == '\r'
+	return b, nil
+}
+
+// RawLine returns the text of the most recent record as it appeared in the
+// input, before quote processing and trimming and without its line ending.
+// Quoted line breaks are included. After a parse error it holds the text
+// read so far. RawLine is empty unless Config.KeepRawLine is set.
+func (cr *Reader) RawLine() string {
+	raw := cr.raw
+	if n := len(raw); n > 0 && raw[n-1] == '\n' {
+		raw = raw[:n-1]
+	}
+	if n := len(raw); n > 0 && raw[n-1] == '\r' {
+		raw = raw[:n-1]
+	}
+	return string(raw)
+}
+
+// LineEndingStats returns how many "\n", "\r\n" and lone "\r" line endings
+// have been read so far, including those inside quoted fields
+func (cr *Reader) LineEndingStats() (lf, crlf, lone int) {
+	return cr.endings[0], cr.endings[1], cr.endings[2]
+}
+
+// finishRecord marks the record being built as complete and returns it. In
+// strict mode a record whose field count differs from the first record's is
+// returned as an ErrFieldCount error instead; reading can continue with the
+// next record.
+func (cr *Reader) finishRecord() ([]string, error) {
+	cr.endOfField = false
+	cr.currentRecord = cr.record
+	cr.currentRowNum++
+	cr.countRecord(len(cr.record))
+	if cr.currentColNum > 0 {
+		cr.currentColNum-- // point at the last field read
+	}
+	if cr.cfg.StrictRFC4180 {
+		if cr.fieldsPerRec == 0 {
+			cr.fieldsPerRec = len(cr.record)
+		} else if len(cr.record) != cr.fieldsPerRec {
+			return nil, fmt.Errorf("%s: %w: got %d, want %d",
+				cr.Position(), ErrFieldCount, len(cr.record), cr.fieldsPerRec)
+		}
+	}
+	return cr.record, nil
+}
+
+// countRecord adds a record of n fields to the record and field counters
+func (cr *Reader) countRecord(n int) {
+	if cr.records == 0 || n < cr.minFields {
+		cr.minFields = n
+	}
+	cr.maxFields = max(cr.maxFields, n)
+	cr.records++
+	cr.fields += int64(n)
+}
+
+// syntaxError records err, located at the current position, as the reader's
+// permanent error and returns it
+func (cr *Reader) syntaxError(err error) error {
+	cr.currentRowNum++ // the record in progress was never finished
+	cr.err = fmt.Errorf("%s: %w", cr.Position(), err)
+	return cr.err
+}
+
+// isCommentStart reports whether b begins a comment at the current position.
+// A comment never starts inside quotes or in the middle of a field. The
+// comment character must be the first byte of a physical line, unless
+// AllowInlineComments lets it also start any unquoted field, in which case
+// the rest of the line is ignored.
+func (cr *Reader) isCommentStart(b byte, atLineStart bool) bool {
+	if cr.cfg.Comment == 0 || b != byte(cr.cfg.Comment) || cr.inQuotes || len(cr.field) != 0 {
+		return false
+	}
+	return atLineStart || cr.cfg.AllowInlineComments
+}
+
+// skipLine discards input up to and including the next line ending
+func (cr *Reader) skipLine() {
+	for {
+		b, err := cr.readByte()
+		if err != nil {
+			return
+		}
+		if b == '\n' {
+			return
+		}
+		if b == '\r' {
+			// Check for \n in Windows line endings
+			if next, err := cr.r.Peek(1); err == nil && len(next) > 0 && next[0] == '\n' {
+				_, _ = cr.readByte()
+			}
+			return
 		}
 	}
 }
 
 // New field commit logic
 func (cr *Reader) commitField() {
-	// Save the buffer and return it to pool
-	buf := cr.field
-	defer func() {
-		buf = buf[:0]
-		fieldPool.Put(&buf)
-	}()
-
-	str := string(buf)
+	// string() copies, so the field buffer can be reused for the next field
+	str := string(cr.field)
+	cr.field = cr.field[:0]
+	if cr.cfg.RawFields {
+		cr.quoteEnd = -1
+		cr.record = append(cr.record, str)
+		cr.currentColNum++
+		return
+	}
 
-	if cr.cfg.TrimLeading {
-		str = strings.TrimLeft(str, " \t")
+	// Whitespace inside quotes is data; only the unquoted parts are trimmed
+	if cr.quoteEnd < 0 {
+		if cr.cfg.TrimLeading || cr.cfg.TrimSpace {
+			str = strings.TrimLeft(str, " \t")
+		}
+		if cr.cfg.TrimTrailing || cr.cfg.TrimSpace {
+			str = strings.TrimRight(str, " \t")
+		}
+	} else if cr.cfg.TrimTrailing || cr.cfg.TrimSpace {
+		str = str[:cr.quoteEnd] + strings.TrimRight(str[cr.quoteEnd:], " \t")
 	}
+	cr.quoteEnd = -1
 	if cr.cfg.Null != "" && str == cr.cfg.Null {
 		str = ""
 	}
 
 	cr.record = append(cr.record, str)
-	cr.field = *(fieldPool.Get().(*[]byte)) // Get pointer and dereference
+	cr.currentColNum++
 }
 
 // FieldCount returns the number of fields in the current record
@@ -235,40 +785,233 @@ func (cr *Reader) BytesRead() int64 {
 	return cr.bytesRead
 }
 
+// CurrentLine returns the physical line (1-based) on which the current record starts.
+// It differs from CurrentRow when comments or quoted newlines are present.
+func (cr *Reader) CurrentLine() int64 {
+	return cr.recordLine
+}
+
+// BufferSize returns the size of the Reader's input buffer in bytes
+func (cr *Reader) BufferSize() int {
+	return cr.r.Size()
+}
+
 // Position returns the current parsing position for error reporting
 func (cr *Reader) Position() string {
-	return fmt.Sprintf("row %d, column %d", cr.currentRowNum, cr.currentColNum+1)
+	return fmt.Sprintf("row %d, column %d (line %d, byte offset %d)",
+		cr.currentRowNum, cr.currentColNum+1, cr.recordLine, cr.bytesRead)
 }
 
+// Progress callbacks fire whenever this many bytes or data rows have been
+// read since the last call
+const (
+	progressBytes = 64 * 1024
+	progressRows  = 10000
+)
+
 // ToTable reads the entire CSV and returns it as a Table
 func (cr *Reader) ToTable() (*Table, error) {
+	return cr.toTable(-1, nil)
+}
+
+// ToTableN reads the header and at most maxRows data rows and returns them as
+// a Table, leaving the reader positioned at the next record. A negative
+// maxRows reads every row.
+func (cr *Reader) ToTableN(maxRows int) (*Table, error) {
+	return cr.toTable(maxRows, nil)
+}
+
+// toTable reads the header and up to maxRows data rows (all rows if maxRows
+// is negative) into a Table, calling progress, if non-nil, periodically and
+// once more when the input is exhausted if anything was read since the last
+// call
+func (cr *Reader) toTable(maxRows int, progress func(bytesRead, rowsRead int64)) (*Table, error) {
 	// Read first row as headers
-	headers, err := cr.ReadRecord()
+	first, err := cr.ReadRecord()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read headers: %w", err)
 	}
 
+	if cr.cfg.ReuseRecord {
+		first = append([]string(nil), first...)
+	}
+	headers, err := tableHeaders(cr.cfg, first)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create table with headers
 	table := NewTable(headers)
+	table.setFormats(cr.cfg)
+
+	// Without a header row the first record is data
+	var pending []string
+	if cr.cfg.NoHeader {
+		pending = first
+	}
 
 	// Read remaining rows
-	for {
-		record, err := cr.ReadRecord()
-		if err == io.EOF {
+	var rows, lastBytes, lastRows int64 = 0, cr.bytesRead, 0
+	for maxRows < 0 || rows < int64(maxRows) {
+		record := pending
+		if record != nil {
+			pending = nil
+		} else if record, err = cr.ReadRecord(); err == io.EOF {
+			if progress != nil && (cr.bytesRead > lastBytes || rows > lastRows) {
+				progress(cr.bytesRead, rows)
+			}
 			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read record: %w", err)
+		} else if cr.cfg.ReuseRecord {
+			// The table keeps every row, so it cannot share the reader's buffer
+			record = append([]string(nil), record...)
 		}
+		record, keep, err := cr.fitRow(record, len(table.Headers))
 		if err != nil {
-			return nil, fmt.Errorf("failed to read record: %w", err)
+			return nil, err
+		}
+		if !keep {
+			continue
 		}
 		if err := table.AddRow(record); err != nil {
 			return nil, fmt.Errorf("failed to add row: %w", err)
 		}
+
+		rows++
+		if progress != nil && (cr.bytesRead-lastBytes >= progressBytes || rows-lastRows >= progressRows) {
+			progress(cr.bytesRead, rows)
+			lastBytes, lastRows = cr.bytesRead, rows
+		}
 	}
 
 	return table, nil
 }
 
-// ReadTable is a convenience function to read a CSV file directly into a Table
+// tableHeaders returns the headers for a table whose first record is first:
+// generated names when cfg.NoHeader is set, otherwise first itself after
+// prepareHeaders
+func tableHeaders(cfg Config, first []string) ([]string, error) {
+	if !cfg.NoHeader {
+		return prepareHeaders(cfg, first)
+	}
+	n := len(first)
+	if cfg.TrimTrailingEmptyField && n > 1 && first[n-1] == "" {
+		n--
+	}
+	headers := make([]string, n)
+	for i := range headers {
+		headers[i] = "col" + strconv.Itoa(i+1)
+	}
+	return headers, nil
+}
+
+// prepareHeaders applies the header options in cfg: an empty last header is
+// dropped with TrimTrailingEmptyField and repeated names are handled
+// according to DuplicateHeaders.
+func prepareHeaders(cfg Config, headers []string) ([]string, error) {
+	if cfg.TrimTrailingEmptyField && len(headers) > 1 && headers[len(headers)-1] == "" {
+		headers = headers[:len(headers)-1]
+	}
+	if cfg.DuplicateHeaders == DuplicateHeadersAllow {
+		return headers, nil
+	}
+
+	seen := make(map[string]bool, len(headers))
+	for _, h := range headers {
+		seen[h] = true
+	}
+	counts := make(map[string]int, len(headers))
+	renamed := make([]string, len(headers))
+	for i, h := range headers {
+		counts[h]++
+		renamed[i] = h
+		if counts[h] == 1 {
+			continue
+		}
+		if cfg.DuplicateHeaders == DuplicateHeadersError {
+			return nil, fmt.Errorf("column %d: %w %q", i+1, ErrDuplicateHeader, h)
+		}
+		// Skip suffixes that collide with other headers
+		name := fmt.Sprintf("%s_%d", h, counts[h])
+		for seen[name] {
+			counts[h]++
+			name = fmt.Sprintf("%s_%d", h, counts[h])
+		}
+		seen[name] = true
+		renamed[i] = name
+	}
+	return renamed, nil
+}
+
+// fitRecord applies the ragged-row options in cfg to a record that should
+// have n fields, reporting whether it was padded or truncated. Records that
+// still don't fit are returned unchanged.
+func fitRecord(cfg Config, record []string, n int) ([]string, bool) {
+	if cfg.TrimTrailingEmptyField && len(record) == n+1 && record[n] == "" {
+		return record[:n], false
+	}
+	pad := cfg.PadShortRecords || cfg.OnRagged == RaggedPad || cfg.OnRagged == RaggedTruncate
+	if pad && len(record) < n {
+		padded := make([]string, n)
+		copy(padded, record)
+		return padded, true
+	}
+	if cfg.OnRagged == RaggedTruncate && len(record) > n {
+		return record[:n], true
+	}
+	return record, false
+}
+
+// fitRow fits a record read by cr to n fields with fitRecord, counting the
+// adjusted records. It returns keep false for a record left out under
+// RaggedSkip, and an ErrFieldCount error for one that doesn't fit otherwise.
+func (cr *Reader) fitRow(record []string, n int) ([]string, bool, error) {
+	record, adjusted := fitRecord(cr.cfg, record, n)
+	if adjusted {
+		cr.ragged++
+	}
+	if len(record) == n {
+		return record, true, nil
+	}
+	if cr.cfg.OnRagged == RaggedSkip {
+		cr.ragged++
+		return nil, false, nil
+	}
+	return nil, false, fmt.Errorf("%s: %w: got %d, want %d", cr.Position(), ErrFieldCount, len(record), n)
+}
+
+// RecordsRead returns how many records have been read so far, the header
+// included as one record even when Config.HeaderRows combines several.
+// Comment lines are not records.
+func (cr *Reader) RecordsRead() int64 {
+	return cr.records
+}
+
+// FieldsRead returns the total number of fields in the records read so far
+func (cr *Reader) FieldsRead() int64 {
+	return cr.fields
+}
+
+// FieldCountRange returns the fewest and most fields in any record read so
+// far, or zeros if none was read. The two differ when the input has ragged
+// rows, whether or not they are rejected, padded or truncated afterwards.
+func (cr *Reader) FieldCountRange() (min, max int) {
+	return cr.minFields, cr.maxFields
+}
+
+// RaggedRows returns how many records ToTable and the streaming functions
+// have padded, truncated or skipped so far because their field count
+// differed from the header's
+func (cr *Reader) RaggedRows() int64 {
+	return cr.ragged
+}
+
+// ReadTable is a convenience function to read a CSV file directly into a Table.
+// The first record becomes the headers and every following record a row;
+// records with the wrong number of fields, after applying the ragged-row
+// options in cfg, fail with ErrFieldCount. Column types are inferred as rows
+// are added.
 func ReadTable(rd io.Reader, cfg Config) (*Table, error) {
 	reader, err := NewReader(rd, cfg)
 	if err != nil {
@@ -276,3 +1019,26 @@ func ReadTable(rd io.Reader, cfg Config) (*Table, error) {
 	}
 	return reader.ToTable()
 }
+
+// ReadTableN reads the headers and at most maxRows data rows, stopping as soon
+// as the limit is reached, which makes it cheap to preview large files. A
+// negative maxRows reads every row, like ReadTable.
+func ReadTableN(rd io.Reader, cfg Config, maxRows int) (*Table, error) {
+	reader, err := NewReader(rd, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return reader.ToTableN(maxRows)
+}
+
+// ReadTableWithProgress reads a CSV into a Table like ReadTable, calling
+// progress with the bytes and data rows read so far about every 64KB or 10,000
+// rows, and once more at the end of the input. Both counts only increase
+// between calls; an input with no data rows may not trigger a call at all.
+func ReadTableWithProgress(rd io.Reader, cfg Config, progress func(bytesRead, rowsRead int64)) (*Table, error) {
+	reader, err := NewReader(rd, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return reader.toTable(-1, progress)
+}
@@ -0,0 +1,389 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FilterExpr returns a new table containing only rows that match expr, a
+// condition such as `age > 30 AND department = "IT"`.
+//
+// Each condition compares a column with a value using =, ==, !=, >, <, >=
+// or <=, or tests it with contains, startswith, endswith or matches (a regular
+// expression). Conditions are combined with AND and OR (case-insensitive, AND
+// binds tighter) and may be grouped with parentheses. Integer and float
+// columns are compared numerically, and string columns ordered as dates when
+// the value is a date; everything else compares as strings. Column names and
+// values containing spaces or operator characters must be quoted with single
+// or double quotes.
+func (t *Table) FilterExpr(expr string) (*Table, error) {
+	tokens, err := tokenizeExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter expression")
+	}
+
+	p := &exprParser{tokens: tokens, table: t}
+	pred, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in filter expression", p.tokens[p.pos].text)
+	}
+	return t.Filter(pred), nil
+}
+
+type exprTokenKind int
+
+const (
+	tokWord exprTokenKind = iota // bare column name or value
+	tokQuoted
+	tokOp
+	tokAnd
+	tokOr
+	tokLParen
+	tokRParen
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	text string
+}
+
+// tokenizeExpr splits a filter expression into tokens
+func tokenizeExpr(expr string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, exprToken{tokLParen, "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, exprToken{tokRParen, ")"})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in filter expression")
+			}
+			tokens = append(tokens, exprToken{tokQuoted, expr[i+1 : i+1+end]})
+			i += end + 2
+		case strings.IndexByte("=!<>", c) >= 0:
+			op := string(c)
+			if i+1 < len(expr) && expr[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unknown operator %q in filter expression", op)
+			}
+			tokens = append(tokens, exprToken{tokOp, op})
+			i += len(op)
+		default:
+			start := i
+			for i < len(expr) && strings.IndexByte(" \t()=!<>\"'", expr[i]) < 0 {
+				i++
+			}
+			word := expr[start:i]
+			switch strings.ToUpper(word) {
+			case "AND":
+				tokens = append(tokens, exprToken{tokAnd, word})
+			case "OR":
+				tokens = append(tokens, exprToken{tokOr, word})
+			default:
+				tokens = append(tokens, exprToken{tokWord, word})
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// exprParser is a recursive descent parser producing row predicates
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+	table  *Table
+}
+
+func (p *exprParser) next() (exprToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return exprToken{}, false
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok, true
+}
+
+func (p *exprParser) peek(kind exprTokenKind) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == kind
+}
+
+func (p *exprParser) parseOr() (func([]string) bool, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek(tokOr) {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row []string) bool { return l(row) || right(row) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (func([]string) bool, error) {
+	left, err := p.parseCondition()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek(tokAnd) {
+		p.pos++
+		right, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row []string) bool { return l(row) && right(row) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseCondition() (func([]string) bool, error) {
+	if p.peek(tokLParen) {
+		p.pos++
+		pred, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(tokRParen) {
+			return nil, fmt.Errorf("missing closing parenthesis in filter expression")
+		}
+		p.pos++
+		return pred, nil
+	}
+
+	col, ok := p.next()
+	if !ok || (col.kind != tokWord && col.kind != tokQuoted) {
+		return nil, fmt.Errorf("expected column name in filter expression")
+	}
+	op, ok := p.next()
+	if !ok || (op.kind != tokOp && !(op.kind == tokWord && isWordOperator(op.text))) {
+		return nil, fmt.Errorf("expected operator after %q in filter expression", col.text)
+	}
+	val, ok := p.next()
+	if !ok || (val.kind != tokWord && val.kind != tokQuoted) {
+		return nil, fmt.Errorf("expected value after %q %s in filter expression", col.text, op.text)
+	}
+
+	idx, ok := p.table.index[col.text]
+	if !ok {
+		return nil, fmt.Errorf("column %q not found", col.text)
+	}
+	return comparePredicate(idx, p.table.types[idx], p.table.numbers, op.text, val.text)
+}
+
+// FilterColumn returns a new table containing only rows whose column value
+// satisfies op against value, using the same operators and typing rules as
+// FilterExpr.
+func (t *Table) FilterColumn(column, op, value string) (*Table, error) {
+	idx, ok := t.index[column]
+	if !ok {
+		return nil, fmt.Errorf("column %q not found", column)
+	}
+	pred, err := comparePredicate(idx, t.types[idx], t.numbers, op, value)
+	if err != nil {
+		return nil, err
+	}
+	return t.Filter(pred), nil
+}
+
+// ColEquals returns a predicate for Filter matching rows whose value in col
+// equals val, comparing numerically in integer and float columns, so "1.0"
+// equals "1". Predicates can be combined with And, Or and Not, e.g.
+//
+//	t.Filter(And(ColEquals(t, "dept", "IT"), ColGreater(t, "salary", "1000")))
+//
+// The column is looked up once, when the predicate is built. If t has no
+// column col the predicate matches no rows; use ColCompare to have that
+// reported as an error, e.g. for columns chosen at run time.
+func ColEquals(t *Table, col, val string) func([]string) bool {
+	return colPredicate(t, col, "=", val)
+}
+
+// ColGreater returns a predicate matching rows whose value in col is greater
+// than val, following the typing rules of FilterExpr. Like ColEquals it
+// matches no rows if t has no column col, and also if col is numeric and val
+// is not a number.
+func ColGreater(t *Table, col, val string) func([]string) bool {
+	return colPredicate(t, col, ">", val)
+}
+
+// ColLess is like ColGreater, but matches values less than val
+func ColLess(t *Table, col, val string) func([]string) bool {
+	return colPredicate(t, col, "<", val)
+}
+
+// ColCompare returns a predicate matching rows whose value in col satisfies
+// op against val, with the operators and typing rules of FilterColumn. It
+// fails if t has no column col, op is unknown, or col is numeric and an
+// ordering op is given a val that is not a number.
+func ColCompare(t *Table, col, op, val string) (func([]string) bool, error) {
+	idx, ok := t.index[col]
+	if !ok {
+		return nil, fmt.Errorf("column %q not found", col)
+	}
+	pred, err := comparePredicate(idx, t.types[idx], t.numbers, op, val)
+	if err != nil {
+		return nil, fmt.Errorf("column %q: %w", col, err)
+	}
+	return pred, nil
+}
+
+// colPredicate is ColCompare, returning a predicate that matches no rows on
+// error
+func colPredicate(t *Table, col, op, val string) func([]string) bool {
+	pred, err := ColCompare(t, col, op, val)
+	if err != nil {
+		return func([]string) bool { return false }
+	}
+	return pred
+}
+
+// And returns a predicate matching rows that match every one of preds,
+// checking them in order and stopping at the first that does not match. And
+// with no predicates matches every row.
+func And(preds ...func([]string) bool) func([]string) bool {
+	return func(row []string) bool {
+		for _, pred := range preds {
+			if !pred(row) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns a predicate matching rows that match any of preds, stopping at
+// the first that does. Or with no predicates matches no rows.
+func Or(preds ...func([]string) bool) func([]string) bool {
+	return func(row []string) bool {
+		for _, pred := range preds {
+			if pred(row) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns a predicate matching the rows pred does not
+func Not(pred func([]string) bool) func([]string) bool {
+	return func(row []string) bool { return !pred(row) }
+}
+
+// comparePredicate builds a predicate comparing column idx with target.
+// Numeric columns compare numerically when target is a number, reading both
+// the cells and target as written in nf; ordering a numeric column against a
+// non-numeric target is an error. String columns are ordered as dates when
+// target is a date, and lexically otherwise.
+func comparePredicate(idx int, colType ColumnType, nf NumberFormat, op, target string) (func([]string) bool, error) {
+	switch op = strings.ToLower(op); op {
+	case "=", "==", "!=", ">", "<", ">=", "<=":
+	case "contains":
+		return func(row []string) bool { return strings.Contains(row[idx], target) }, nil
+	case "startswith":
+		return func(row []string) bool { return strings.HasPrefix(row[idx], target) }, nil
+	case "endswith":
+		return func(row []string) bool { return strings.HasSuffix(row[idx], target) }, nil
+	case "matches":
+		re, err := regexp.Compile(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", target, err)
+		}
+		return func(row []string) bool { return re.MatchString(row[idx]) }, nil
+	default:
+		return nil, fmt.Errorf("unknown operator %q", op)
+	}
+
+	if colType == TypeInteger || colType == TypeFloat {
+		want, err := nf.ParseFloat(target)
+		if err == nil {
+			return func(row []string) bool {
+				got, err := nf.ParseFloat(row[idx])
+				if err != nil {
+					// Nulls and malformed numbers only satisfy "not equal"
+					return op == "!="
+				}
+				return compareResult(compareFloats(got, want), op)
+			}, nil
+		}
+		if op != "=" && op != "==" && op != "!=" {
+			return nil, fmt.Errorf("value %q is not a number", target)
+		}
+	}
+
+	if colType == TypeString && op != "=" && op != "==" && op != "!=" {
+		if want, err := parseTime(target, ""); err == nil {
+			return func(row []string) bool {
+				got, err := parseTime(row[idx], "")
+				if err != nil {
+					return false
+				}
+				return compareResult(got.Compare(want), op)
+			}, nil
+		}
+	}
+
+	return func(row []string) bool {
+		return compareResult(strings.Compare(row[idx], target), op)
+	}, nil
+}
+
+// isWordOperator reports whether s is an operator spelled as a word
+func isWordOperator(s string) bool {
+	switch strings.ToLower(s) {
+	case "contains", "startswith", "endswith", "matches":
+		return true
+	}
+	return false
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareResult applies op to the result of a three-way comparison
+func compareResult(cmp int, op string) bool {
+	switch op {
+	case "=", "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
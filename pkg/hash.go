@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"sort"
+)
+
+// Hash returns a hex-encoded SHA-256 of the headers and rows in order. Equal
+// tables always hash equal, so the hash can be used to detect changes.
+func (t *Table) Hash() string {
+	return t.ContentHash(false)
+}
+
+// ContentHash is like Hash; with ignoreOrder set, the rows are hashed in
+// sorted order so tables holding the same rows in any order hash equal.
+// Column order always matters.
+func (t *Table) ContentHash(ignoreOrder bool) string {
+	h := sha256.New()
+	writeRecord(h, t.Headers)
+
+	rows := t.Rows
+	if ignoreOrder {
+		rows = append([][]string(nil), t.Rows...)
+		sort.Slice(rows, func(i, j int) bool {
+			return compareRecords(rows[i], rows[j]) < 0
+		})
+	}
+	for _, row := range rows {
+		writeRecord(h, row)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeRecord feeds a record to h with length prefixes, so different
+// records never produce the same byte stream
+func writeRecord(h hash.Hash, record []string) {
+	var buf [binary.MaxVarintLen64]byte
+	h.Write(buf[:binary.PutUvarint(buf[:], uint64(len(record)))])
+	for _, field := range record {
+		h.Write(buf[:binary.PutUvarint(buf[:], uint64(len(field)))])
+		h.Write([]byte(field))
+	}
+}
+
+// compareRecords orders records field by field
+func compareRecords(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
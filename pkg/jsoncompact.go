@@ -0,0 +1,131 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// compactJSON is the document written by ExportToJSONCompact
+type compactJSON struct {
+	Columns []string        `json:"columns"`
+	Types   []string        `json:"types"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+// columnTypeNames are the names used for column types in compact JSON
+var columnTypeNames = map[ColumnType]string{
+	TypeString:  "string",
+	TypeInteger: "integer",
+	TypeFloat:   "float",
+	TypeBoolean: "boolean",
+	TypeNull:    "null",
+}
+
+// ExportToJSONCompact writes the table as a single JSON object holding the
+// column names, their types and the rows as arrays of values, e.g.
+// {"columns":["id"],"types":["integer"],"rows":[[1],[2]]}. Values are typed
+// per column like ExportToJSON; numbers keep their original digits.
+func (t *Table) ExportToJSONCompact(writer io.Writer) error {
+	if t == nil || len(t.Headers) == 0 {
+		return fmt.Errorf("cannot export empty table")
+	}
+
+	doc := compactJSON{
+		Columns: t.Headers,
+		Types:   make([]string, len(t.Headers)),
+		Rows:    make([][]interface{}, len(t.Rows)),
+	}
+	for i, ct := range t.types {
+		doc.Types[i] = columnTypeNames[ct]
+	}
+	for i, row := range t.Rows {
+		values := make([]interface{}, len(row))
+		for j, v := range row {
+			values[j] = t.compactValue(v, t.types[j])
+		}
+		doc.Rows[i] = values
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(doc)
+}
+
+// compactValue converts a cell to the JSON value for a column of type colType
+func (t *Table) compactValue(value string, colType ColumnType) interface{} {
+	if DetectType(value) == TypeNull {
+		return nil
+	}
+	switch colType {
+	case TypeInteger, TypeFloat:
+		// Reuse the number's text, rewritten from the table's
+		// NumberFormat, when it is a valid JSON number
+		if n, ok := t.numbers.Normalize(value); ok {
+			if n = strings.TrimPrefix(n, "+"); json.Valid([]byte(n)) {
+				return json.Number(n)
+			}
+		}
+	case TypeBoolean:
+		if b, ok := t.bools.parse(value); ok {
+			return b
+		}
+		if b, err := strconv.ParseBool(strings.ToLower(value)); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// ReadTableFromJSONCompact reads a table written by ExportToJSONCompact.
+// Null values become empty cells and booleans are written in lower case.
+func ReadTableFromJSONCompact(r io.Reader) (*Table, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var doc compactJSON
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error decoding compact JSON: %w", err)
+	}
+	if len(doc.Columns) == 0 {
+		return nil, fmt.Errorf("compact JSON has no columns")
+	}
+
+	table := NewTable(doc.Columns)
+	for i, values := range doc.Rows {
+		if len(values) != len(doc.Columns) {
+			return nil, fmt.Errorf("row %d has %d values, expected %d", i+1, len(values), len(doc.Columns))
+		}
+		row := make([]string, len(values))
+		for j, v := range values {
+			switch v := v.(type) {
+			case nil:
+				row[j] = ""
+			case string:
+				row[j] = v
+			case json.Number:
+				row[j] = v.String()
+			case bool:
+				row[j] = strconv.FormatBool(v)
+			default:
+				return nil, fmt.Errorf("row %d, column %q: unsupported value %v", i+1, doc.Columns[j], v)
+			}
+		}
+		if err := table.AddRow(row); err != nil {
+			return nil, err
+		}
+	}
+
+	// Declared types take precedence over the detected ones
+	if len(doc.Types) == len(doc.Columns) {
+		for i, name := range doc.Types {
+			for ct, n := range columnTypeNames {
+				if n == name {
+					table.types[i] = ct
+				}
+			}
+		}
+	}
+	return table, nil
+}
@@ -0,0 +1,156 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ExportToJSONL writes the table as JSON Lines: one JSON object per row, with
+// keys in column order and values typed per column like ExportToJSON, and no
+// enclosing array.
+func (t *Table) ExportToJSONL(writer io.Writer) error {
+	if t == nil || len(t.Headers) == 0 {
+		return fmt.Errorf("cannot export empty table")
+	}
+
+	keys := make([][]byte, len(t.Headers))
+	for i, h := range t.Headers {
+		key, err := marshalJSON(h)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	bw := bufio.NewWriter(writer)
+	var line bytes.Buffer
+	for i, row := range t.Rows {
+		line.Reset()
+		line.WriteByte('{')
+		for j, value := range row {
+			if j > 0 {
+				line.WriteByte(',')
+			}
+			v, err := marshalJSON(t.jsonValue(value, t.types[j]))
+			if err != nil {
+				return fmt.Errorf("row %d, column %q: %w", i+1, t.Headers[j], err)
+			}
+			line.Write(keys[j])
+			line.WriteByte(':')
+			line.Write(v)
+		}
+		line.WriteString("}\n")
+		if _, err := bw.Write(line.Bytes()); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// marshalJSON encodes v without escaping HTML characters or a trailing newline
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// ReadTableFromJSONL reads a table from JSON Lines, one object per row.
+// Columns appear in the order their keys are first seen; a row missing a key
+// gets an empty value. Nulls become empty values, numbers keep their original
+// digits, booleans are written in lower case, and nested arrays and objects
+// are kept as JSON text.
+func ReadTableFromJSONL(r io.Reader) (*Table, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var headers []string
+	index := make(map[string]int)
+	var records []map[int]string
+	for n := 1; ; n++ {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %d: error decoding JSON: %w", n, err)
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+			return nil, fmt.Errorf("record %d: expected a JSON object", n)
+		}
+
+		record := make(map[int]string)
+		for decoder.More() {
+			tok, err := decoder.Token()
+			if err != nil {
+				return nil, fmt.Errorf("record %d: error decoding JSON: %w", n, err)
+			}
+			key := tok.(string) // object keys are always strings
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				return nil, fmt.Errorf("record %d, key %q: error decoding JSON: %w", n, key, err)
+			}
+			value, err := jsonlValue(raw)
+			if err != nil {
+				return nil, fmt.Errorf("record %d, key %q: %w", n, key, err)
+			}
+
+			col, ok := index[key]
+			if !ok {
+				col = len(headers)
+				index[key] = col
+				headers = append(headers, key)
+			}
+			record[col] = value
+		}
+		if _, err := decoder.Token(); err != nil { // closing brace
+			return nil, fmt.Errorf("record %d: error decoding JSON: %w", n, err)
+		}
+		records = append(records, record)
+	}
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("JSON Lines input has no columns")
+	}
+
+	table := NewTable(headers)
+	for _, record := range records {
+		row := make([]string, len(headers))
+		for col, value := range record {
+			row[col] = value
+		}
+		if err := table.AddRow(row); err != nil {
+			return nil, err
+		}
+	}
+	return table, nil
+}
+
+// jsonlValue converts a JSON value to a table cell
+func jsonlValue(raw json.RawMessage) (string, error) {
+	switch raw[0] {
+	case 'n':
+		return "", nil
+	case 't', 'f':
+		return string(raw), nil
+	case '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	case '{', '[':
+		return string(raw), nil
+	}
+	// Anything else is a number; keep its digits
+	if _, err := strconv.ParseFloat(string(raw), 64); err != nil {
+		return "", fmt.Errorf("unsupported value %s", raw)
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,199 @@
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LintSeverity ranks how serious a lint finding is
+type LintSeverity int
+
+const (
+	SeverityInfo LintSeverity = iota
+	SeverityWarning
+	SeverityError // The file could not be read past this point
+)
+
+// String returns the severity's name
+func (s LintSeverity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("LintSeverity(%d)", int(s))
+}
+
+// LintWarning is one finding reported by LintCSV
+type LintWarning struct {
+	Severity LintSeverity
+	Row      int    // Record number (the header is row 0), or -1 for the whole file
+	Column   string // Column name, if the finding is about one column
+	Message  string
+}
+
+// String formats the warning for display
+func (w LintWarning) String() string {
+	var sb strings.Builder
+	sb.WriteString(w.Severity.String())
+	if w.Row >= 0 {
+		fmt.Fprintf(&sb, ": row %d", w.Row)
+	}
+	if w.Column != "" {
+		fmt.Fprintf(&sb, ": column %q", w.Column)
+	}
+	sb.WriteString(": " + w.Message)
+	return sb.String()
+}
+
+// utf8BOM is the byte order mark some editors put at the start of UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// lintColumn accumulates per-column statistics while linting
+type lintColumn struct {
+	padded, paddedRow int // cells with surrounding whitespace, first such row
+	numeric, text     int
+	boolean           int
+	firstText         string
+	firstTextRow      int
+}
+
+// LintCSV reads a CSV stream and reports problems that don't stop it from
+// being parsed: a byte order mark, mixed line endings, empty or duplicate
+// headers, rows whose field count differs from the header, values with
+// leading or trailing whitespace, and columns mixing numbers, booleans and
+// text, with numbers read in cfg.NumberFormat and booleans also as
+// cfg.BoolTrue and cfg.BoolFalse. Records are streamed, so memory use does
+// not grow with the file. A parse error is reported with SeverityError and
+// ends the lint.
+func LintCSV(r io.Reader, cfg Config) []LintWarning {
+	return LintCSVN(r, cfg, -1)
+}
+
+// LintCSVN lints like LintCSV but stops after at most maxRows data rows, so a
+// large file can be sampled quickly. Problems past the sample, including
+// parse errors, are not reported. A negative maxRows lints every row.
+func LintCSVN(r io.Reader, cfg Config, maxRows int) []LintWarning {
+	var warnings []LintWarning
+	warn := func(sev LintSeverity, row int, col, format string, args ...interface{}) {
+		warnings = append(warnings, LintWarning{sev, row, col, fmt.Sprintf(format, args...)})
+	}
+
+	br := bufio.NewReader(r)
+	if head, _ := br.Peek(len(utf8BOM)); bytes.Equal(head, utf8BOM) {
+		warn(SeverityWarning, -1, "", "file starts with a UTF-8 byte order mark")
+		_, _ = br.Discard(len(utf8BOM))
+	}
+
+	cfg.ReuseRecord = true
+	reader, err := NewReader(br, cfg)
+	if err != nil {
+		warn(SeverityError, -1, "", "%v", err)
+		return warnings
+	}
+
+	header, err := reader.ReadRecord()
+	if err == io.EOF {
+		warn(SeverityWarning, -1, "", "file is empty")
+		return warnings
+	}
+	if err != nil {
+		warn(SeverityError, 0, "", "%v", err)
+		return warnings
+	}
+	headers := append([]string(nil), header...)
+	seen := make(map[string]bool, len(headers))
+	for i, h := range headers {
+		switch {
+		case strings.TrimSpace(h) == "":
+			warn(SeverityWarning, 0, "", "column %d has an empty header", i+1)
+		case seen[h]:
+			warn(SeverityWarning, 0, h, "duplicate header")
+		}
+		seen[h] = true
+	}
+
+	cols := make([]lintColumn, len(headers))
+	bools := boolWords{cfg.BoolTrue, cfg.BoolFalse}
+	rows := 0
+	for maxRows < 0 || rows < maxRows {
+		record, err := reader.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			warn(SeverityError, rows+1, "", "%v", err)
+			break
+		}
+		rows++
+
+		if len(record) != len(headers) {
+			warn(SeverityWarning, rows, "", "has %d fields, header has %d", len(record), len(headers))
+		}
+		for i, v := range record {
+			if i >= len(cols) {
+				break
+			}
+			c := &cols[i]
+			if v != strings.TrimSpace(v) {
+				if c.padded == 0 {
+					c.paddedRow = rows
+				}
+				c.padded++
+			}
+			switch detectTypeWith(strings.TrimSpace(v), cfg.NumberFormat, bools) {
+			case TypeInteger, TypeFloat:
+				c.numeric++
+			case TypeBoolean:
+				c.boolean++
+			case TypeString:
+				if c.text == 0 {
+					c.firstText, c.firstTextRow = v, rows
+				}
+				c.text++
+			}
+		}
+	}
+
+	for i, c := range cols {
+		if c.padded > 0 {
+			warn(SeverityWarning, -1, headers[i], "%d values have leading or trailing whitespace, first in row %d", c.padded, c.paddedRow)
+		}
+		kinds := 0
+		for _, n := range []int{c.numeric, c.boolean, c.text} {
+			if n > 0 {
+				kinds++
+			}
+		}
+		if kinds > 1 {
+			msg := fmt.Sprintf("mixes value types: %d numeric, %d boolean, %d text", c.numeric, c.boolean, c.text)
+			if c.text > 0 {
+				msg += fmt.Sprintf(" (first text value %q in row %d)", c.firstText, c.firstTextRow)
+			}
+			warn(SeverityWarning, -1, headers[i], "%s", msg)
+		}
+	}
+
+	if lf, crlf, cr := reader.LineEndingStats(); MixedLineEndings(lf, crlf, cr) {
+		warn(SeverityWarning, -1, "", "mixed line endings: %d CRLF, %d LF, %d CR", crlf, lf, cr)
+	}
+	return warnings
+}
+
+// MixedLineEndings reports whether more than one of the line ending counts
+// returned by Reader.LineEndingStats is non-zero
+func MixedLineEndings(lf, crlf, cr int) bool {
+	kinds := 0
+	for _, n := range []int{lf, crlf, cr} {
+		if n > 0 {
+			kinds++
+		}
+	}
+	return kinds > 1
+}
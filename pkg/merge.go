@@ -0,0 +1,46 @@
+package pkg
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ReadTables reads the CSV files at paths, any of which may be
+// gzip-compressed, into one table holding the rows of each file in turn, as
+// when combining monthly exports. Every file must have the same headers in
+// the same order as the first; only the first file's header row is kept.
+func ReadTables(paths []string, cfg Config) (*Table, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files to read")
+	}
+
+	var result *Table
+	for _, path := range paths {
+		table, err := readTableFile(path, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if result == nil {
+			result = table
+			continue
+		}
+		if !slices.Equal(table.Headers, result.Headers) {
+			return nil, fmt.Errorf("%s: headers %q do not match %q in %s",
+				path, table.Headers, result.Headers, paths[0])
+		}
+		if err := result.AppendRows(table.Rows); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return result, nil
+}
+
+// readTableFile reads the CSV file at path with ReadTable
+func readTableFile(path string, cfg Config) (*Table, error) {
+	file, err := OpenMaybeCompressed(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadTable(file, cfg)
+}
@@ -0,0 +1,185 @@
+package pkg
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NumberFormat describes how numbers are written in data that does not use
+// plain numbers, such as "$1,234.56" from a spreadsheet, "1.234,56 €", "45%"
+// or the accounting negative "(1,234)". The zero value accepts plain numbers
+// only, as DetectType does; these may have a leading '+'.
+type NumberFormat struct {
+	// ThousandsSeparator groups the digits before the decimal separator in
+	// threes, as ',' does in "1,234,567". Groups must be complete, so "1,23"
+	// is not a number. Zero allows no grouping.
+	ThousandsSeparator rune
+
+	// DecimalSeparator separates the fraction; '.' if zero
+	DecimalSeparator rune
+
+	// CurrencySymbols may each appear once before or after the number, such
+	// as "$" in "$12" and "-$12", or "€" in "12 €"
+	CurrencySymbols []string
+
+	// Percent accepts a trailing '%', as in "45%" or "12.5 %". The number
+	// is read as written, so "45%" is 45, unless PercentAsFraction is set.
+	Percent bool
+
+	// PercentAsFraction divides percentages by 100, so "45%" is 0.45
+	PercentAsFraction bool
+
+	// ParenNegative reads a number in parentheses as negative, as
+	// accounting does: "(1,234)" is -1234
+	ParenNegative bool
+}
+
+// plain reports whether nf accepts only plain numbers
+func (nf NumberFormat) plain() bool {
+	return nf.ThousandsSeparator == 0 && (nf.DecimalSeparator == 0 || nf.DecimalSeparator == '.') &&
+		len(nf.CurrencySymbols) == 0 && !nf.Percent && !nf.ParenNegative
+}
+
+// Normalize returns s rewritten as a plain number, e.g. "-1234.56" for
+// "-$1,234.56", and whether s is a number in this format
+func (nf NumberFormat) Normalize(s string) (string, bool) {
+	if nf.plain() {
+		_, err := strconv.ParseFloat(s, 64)
+		return s, err == nil
+	}
+
+	percent := false
+	cutPercent := func() {
+		if rest, ok := strings.CutSuffix(s, "%"); nf.Percent && !percent && ok {
+			s, percent = strings.TrimRight(rest, " "), true
+		}
+	}
+	cutPercent()
+	negative := false
+	if nf.ParenNegative && len(s) > 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		s, negative = s[1:len(s)-1], true
+	}
+	cutPercent() // inside the parentheses, as in "(5%)"
+
+	sign := ""
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+	for _, sym := range nf.CurrencySymbols {
+		if sym == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(s, sym); ok {
+			s = strings.TrimLeft(rest, " ")
+			break
+		}
+		if rest, ok := strings.CutSuffix(s, sym); ok {
+			s = strings.TrimRight(rest, " ")
+			break
+		}
+	}
+	if sign == "" && s != "" && (s[0] == '-' || s[0] == '+') {
+		// The sign may also follow a leading currency symbol, as in "$-12"
+		sign, s = s[:1], s[1:]
+	}
+
+	dec := nf.DecimalSeparator
+	if dec == 0 {
+		dec = '.'
+	}
+	intPart, frac, hasFrac := strings.Cut(s, string(dec))
+	if nf.ThousandsSeparator != 0 && nf.ThousandsSeparator != dec &&
+		strings.ContainsRune(intPart, nf.ThousandsSeparator) {
+		groups := strings.Split(intPart, string(nf.ThousandsSeparator))
+		for i, g := range groups {
+			if (i == 0 && (len(g) == 0 || len(g) > 3)) || (i > 0 && len(g) != 3) ||
+				strings.Trim(g, "0123456789") != "" {
+				return "", false
+			}
+		}
+		intPart = strings.Join(groups, "")
+	}
+
+	if negative {
+		if sign != "" {
+			return "", false
+		}
+		sign = "-"
+	}
+	n := sign + intPart
+	if hasFrac {
+		n += "." + frac
+	}
+	f, err := strconv.ParseFloat(n, 64)
+	if err != nil {
+		return "", false
+	}
+	if percent && nf.PercentAsFraction {
+		n = percentFraction(n, f)
+	}
+	return n, true
+}
+
+// percentFraction returns the plain number n, whose value is f, divided by
+// 100. The decimal point is moved so the digits are kept exactly, as in
+// "0.125" for "12.5"; numbers with an exponent are divided as floats.
+func percentFraction(n string, f float64) string {
+	sign := ""
+	if n[0] == '-' || n[0] == '+' {
+		sign, n = n[:1], n[1:]
+	}
+	if strings.Trim(n, "0123456789.") != "" {
+		return strconv.FormatFloat(f/100, 'g', -1, 64)
+	}
+	intPart, frac, _ := strings.Cut(n, ".")
+	digits := intPart + frac
+	point := len(intPart) - 2
+	if point <= 0 {
+		return sign + "0." + strings.Repeat("0", -point) + digits
+	}
+	intPart = strings.TrimLeft(digits[:point], "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+	return sign + intPart + "." + digits[point:]
+}
+
+// DetectType is like the package-level DetectType, but recognizes numbers
+// written in this format
+func (nf NumberFormat) DetectType(val string) ColumnType {
+	if nf.plain() {
+		return DetectType(val)
+	}
+	if t := DetectType(val); t == TypeNull || t == TypeBoolean {
+		return t
+	}
+	if n, ok := nf.Normalize(val); ok {
+		return DetectType(n)
+	}
+	return TypeString
+}
+
+// ParseInt parses an integer written in this format, with the bit size
+// strconv.ParseInt takes
+func (nf NumberFormat) ParseInt(s string, bitSize int) (int64, error) {
+	if nf.plain() {
+		return strconv.ParseInt(s, 10, bitSize)
+	}
+	n, ok := nf.Normalize(s)
+	if !ok {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrSyntax}
+	}
+	return strconv.ParseInt(n, 10, bitSize)
+}
+
+// ParseFloat parses a number written in this format
+func (nf NumberFormat) ParseFloat(s string) (float64, error) {
+	if nf.plain() {
+		return strconv.ParseFloat(s, 64)
+	}
+	n, ok := nf.Normalize(s)
+	if !ok {
+		return 0, &strconv.NumError{Func: "ParseFloat", Num: s, Err: strconv.ErrSyntax}
+	}
+	return strconv.ParseFloat(n, 64)
+}
@@ -0,0 +1,279 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// minChunkSize is the smallest byte range worth handing to a separate worker
+const minChunkSize = 64 * 1024
+
+// ReadTableParallel reads size bytes of CSV from r into a Table using up to
+// workers goroutines. A workers value <= 0 uses runtime.NumCPU().
+//
+// The input is split into byte ranges that end on record boundaries. Boundaries
+// are found with a quote-aware scan, so a quoted field containing newlines is
+// never cut in half. Each range is then parsed concurrently and the rows are
+// appended to the table in their original order. Small inputs, and configs
+// that allow inline comments, use SkipRows or HeaderRows, or read fixed-width
+// columns, are read serially.
+// In strict mode every chunk checks its records against the header's field
+// count, and errors report the same positions as a serial read.
+func ReadTableParallel(r io.ReaderAt, size int64, cfg Config, workers int) (*Table, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if maxWorkers := int(size / minChunkSize); workers > maxWorkers {
+		workers = maxWorkers
+	}
+	cfg.ReuseRecord = false // records are retained across chunks
+
+	// Compressed input cannot be split, so it is read serially
+	compressed := cfg.AutoDecompress && isGzipAt(r)
+
+	if workers <= 1 || compressed || (cfg.Comment != 0 && cfg.AllowInlineComments) ||
+		cfg.SkipRows > 0 || cfg.HeaderRows > 1 || len(cfg.FixedWidths) > 0 {
+		return ReadTable(io.NewSectionReader(r, 0, size), cfg)
+	}
+
+	bounds, err := splitRecords(r, size, cfg, workers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split input: %w", err)
+	}
+
+	// Strict mode compares every record with the first, which only the
+	// first chunk would otherwise see
+	fields := 0
+	if cfg.StrictRFC4180 {
+		reader, err := NewReader(io.NewSectionReader(r, 0, size), cfg)
+		if err != nil {
+			return nil, err
+		}
+		first, err := reader.ReadRecord()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read headers: %w", err)
+		}
+		fields = len(first)
+	}
+
+	chunks := make([][][]string, len(bounds)-1)
+	lines := make([]int64, len(bounds)-1)
+	errs := make([]error, len(bounds)-1)
+	section := func(i int) io.Reader {
+		return io.NewSectionReader(r, bounds[i], bounds[i+1]-bounds[i])
+	}
+	var wg sync.WaitGroup
+	for i := range chunks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := chunkStart{bytes: bounds[i], fields: fields}
+			chunks[i], lines[i], errs[i] = readChunk(section(i), cfg, start)
+		}(i)
+	}
+	wg.Wait()
+
+	// The rows and lines before a chunk are known only once the chunks
+	// before it are read, so a failed chunk is read again from its true
+	// starting position to report where the error is in the whole input
+	start := chunkStart{fields: fields}
+	starts := make([]chunkStart, len(chunks))
+	for i, err := range errs {
+		start.bytes = bounds[i]
+		starts[i] = start
+		if err != nil {
+			if _, _, err = readChunk(section(i), cfg, start); err == nil {
+				err = errs[i]
+			}
+			return nil, fmt.Errorf("failed to read record: %w", err)
+		}
+		start.rows += int64(len(chunks[i]))
+		start.lines += lines[i]
+	}
+
+	if len(chunks[0]) == 0 {
+		return nil, fmt.Errorf("failed to read headers: %w", io.EOF)
+	}
+	headers, err := tableHeaders(cfg, chunks[0][0])
+	if err != nil {
+		return nil, err
+	}
+	table := NewTable(headers)
+	table.setFormats(cfg)
+	if !cfg.NoHeader {
+		chunks[0] = chunks[0][1:]
+	}
+
+	for i, records := range chunks {
+		for _, record := range records {
+			record, _ = fitRecord(cfg, record, len(table.Headers))
+			if len(record) != len(table.Headers) {
+				if cfg.OnRagged == RaggedSkip {
+					continue
+				}
+				// Read the chunk again from its true starting position
+				// so the error has the position a serial read reports
+				return nil, fieldCountError(section(i), cfg, starts[i],
+					len(table.Headers), i == 0 && !cfg.NoHeader)
+			}
+			if err := table.AddRow(record); err != nil {
+				return nil, fmt.Errorf("failed to add row: %w", err)
+			}
+		}
+	}
+
+	return table, nil
+}
+
+// chunkStart is where a chunk begins in the whole input
+type chunkStart struct {
+	rows, lines, bytes int64
+	fields             int // field count every record must have in strict mode, 0 to take the first
+}
+
+// readChunk parses every record in rd, a chunk starting at start, and returns
+// them with the number of physical lines read. Error positions count from
+// start.
+func readChunk(rd io.Reader, cfg Config, start chunkStart) ([][]string, int64, error) {
+	reader, err := newChunkReader(rd, cfg, start)
+	if err != nil {
+		return nil, 0, err
+	}
+	var records [][]string
+	for {
+		record, err := reader.ReadRecord()
+		if err == io.EOF {
+			return records, reader.lineNum - start.lines, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		records = append(records, record)
+	}
+}
+
+// fieldCountError reads the chunk rd starting at start again, fitting every
+// record to n fields as a serial read does, and returns the error for the
+// first record that does not fit. skipHeader leaves out the chunk's first
+// record, the header.
+func fieldCountError(rd io.Reader, cfg Config, start chunkStart, n int, skipHeader bool) error {
+	reader, err := newChunkReader(rd, cfg, start)
+	if err != nil {
+		return err
+	}
+	for {
+		record, err := reader.ReadRecord()
+		if err != nil {
+			return fmt.Errorf("failed to read record: %w", err)
+		}
+		if skipHeader {
+			skipHeader = false
+			continue
+		}
+		if _, _, err := reader.fitRow(record, n); err != nil {
+			return err
+		}
+	}
+}
+
+// newChunkReader returns a Reader for rd, a chunk starting at start, that
+// counts rows, lines and bytes from start
+func newChunkReader(rd io.Reader, cfg Config, start chunkStart) (*Reader, error) {
+	reader, err := NewReader(rd, cfg)
+	if err != nil {
+		return nil, err
+	}
+	reader.currentRowNum = start.rows
+	reader.lineNum = start.lines
+	reader.bytesRead = start.bytes
+	reader.fieldsPerRec = start.fields
+	return reader, nil
+}
+
+// splitRecords returns the offsets at which to split the input into at most
+// parts ranges, starting with 0 and ending with size. Every inner offset is the
+// start of a record: it follows a newline that is outside quotes and outside a
+// comment line.
+func splitRecords(r io.ReaderAt, size int64, cfg Config, parts int) ([]int64, error) {
+	quote := byte(cfg.Quote)
+	quoting := cfg.Quote != 0
+	comment := byte(cfg.Comment)
+
+	chunk := size / int64(parts)
+	bounds := []int64{0}
+	next := chunk
+
+	br := bufio.NewReaderSize(io.NewSectionReader(r, 0, size), 64*1024)
+	var (
+		offset       int64
+		inQuotes     bool
+		inComment    bool
+		atLineStart  = true
+		atFieldStart = true // only a quote at the start of a field opens quotes
+		justClosed   bool   // the previous byte closed a quoted field
+	)
+	trimLeading := cfg.TrimLeading || cfg.TrimSpace
+	delim := byte(cfg.Delimiter)
+	whitespace := cfg.WhitespaceDelimited
+	// Stop once the last boundary is found; the tail needs no scanning
+	for len(bounds) < parts {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		offset++
+
+		if inComment {
+			if b == '\n' {
+				inComment = false
+				atLineStart = true
+			}
+			continue
+		}
+		if atLineStart && !inQuotes && cfg.Comment != 0 && b == comment {
+			inComment = true
+			atLineStart = false
+			continue
+		}
+		atLineStart = false
+
+		closed := false
+		switch {
+		case quoting && b == quote && inQuotes:
+			// An escaped quote closes and immediately reopens the field
+			inQuotes = false
+			closed = true
+		case quoting && b == quote:
+			// Elsewhere in an unquoted field a quote is data
+			inQuotes = atFieldStart || justClosed
+			atFieldStart = false
+		case inQuotes:
+		case whitespace && (b == ' ' || b == '\t'):
+			// In whitespace-delimited mode the delimiter is data and a
+			// run of spaces or tabs separates fields
+			atFieldStart = true
+		case (b == delim && !whitespace) || b == '\r':
+			atFieldStart = true
+		case b == '\n':
+			atLineStart = true
+			atFieldStart = true
+			if offset >= next && offset < size {
+				bounds = append(bounds, offset)
+				next = offset + chunk
+			}
+		case trimLeading && atFieldStart && (b == ' ' || b == '\t'):
+			// Skipped by the parser, so the field has not started yet
+		default:
+			atFieldStart = false
+		}
+		justClosed = closed
+	}
+
+	return append(bounds, size), nil
+}
@@ -0,0 +1,101 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PickSpec selects the row GroupByPick keeps for each group
+type PickSpec struct {
+	Func   string // first, last, min or max
+	Column string // Column compared by min and max
+}
+
+// GroupByPick groups rows by groupCols like GroupBy, but instead of
+// aggregating keeps one complete row per group: the first or last in row
+// order, or the one with the smallest or largest value in pick.Column, e.g.
+// the highest-paid employee in each department. min and max compare numeric
+// columns as numbers, skipping nulls, and other columns as strings; ties go
+// to the earliest row. The result has all of t's columns, with one row per
+// group in the order the groups are first seen.
+func (t *Table) GroupByPick(groupCols []string, pick PickSpec) (*Table, error) {
+	groupIndices := make([]int, len(groupCols))
+	for i, col := range groupCols {
+		idx, ok := t.index[col]
+		if !ok {
+			return nil, fmt.Errorf("group column %q not found", col)
+		}
+		groupIndices[i] = idx
+	}
+
+	fn := strings.ToLower(pick.Func)
+	col := -1
+	switch fn {
+	case "first", "last":
+	case "min", "max":
+		idx, ok := t.index[pick.Column]
+		if !ok {
+			return nil, fmt.Errorf("pick column %q not found", pick.Column)
+		}
+		col = idx
+	default:
+		return nil, fmt.Errorf("unknown pick %q, expected first, last, min or max", pick.Func)
+	}
+	numeric := col >= 0 && (t.types[col] == TypeInteger || t.types[col] == TypeFloat)
+
+	// better reports whether row a should replace b, the group's current pick
+	better := func(a, b []string) bool {
+		switch fn {
+		case "first":
+			return false
+		case "last":
+			return true
+		}
+		if numeric {
+			fa, errA := t.numbers.ParseFloat(a[col])
+			fb, errB := t.numbers.ParseFloat(b[col])
+			switch {
+			case errA != nil:
+				return false
+			case errB != nil:
+				return true
+			case fn == "min":
+				return fa < fb
+			default:
+				return fa > fb
+			}
+		}
+		if fn == "min" {
+			return a[col] < b[col]
+		}
+		return a[col] > b[col]
+	}
+
+	picks := make(map[string]int) // group key to index into order
+	var order [][]string
+	key := make([]string, len(groupIndices))
+	for _, row := range t.Rows {
+		for i, idx := range groupIndices {
+			key[i] = row[idx]
+		}
+		groupKey := strings.Join(key, "\x00")
+		i, ok := picks[groupKey]
+		if !ok {
+			picks[groupKey] = len(order)
+			order = append(order, row)
+			continue
+		}
+		if better(row, order[i]) {
+			order[i] = row
+		}
+	}
+
+	result := NewTable(append([]string{}, t.Headers...))
+	result.numbers, result.bools = t.numbers, t.bools
+	for _, row := range order {
+		if err := result.AddRow(append([]string{}, row...)); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// profilePrecision is the number of decimals ProfileTable reports
+const profilePrecision = 2
+
+// profileTopValues is the number of most frequent values ProfileTable lists
+const profileTopValues = 3
+
+// ProfileTable returns one row per column of t describing its contents: the
+// detected type, the number and percentage of null cells, the number of
+// distinct non-null values, and the minimum and maximum. Numeric columns
+// also get the mean, median and sample standard deviation of their values;
+// other columns instead list their most frequent values with their counts,
+// e.g. "red (4), blue (2)", ties going to the value seen first. Numeric
+// minimums and maximums are compared as numbers and shown as written;
+// others are compared as strings.
+func ProfileTable(t *Table) *Table {
+	result := NewTable([]string{"column", "type", "nulls", "null_pct", "unique",
+		"min", "max", "mean", "median", "stddev", "top_values"})
+	for _, col := range t.Columns() {
+		numeric := col.Type == TypeInteger || col.Type == TypeFloat
+
+		counts := make(map[string]int)
+		var order []string // distinct values in first-seen order
+		var vals []float64
+		var lo, hi string
+		var loVal, hiVal float64
+		nulls := 0
+		for _, cell := range col.Values {
+			if DetectType(cell) == TypeNull {
+				nulls++
+				continue
+			}
+			if counts[cell] == 0 {
+				order = append(order, cell)
+			}
+			counts[cell]++
+
+			if !numeric {
+				if lo == "" || cell < lo {
+					lo = cell
+				}
+				if hi == "" || cell > hi {
+					hi = cell
+				}
+				continue
+			}
+			f, err := t.numbers.ParseFloat(cell)
+			if err != nil {
+				continue
+			}
+			if len(vals) == 0 || f < loVal {
+				lo, loVal = cell, f
+			}
+			if len(vals) == 0 || f > hiVal {
+				hi, hiVal = cell, f
+			}
+			vals = append(vals, f)
+		}
+
+		nullPct := ""
+		if len(t.Rows) > 0 {
+			nullPct = formatNumber(100*float64(nulls)/float64(len(t.Rows)), profilePrecision)
+		}
+		row := []string{col.Name, columnTypeNames[col.Type], strconv.Itoa(nulls), nullPct,
+			strconv.Itoa(len(order)), lo, hi, "", "", "", ""}
+		if numeric && len(vals) > 0 {
+			m := mean(vals)
+			row[7] = formatNumber(m, profilePrecision)
+			row[8] = formatNumber(median(vals), profilePrecision)
+			row[9] = formatNumber(sampleStdDev(vals, m), profilePrecision)
+		} else if !numeric {
+			row[10] = topValues(order, counts, profileTopValues)
+		}
+		// Rows always match the result headers
+		_ = result.AddRow(row)
+	}
+	return result
+}
+
+// median returns the median of vals, which must not be empty. vals is not
+// modified.
+func median(vals []float64) float64 {
+	sorted := append([]float64(nil), vals...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// topValues formats the n most frequent of values, given in first-seen order,
+// as "value (count)" separated by commas
+func topValues(values []string, counts map[string]int, n int) string {
+	sorted := append([]string(nil), values...)
+	sort.SliceStable(sorted, func(a, b int) bool { return counts[sorted[a]] > counts[sorted[b]] })
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	parts := make([]string, len(sorted))
+	for i, v := range sorted {
+		parts[i] = fmt.Sprintf("%s (%d)", v, counts[v])
+	}
+	return strings.Join(parts, ", ")
+}
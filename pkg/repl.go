@@ -2,10 +2,14 @@ package pkg
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"math"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // REPL represents the interactive CSV analysis environment
@@ -15,17 +19,132 @@ type REPL struct {
 	undoStack    []*Table
 	redoStack    []*Table
 	formats      map[string]FormatOptions
+	format       FormatOptions
+	formatName   string
 	history      []string
+
+	// ContinueOnError keeps RunScript going after a failing command
+	ContinueOnError bool
 }
 
 // NewREPL creates a new REPL instance
 func NewREPL() *REPL {
 	return &REPL{
-		undoStack: make([]*Table, 0),
-		redoStack: make([]*Table, 0),
-		formats:   make(map[string]FormatOptions),
-		history:   make([]string, 0),
+		undoStack:  make([]*Table, 0),
+		redoStack:  make([]*Table, 0),
+		formats:    replFormats(),
+		format:     replFormat(),
+		formatName: "rounded",
+		history:    make([]string, 0),
+	}
+}
+
+// replFormat returns the default format fitted to the terminal width
+func replFormat() FormatOptions {
+	format := DefaultFormat()
+	format.FitToWidth = -1
+	return format
+}
+
+// replFormats returns the named table styles the format command switches
+// between
+func replFormats() map[string]FormatOptions {
+	ascii := replFormat()
+	ascii.Style = DefaultStyle
+	fancy := replFormat()
+	fancy.Style = FancyStyle
+	plain := replFormat()
+	plain.Style = DefaultStyle
+	plain.HeaderStyle, plain.HeaderColor, plain.BorderColor = "", "", ""
+	plain.AlternateRows = false
+	return map[string]FormatOptions{
+		"rounded": replFormat(),
+		"fancy":   fancy,
+		"ascii":   ascii,
+		"plain":   plain,
+	}
+}
+
+// formatNames returns the names of the REPL's table styles in sorted order
+func (r *REPL) formatNames() []string {
+	names := make([]string, 0, len(r.formats))
+	for name := range r.formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setFormat switches to the named table style. Only the look changes:
+// options changed with set, such as the column width, are kept.
+func (r *REPL) setFormat(name string) error {
+	style, ok := r.formats[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown format %q (available: %s)", name, strings.Join(r.formatNames(), ", "))
+	}
+	r.format.Style = style.Style
+	r.format.HeaderStyle = style.HeaderStyle
+	r.format.HeaderColor = style.HeaderColor
+	r.format.BorderColor = style.BorderColor
+	r.format.AlternateRows = style.AlternateRows
+	r.format.AlternateColor = style.AlternateColor
+	r.formatName = strings.ToLower(name)
+	return nil
+}
+
+// setOption changes one display option of the current format. option must
+// be lower case.
+func (r *REPL) setOption(option, value string) error {
+	switch option {
+	case "maxwidth", "width":
+		n := -1 // width auto fits the terminal
+		if option == "maxwidth" || strings.ToLower(value) != "auto" {
+			v, err := strconv.Atoi(value)
+			if err != nil || v < 0 {
+				return fmt.Errorf("invalid %s %q, expected a number of columns (0 for unlimited)", option, value)
+			}
+			n = v
+		}
+		if option == "maxwidth" {
+			r.format.MaxColumnWidth = n
+		} else {
+			r.format.FitToWidth = n
+		}
+		return nil
+	}
+
+	on, err := parseOnOff(value)
+	if err != nil {
+		return err
+	}
+	switch option {
+	case "numbered":
+		r.format.NumberedRows = on
+	case "wrap":
+		r.format.WrapText = on
+	case "alternate":
+		r.format.AlternateRows = on
+	case "headers":
+		r.format.HideHeaders = !on
+	default:
+		return fmt.Errorf("unknown option %q (available: maxwidth, width, numbered, wrap, alternate, headers)", option)
 	}
+	return nil
+}
+
+// parseOnOff parses a set value such as on, off, true or false
+func parseOnOff(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "on", "yes":
+		return true, nil
+	case "off", "no":
+		return false, nil
+	}
+	on, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q, expected on or off", value)
+	}
+	return on, nil
 }
 
 // pushUndo adds the current table state to the undo stack
@@ -36,13 +155,15 @@ func (r *REPL) pushUndo() {
 	}
 }
 
+// errExit is returned by execute when the session should end
+var errExit = errors.New("exit")
+
 // Start begins the REPL session
 func (r *REPL) Start() {
 	fmt.Println("Welcome to the CSV Parser REPL!")
 	fmt.Println("Type 'help' for available commands or 'exit' to quit")
 
 	scanner := bufio.NewScanner(os.Stdin)
-	mainFormat := DefaultFormat()
 
 	for {
 		fmt.Print("\n> ")
@@ -50,81 +171,363 @@ func (r *REPL) Start() {
 			break
 		}
 
-		input := scanner.Text()
-		args := strings.Fields(input)
-		if len(args) == 0 {
+		if err := r.execute(scanner.Text()); err != nil {
+			if errors.Is(err, errExit) {
+				fmt.Println("Goodbye!")
+				return
+			}
+			fmt.Printf("Error: %v\n", err)
+		}
+	}
+}
+
+// RunScript executes REPL commands read line by line from the file at path.
+// Blank lines and lines starting with '#' are skipped. Execution stops at the
+// first failing command unless ContinueOnError is set.
+func (r *REPL) RunScript(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening script: %w", err)
+	}
+	defer file.Close()
+
+	var failed int
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 
-		command := strings.ToLower(args[0])
-		switch command {
-		case "exit":
-			fmt.Println("Goodbye!")
-			return
-		case "help":
-			r.showHelp()
-		case "load":
-			if len(args) < 2 {
-				fmt.Println("Usage: load <file>")
-				continue
+		if err := r.execute(line); err != nil {
+			if errors.Is(err, errExit) {
+				return nil
 			}
-			if err := r.loadFile(args[1]); err != nil {
-				fmt.Printf("Error: %v\n", err)
+			if !r.ContinueOnError {
+				return fmt.Errorf("line %d: %w", lineNum, err)
 			}
-		case "info":
-			if r.currentTable == nil {
-				fmt.Println("No file loaded. Use 'load <file>' first.")
-				continue
-			}
-			r.showInfo()
-		case "preview":
-			if r.currentTable == nil {
-				fmt.Println("No file loaded. Use 'load <file>' first.")
-				continue
-			}
-			n := 5
-			if len(args) > 1 {
-				if n_, err := strconv.Atoi(args[1]); err == nil {
-					n = n_
+			fmt.Printf("Error on line %d: %v\n", lineNum, err)
+			failed++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading script: %w", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d script commands failed", failed)
+	}
+	return nil
+}
+
+// execute parses and runs a single REPL command line
+func (r *REPL) execute(input string) error {
+	args := strings.Fields(input)
+	if len(args) == 0 {
+		return nil
+	}
+
+	command := strings.ToLower(args[0])
+	switch command {
+	case "exit":
+		return errExit
+	case "help":
+		r.showHelp()
+	case "load":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: load <file>")
+		}
+		if err := r.loadFile(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Loaded %d rows from %s\n", len(r.currentTable.Rows), args[1])
+	case "info":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		r.showInfo()
+	case "preview":
+		// preview <file> [n] peeks at a file without loading it
+		if len(args) > 1 {
+			if _, err := strconv.Atoi(args[1]); err != nil {
+				n := 5
+				if len(args) > 2 {
+					if n_, err := strconv.Atoi(args[2]); err == nil {
+						n = n_
+					}
 				}
+				return r.previewFile(args[1], n)
 			}
-			r.showPreview(n, mainFormat)
-		case "export":
-			if r.currentTable == nil {
-				fmt.Println("No file loaded. Use 'load <file>' first.")
-				continue
+		}
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		n := 5
+		if len(args) > 1 {
+			if n_, err := strconv.Atoi(args[1]); err == nil {
+				n = n_
 			}
-			if len(args) < 3 {
-				fmt.Println("Usage: export <format> <output_file>")
-				fmt.Println("Formats: json, html")
-				continue
+		}
+		r.showPreview(n, r.format)
+	case "format":
+		if len(args) < 2 {
+			fmt.Printf("Format: %s (available: %s)\n", r.formatName, strings.Join(r.formatNames(), ", "))
+			return nil
+		}
+		if err := r.setFormat(args[1]); err != nil {
+			return err
+		}
+		if r.currentTable != nil {
+			r.showPreview(5, r.format)
+		}
+	case "set":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: set <option> <value>")
+		}
+		if err := r.setOption(strings.ToLower(args[1]), args[2]); err != nil {
+			return err
+		}
+		if r.currentTable != nil {
+			r.showPreview(5, r.format)
+		}
+	case "filter":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		if len(args) < 4 {
+			return fmt.Errorf("usage: filter <column> <operator> <value>")
+		}
+		filtered, err := r.currentTable.FilterColumn(args[1], args[2], strings.Join(args[3:], " "))
+		if err != nil {
+			return err
+		}
+		r.pushUndo()
+		r.currentTable = filtered
+		fmt.Printf("Filtered to %d rows\n", len(r.currentTable.Rows))
+	case "delete":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		if len(args) < 4 {
+			return fmt.Errorf("usage: delete <column> <operator> <value>")
+		}
+		idx, ok := r.currentTable.ColumnIndex(args[1])
+		if !ok {
+			return fmt.Errorf("column %q not found", args[1])
+		}
+		colType, _ := r.currentTable.GetColumnType(args[1])
+		pred, err := comparePredicate(idx, colType, r.currentTable.numbers, args[2], strings.Join(args[3:], " "))
+		if err != nil {
+			return err
+		}
+		r.pushUndo()
+		n := r.currentTable.DeleteWhere(pred)
+		fmt.Printf("Deleted %d rows, %d left\n", n, len(r.currentTable.Rows))
+	case "rename":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		if len(args) != 3 {
+			return fmt.Errorf("usage: rename <column> <new_name>")
+		}
+		renamed := r.currentTable.Copy()
+		if err := renamed.RenameColumn(args[1], args[2]); err != nil {
+			return err
+		}
+		r.pushUndo()
+		r.currentTable = renamed
+		fmt.Printf("Renamed %s to %s\n", args[1], args[2])
+	case "reorder":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("usage: reorder <column> [column...]")
+		}
+		reordered := r.currentTable.Copy()
+		if err := reordered.ReorderColumns(args[1:]); err != nil {
+			return err
+		}
+		r.pushUndo()
+		r.currentTable = reordered
+		fmt.Println("Columns reordered")
+	case "outliers":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		if len(args) < 2 || len(args) > 3 {
+			return fmt.Errorf("usage: outliers <column> [threshold]")
+		}
+		threshold := defaultOutlierThreshold
+		if len(args) == 3 {
+			t, err := strconv.ParseFloat(args[2], 64)
+			if err != nil || t <= 0 {
+				return fmt.Errorf("invalid threshold %q", args[2])
 			}
-			if err := r.exportTable(args[1], args[2]); err != nil {
-				fmt.Printf("Error: %v\n", err)
+			threshold = t
+		}
+		outliers, err := r.Outliers(args[1], threshold)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Found %d outliers in %s (|z| > %g)\n", len(outliers.Rows), args[1], threshold)
+		if len(outliers.Rows) > 0 {
+			fmt.Println(outliers.Format(r.format))
+		}
+	case "summarize":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		summary, err := r.currentTable.Summarize(args[1:]...)
+		if err != nil {
+			return err
+		}
+		fmt.Println(summary.Format(r.format))
+	case "profile":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		fmt.Println(ProfileTable(r.currentTable).Format(r.format))
+	case "correlate":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		method := CorrelationPearson
+		var columns []string
+		for _, arg := range args[1:] {
+			if strings.EqualFold(arg, "--spearman") {
+				method = CorrelationSpearman
 			} else {
-				fmt.Printf("Table exported to %s\n", args[2])
+				columns = append(columns, arg)
 			}
 		}
+		matrix, err := r.Correlate(columns, method)
+		if err != nil {
+			return err
+		}
+		fmt.Println(matrix.Format(r.format))
+	case "dates":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("usage: dates <column> [layout]")
+		}
+		// Layouts such as "2006-01-02 15:04" contain spaces
+		layout := strings.Join(args[2:], " ")
+		analysis, err := r.DateAnalysis(args[1], layout)
+		if err != nil {
+			return err
+		}
+		fmt.Println(analysis.Format(r.format))
+	case "crosstab":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		if len(args) < 3 {
+			return fmt.Errorf("usage: crosstab <row_column> <column_column>")
+		}
+		counts, err := r.currentTable.CrossTabWithOptions(args[1], args[2], CrossTabOptions{Totals: true})
+		if err != nil {
+			return err
+		}
+		fmt.Println(counts.Format(r.format))
+	case "transpose":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		r.pushUndo()
+		r.currentTable = r.currentTable.Transpose()
+		fmt.Printf("Transposed to %d rows x %d columns\n", len(r.currentTable.Rows), len(r.currentTable.Headers))
+	case "undo":
+		if len(r.undoStack) == 0 {
+			return fmt.Errorf("nothing to undo")
+		}
+		r.redoStack = append(r.redoStack, r.currentTable)
+		r.currentTable = r.undoStack[len(r.undoStack)-1]
+		r.undoStack = r.undoStack[:len(r.undoStack)-1]
+		fmt.Printf("Undone, %d rows\n", len(r.currentTable.Rows))
+	case "redo":
+		if len(r.redoStack) == 0 {
+			return fmt.Errorf("nothing to redo")
+		}
+		r.undoStack = append(r.undoStack, r.currentTable)
+		r.currentTable = r.redoStack[len(r.redoStack)-1]
+		r.redoStack = r.redoStack[:len(r.redoStack)-1]
+		fmt.Printf("Redone, %d rows\n", len(r.currentTable.Rows))
+	case "save":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("usage: save <file>")
+		}
+		if err := r.saveTable(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Table saved to %s\n", args[1])
+	case "export":
+		if err := r.requireTable(); err != nil {
+			return err
+		}
+		if len(args) < 3 {
+			return fmt.Errorf("usage: export <format> <output_file> (formats: json, jsonl, html, csv, md, xlsx)")
+		}
+		if err := r.exportTable(args[1], args[2]); err != nil {
+			return err
+		}
+		fmt.Printf("Table exported to %s\n", args[2])
+	default:
+		return fmt.Errorf("unknown command: %s (type 'help' for available commands)", command)
 	}
+	return nil
 }
 
-func (r *REPL) showHelp() {
-	fmt.Println(`Available commands:
+// requireTable returns an error if no table has been loaded yet
+func (r *REPL) requireTable() error {
+	if r.currentTable == nil {
+		return fmt.Errorf("no file loaded, use 'load <file>' first")
+	}
+	return nil
+}
+
+// REPLHelp lists the commands the REPL accepts, as shown by its help command
+const REPLHelp = `Available commands:
   load <file>              - Load a CSV file
   info                     - Show information about the current table
   preview [n]              - Show first n rows (default: 5)
-  stats                    - Show column statistics
+  preview <file> [n]       - Show first n rows of a file without loading it
   summarize [cols]         - Show detailed statistics for columns
-  correlate [cols]         - Show correlation matrix for numeric columns
-  pivot <row> <col> <val> - Create pivot table with aggregation
-  dates <col>             - Analyze dates in a column
-  export <format> <file>  - Export table (formats: json, html)
+  profile                  - Show type, nulls, range and common values per column
+  correlate [--spearman] [cols]
+                           - Show correlation matrix for numeric columns
+  filter <col> <op> <val> - Keep matching rows (=, !=, >, <, >=, <=, contains,
+                            startswith, endswith, matches)
+  delete <col> <op> <val> - Remove matching rows, with the same operators
+  dates <col> [layout]    - Analyze dates in a column (layout detected if omitted)
+  rename <col> <new>      - Rename a column
+  reorder <cols...>       - Rearrange columns into the given order
+  outliers <col> [z]      - Show rows whose z-score exceeds z (default: 3)
+  crosstab <row> <col>    - Count co-occurring values of two columns
+  transpose               - Turn columns into rows
+  format [name]           - Switch the table style (rounded, fancy, ascii, plain)
+  set <option> <value>    - Change a display option: maxwidth <n>, width <n|auto>,
+                            numbered, wrap, alternate or headers <on|off>
+  save <file>             - Save the current table as CSV
+  export <format> <file>  - Export table (formats: json, jsonl, html, csv, md, xlsx)
   undo                    - Undo last operation
   redo                    - Redo last undone operation
   help                    - Show this help message
-  exit                    - Exit the REPL`)
+  exit                    - Exit the REPL`
+
+func (r *REPL) showHelp() {
+	fmt.Println(REPLHelp)
 }
 
+// loadProgressMinSize is the file size from which load reports progress
+const loadProgressMinSize = 4 * 1024 * 1024
+
 func (r *REPL) loadFile(path string) error {
 	file, err := os.Open(path)
 	if err != nil {
@@ -132,7 +535,20 @@ func (r *REPL) loadFile(path string) error {
 	}
 	defer file.Close()
 
-	table, err := ReadTable(file, DefaultConfig())
+	// Show progress for files big enough to take a noticeable time. The
+	// percentage is of the file size, so it is not shown for compressed files,
+	// which are recognized by their header as ReadTable does.
+	var progress func(bytesRead, rowsRead int64)
+	compressed := isGzipAt(file)
+	if info, err := file.Stat(); err == nil && info.Size() >= loadProgressMinSize && !compressed {
+		size := info.Size()
+		progress = func(bytesRead, rowsRead int64) {
+			fmt.Printf("\rLoading %s: %3d%% (%d rows)", path, bytesRead*100/size, rowsRead)
+		}
+		defer fmt.Println()
+	}
+
+	table, err := ReadTableWithProgress(file, DefaultConfig(), progress)
 	if err != nil {
 		return fmt.Errorf("error reading table: %w", err)
 	}
@@ -144,9 +560,11 @@ func (r *REPL) loadFile(path string) error {
 }
 
 func (r *REPL) showInfo() {
+	rows, cols := r.currentTable.Shape()
 	fmt.Printf("File: %s\n", r.currentFile)
-	fmt.Printf("Rows: %d\n", len(r.currentTable.Rows))
-	fmt.Printf("Columns: %d\n\n", len(r.currentTable.Headers))
+	fmt.Printf("Rows: %d\n", rows)
+	fmt.Printf("Columns: %d\n", cols)
+	fmt.Printf("Memory: ~%s\n\n", FormatBytes(r.currentTable.MemoryEstimate()))
 
 	fmt.Println("Column Information:")
 	for i, header := range r.currentTable.Headers {
@@ -166,6 +584,197 @@ func (r *REPL) showPreview(n int, format FormatOptions) {
 	fmt.Println(preview.Format(format))
 }
 
+// defaultOutlierThreshold is the z-score above which the outliers command
+// flags a value
+const defaultOutlierThreshold = 3.0
+
+// Outliers returns the rows of the current table whose value in column has an
+// absolute z-score above threshold, with the score added as a "z_score"
+// column. Values that are not numbers are ignored, and a column with no
+// variation has no outliers.
+func (r *REPL) Outliers(column string, threshold float64) (*Table, error) {
+	if err := r.requireTable(); err != nil {
+		return nil, err
+	}
+	t := r.currentTable
+	vals, _, err := t.GetColumnFloats(column)
+	if err != nil {
+		return nil, err
+	}
+	idx := t.index[column]
+	m := mean(vals)
+	sd := stdDev(vals, m)
+
+	result := NewTable(append(append([]string{}, t.Headers...), "z_score"))
+	result.numbers, result.bools = t.numbers, t.bools
+	if sd == 0 {
+		return result, nil
+	}
+	for _, row := range t.Rows {
+		v, err := t.numbers.ParseFloat(strings.TrimSpace(row[idx]))
+		if err != nil {
+			continue
+		}
+		z := (v - m) / sd
+		if math.Abs(z) > threshold {
+			newRow := append(append(make([]string, 0, len(row)+1), row...), strconv.FormatFloat(z, 'f', 2, 64))
+			if err := result.AddRow(newRow); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// Correlation methods accepted by Correlate
+const (
+	CorrelationPearson  = "pearson"
+	CorrelationSpearman = "spearman"
+)
+
+// correlationPrecision is the number of decimals Correlate reports
+const correlationPrecision = 3
+
+// Correlate returns the correlation matrix of columns in the current table,
+// or of every numeric column if none are given. Method is CorrelationPearson
+// for linear correlation or CorrelationSpearman for rank correlation, which
+// also detects monotonic relationships that are not linear; tied values get
+// their average rank. Each pair is computed over the rows where both cells
+// are numbers. Undefined correlations, such as with a constant column, are
+// shown as "NaN".
+func (r *REPL) Correlate(columns []string, method string) (*Table, error) {
+	if err := r.requireTable(); err != nil {
+		return nil, err
+	}
+	t := r.currentTable
+
+	var correlate func(x, y []float64) float64
+	switch strings.ToLower(method) {
+	case CorrelationPearson, "":
+		correlate = calculateCorrelation
+	case CorrelationSpearman:
+		correlate = spearmanCorrelation
+	default:
+		return nil, fmt.Errorf("unknown correlation method %q (use pearson or spearman)", method)
+	}
+
+	if len(columns) == 0 {
+		for i, h := range t.Headers {
+			if t.types[i] == TypeInteger || t.types[i] == TypeFloat {
+				columns = append(columns, h)
+			}
+		}
+	}
+	if len(columns) < 2 {
+		return nil, fmt.Errorf("correlation needs at least two numeric columns")
+	}
+	indices := make([]int, len(columns))
+	for i, name := range columns {
+		idx, ok := t.index[name]
+		if !ok {
+			return nil, fmt.Errorf("column %q not found", name)
+		}
+		indices[i] = idx
+	}
+
+	result := NewTable(append([]string{"column"}, columns...))
+	for i, a := range indices {
+		row := []string{columns[i]}
+		for _, b := range indices {
+			var x, y []float64
+			for _, rec := range t.Rows {
+				fx, errX := t.numbers.ParseFloat(strings.TrimSpace(rec[a]))
+				fy, errY := t.numbers.ParseFloat(strings.TrimSpace(rec[b]))
+				if errX == nil && errY == nil {
+					x, y = append(x, fx), append(y, fy)
+				}
+			}
+			row = append(row, formatNumber(correlate(x, y), correlationPrecision))
+		}
+		if err := result.AddRow(row); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// DateAnalysis summarizes the dates in column of the current table: the
+// layout used, how many values are valid and invalid, the earliest and
+// latest dates and the number of days between them. If layout is empty it is
+// detected from the first value that parses with one of the common layouts
+// ToStructs accepts (ISO 8601 dates and timestamps, MM/DD/YYYY and
+// YYYY/MM/DD), and every other value is read with that layout. Empty cells
+// are ignored.
+func (r *REPL) DateAnalysis(column, layout string) (*Table, error) {
+	if err := r.requireTable(); err != nil {
+		return nil, err
+	}
+	col, err := r.currentTable.GetColumn(column)
+	if err != nil {
+		return nil, err
+	}
+
+	var earliest, latest time.Time
+	valid, invalid := 0, 0
+	for _, cell := range col {
+		cell = strings.TrimSpace(cell)
+		if DetectType(cell) == TypeNull {
+			continue
+		}
+		if layout == "" {
+			if detected, ok := detectTimeLayout(cell); ok {
+				layout = detected
+			}
+		}
+		tm, err := time.Parse(layout, cell)
+		if layout == "" || err != nil {
+			invalid++
+			continue
+		}
+		if valid == 0 || tm.Before(earliest) {
+			earliest = tm
+		}
+		if valid == 0 || tm.After(latest) {
+			latest = tm
+		}
+		valid++
+	}
+	if valid == 0 {
+		return nil, fmt.Errorf("no valid dates found in column %q", column)
+	}
+
+	result := NewTable([]string{"metric", "value"})
+	for _, row := range [][]string{
+		{"format", layout},
+		{"valid", strconv.Itoa(valid)},
+		{"invalid", strconv.Itoa(invalid)},
+		{"earliest", earliest.Format(layout)},
+		{"latest", latest.Format(layout)},
+		{"span_days", formatNumber(latest.Sub(earliest).Hours()/24, 1)},
+	} {
+		if err := result.AddRow(row); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// previewFile prints the first n rows of path, reading no further than needed
+func (r *REPL) previewFile(path string, n int) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	table, err := ReadTableN(file, DefaultConfig(), n)
+	if err != nil {
+		return fmt.Errorf("error reading table: %w", err)
+	}
+	fmt.Println(table.Format(r.format))
+	return nil
+}
+
 func minimum(a, b int) int {
 	if a < b {
 		return a
@@ -183,9 +792,30 @@ func (r *REPL) exportTable(format, path string) error {
 	switch strings.ToLower(format) {
 	case "json":
 		return r.currentTable.ExportToJSON(file)
+	case "jsonl", "ndjson":
+		return r.currentTable.ExportToJSONL(file)
 	case "html":
 		return r.currentTable.ExportToHTML(file)
+	case "csv":
+		return r.currentTable.WriteCSV(file, DefaultConfig())
+	case "md", "markdown":
+		return r.currentTable.ExportToMarkdown(file)
+	case "xlsx":
+		return r.currentTable.ExportToXLSX(file, XLSXOptions{FreezeHeader: true})
 	default:
-		return fmt.Errorf("unsupported format: %s (use 'json' or 'html')", format)
+		return fmt.Errorf("unsupported format: %s (use 'json', 'jsonl', 'html', 'csv', 'md' or 'xlsx')", format)
+	}
+}
+
+func (r *REPL) saveTable(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer file.Close()
+
+	if err := r.currentTable.WriteCSV(file, DefaultConfig()); err != nil {
+		return err
 	}
+	return file.Close()
 }
@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// RollingOptions controls RollingAggWithOptions
+type RollingOptions struct {
+	// Partial computes the aggregate over the rows available for the first
+	// window-1 rows instead of leaving them empty
+	Partial bool
+	// Name is the added column's name, defaulting to column_agg_window,
+	// e.g. "price_avg_3"
+	Name string
+}
+
+// RollingAgg returns a copy of the table with an added column holding agg
+// (avg, sum, minimum or maximum, also spelled min and max) of column over
+// each row and the window-1 rows before it. Null cells are left out of the
+// windows they fall in, and a window with only nulls gives an empty cell.
+// Rows without a full window are left empty. The table should already be
+// sorted, e.g. by date.
+func (t *Table) RollingAgg(column string, window int, agg string) (*Table, error) {
+	return t.RollingAggWithOptions(column, window, agg, RollingOptions{})
+}
+
+// RollingAggWithOptions is like RollingAgg with control over partial windows
+// and the added column's name
+func (t *Table) RollingAggWithOptions(column string, window int, agg string, opts RollingOptions) (*Table, error) {
+	if window < 1 {
+		return nil, fmt.Errorf("window must be at least 1, got %d", window)
+	}
+	agg = strings.ToLower(agg)
+	fn := agg
+	switch agg {
+	case "avg", "sum", "minimum", "maximum":
+	case "min":
+		fn = "minimum"
+	case "max":
+		fn = "maximum"
+	default:
+		return nil, fmt.Errorf("unknown rolling aggregation %q", agg)
+	}
+	idx, ok := t.index[column]
+	if !ok {
+		return nil, fmt.Errorf("column %q not found", column)
+	}
+	// Null cells have no value and are left out of their windows
+	vals := make([]float64, len(t.Rows))
+	isNull := make([]bool, len(t.Rows))
+	for i, row := range t.Rows {
+		if DetectType(row[idx]) == TypeNull {
+			isNull[i] = true
+			continue
+		}
+		f, err := t.numbers.ParseFloat(row[idx])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid number %q in column %q", i+1, row[idx], column)
+		}
+		vals[i] = f
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = fmt.Sprintf("%s_%s_%d", column, agg, window)
+	}
+	if _, exists := t.index[name]; exists {
+		return nil, fmt.Errorf("column %q already exists", name)
+	}
+
+	result := NewTable(append(append([]string{}, t.Headers...), name))
+	result.numbers, result.bools = t.numbers, t.bools
+	var windowVals []float64
+	for i, row := range t.Rows {
+		cell := ""
+		if start := i - window + 1; start >= 0 || opts.Partial {
+			windowVals = windowVals[:0]
+			for j := max(start, 0); j <= i; j++ {
+				if !isNull[j] {
+					windowVals = append(windowVals, vals[j])
+				}
+			}
+			if len(windowVals) > 0 {
+				cell = formatNumber(rollingValue(windowVals, fn), FullPrecision)
+			}
+		}
+		if err := result.AddRow(append(append(make([]string, 0, len(row)+1), row...), cell)); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// rollingValue aggregates one window of values
+func rollingValue(vals []float64, agg string) float64 {
+	switch agg {
+	case "minimum":
+		m := math.Inf(1)
+		for _, v := range vals {
+			m = math.Min(m, v)
+		}
+		return m
+	case "maximum":
+		m := math.Inf(-1)
+		for _, v := range vals {
+			m = math.Max(m, v)
+		}
+		return m
+	}
+	var sum float64
+	for _, v := range vals {
+		sum += v
+	}
+	if agg == "avg" {
+		return sum / float64(len(vals))
+	}
+	return sum
+}
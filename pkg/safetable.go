@@ -0,0 +1,62 @@
+package pkg
+
+import "sync"
+
+// SafeTable guards a Table for use from several goroutines, such as a server
+// formatting the table for many requests while a background job appends rows.
+// Reads share a read lock and modifications take the write lock. A plain
+// Table supports concurrent reads, but no reads during a modification.
+type SafeTable struct {
+	mu    sync.RWMutex
+	table *Table
+}
+
+// NewSafeTable wraps t. Once wrapped, t should only be used through the
+// SafeTable.
+func NewSafeTable(t *Table) *SafeTable {
+	return &SafeTable{table: t}
+}
+
+// AddRow adds a row to the table
+func (s *SafeTable) AddRow(row []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.table.AddRow(row)
+}
+
+// Len returns the number of rows
+func (s *SafeTable) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.table.Rows)
+}
+
+// GetColumn returns a copy of the values in a column
+func (s *SafeTable) GetColumn(header string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.table.GetColumn(header)
+}
+
+// Format formats the table like Table.Format
+func (s *SafeTable) Format(opts FormatOptions) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.table.Format(opts)
+}
+
+// Read calls fn with the table under the read lock. fn must not modify the
+// table or keep references to it, its rows or its headers after returning.
+func (s *SafeTable) Read(fn func(t *Table)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.table)
+}
+
+// Write calls fn with the table under the write lock and returns its error.
+// fn must not keep references to the table after returning.
+func (s *SafeTable) Write(fn func(t *Table) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.table)
+}
@@ -0,0 +1,246 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// The statistics helpers work on values already parsed as numbers; callers
+// decide which cells count. Non-finite inputs propagate: a column holding
+// "Inf", or values whose sum overflows, has an infinite mean and a NaN
+// standard deviation, which formatNumber spells out as "Inf" and "NaN".
+
+// mean returns the arithmetic mean of vals, or 0 for no values
+func mean(vals []float64) float64 {
+	if len(vals) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range vals {
+		sum += v
+	}
+	return sum / float64(len(vals))
+}
+
+// stdDev returns the population standard deviation of vals around m
+func stdDev(vals []float64, m float64) float64 {
+	if len(vals) == 0 {
+		return 0
+	}
+	var sq float64
+	for _, v := range vals {
+		sq += (v - m) * (v - m)
+	}
+	return math.Sqrt(sq / float64(len(vals)))
+}
+
+// sampleStdDev returns the sample standard deviation of vals around m, or 0
+// for fewer than two values
+func sampleStdDev(vals []float64, m float64) float64 {
+	if len(vals) < 2 {
+		return 0
+	}
+	var sq float64
+	for _, v := range vals {
+		sq += (v - m) * (v - m)
+	}
+	return math.Sqrt(sq / float64(len(vals)-1))
+}
+
+// summaryPrecision is the number of decimals Summarize reports
+const summaryPrecision = 2
+
+// Summarize returns one row of statistics per column: the number of numeric
+// values, the number of non-numeric values, and the mean, sample standard
+// deviation, minimum and maximum of the numeric ones. Empty and null cells
+// are not counted at all; any other cell that does not parse as a number is
+// counted as non-numeric and excluded from the statistics. Statistics that
+// are not finite are shown as "NaN", "Inf" or "-Inf", and columns without
+// numbers have empty statistics. With no columns given, every column is
+// summarized.
+func (t *Table) Summarize(columns ...string) (*Table, error) {
+	if len(columns) == 0 {
+		columns = t.Headers
+	}
+	result := NewTable([]string{"column", "count", "non_numeric", "mean", "stddev", "min", "max"})
+	for _, name := range columns {
+		col, err := t.GetColumn(name)
+		if err != nil {
+			return nil, err
+		}
+
+		var vals []float64
+		nonNumeric := 0
+		for _, cell := range col {
+			if DetectType(cell) == TypeNull {
+				continue
+			}
+			f, err := t.numbers.ParseFloat(cell)
+			if err != nil {
+				nonNumeric++
+				continue
+			}
+			vals = append(vals, f)
+		}
+
+		row := []string{name, strconv.Itoa(len(vals)), strconv.Itoa(nonNumeric), "", "", "", ""}
+		if len(vals) > 0 {
+			m := mean(vals)
+			lo, hi := vals[0], vals[0]
+			for _, v := range vals[1:] {
+				lo, hi = math.Min(lo, v), math.Max(hi, v)
+			}
+			row[3] = formatNumber(m, summaryPrecision)
+			row[4] = formatNumber(sampleStdDev(vals, m), summaryPrecision)
+			row[5] = formatNumber(lo, summaryPrecision)
+			row[6] = formatNumber(hi, summaryPrecision)
+		}
+		if err := result.AddRow(row); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// numericValues returns the non-null cells of the named column parsed as
+// numbers. It fails if the column is missing or has non-numeric values.
+func (t *Table) numericValues(name string) ([]float64, error) {
+	idx, ok := t.index[name]
+	if !ok {
+		return nil, fmt.Errorf("column %q not found", name)
+	}
+	if typ := t.types[idx]; typ != TypeInteger && typ != TypeFloat && typ != TypeNull {
+		return nil, fmt.Errorf("column %q is not numeric", name)
+	}
+	vals := make([]float64, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		if DetectType(row[idx]) == TypeNull {
+			continue
+		}
+		f, err := t.numbers.ParseFloat(row[idx])
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", name, err)
+		}
+		vals = append(vals, f)
+	}
+	return vals, nil
+}
+
+// SumColumn returns the sum of a numeric column, skipping null cells. A
+// column with only nulls sums to 0.
+func (t *Table) SumColumn(name string) (float64, error) {
+	vals, err := t.numericValues(name)
+	if err != nil {
+		return 0, err
+	}
+	var sum float64
+	for _, v := range vals {
+		sum += v
+	}
+	return sum, nil
+}
+
+// MeanColumn returns the mean of the non-null cells of a numeric column
+func (t *Table) MeanColumn(name string) (float64, error) {
+	vals, err := t.numericValues(name)
+	if err != nil {
+		return 0, err
+	}
+	if len(vals) == 0 {
+		return 0, fmt.Errorf("column %q has no values", name)
+	}
+	return mean(vals), nil
+}
+
+// MinColumn returns the smallest value of a numeric column, skipping nulls
+func (t *Table) MinColumn(name string) (float64, error) {
+	return t.columnExtreme(name, math.Min)
+}
+
+// MaxColumn returns the largest value of a numeric column, skipping nulls
+func (t *Table) MaxColumn(name string) (float64, error) {
+	return t.columnExtreme(name, math.Max)
+}
+
+// columnExtreme folds the non-null values of a numeric column with pick
+func (t *Table) columnExtreme(name string, pick func(a, b float64) float64) (float64, error) {
+	vals, err := t.numericValues(name)
+	if err != nil {
+		return 0, err
+	}
+	if len(vals) == 0 {
+		return 0, fmt.Errorf("column %q has no values", name)
+	}
+	result := vals[0]
+	for _, v := range vals[1:] {
+		result = pick(result, v)
+	}
+	return result, nil
+}
+
+// CountNonNull returns the number of cells in a column of any type that are
+// not null
+func (t *Table) CountNonNull(name string) (int, error) {
+	idx, ok := t.index[name]
+	if !ok {
+		return 0, fmt.Errorf("column %q not found", name)
+	}
+	count := 0
+	for _, row := range t.Rows {
+		if DetectType(row[idx]) != TypeNull {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// calculateCorrelation returns the Pearson correlation coefficient of x and
+// y, which must have the same length. It is NaN when either has no
+// variation, since the correlation is then undefined.
+func calculateCorrelation(x, y []float64) float64 {
+	mx, my := mean(x), mean(y)
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return math.NaN()
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// ranks returns the 1-based rank of each value in vals, giving tied values
+// the average of the ranks they span
+func ranks(vals []float64) []float64 {
+	order := make([]int, len(vals))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return vals[order[a]] < vals[order[b]] })
+
+	result := make([]float64, len(vals))
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && vals[order[end]] == vals[order[start]] {
+			end++
+		}
+		// Positions start..end-1 hold ranks start+1..end
+		avg := float64(start+1+end) / 2
+		for _, idx := range order[start:end] {
+			result[idx] = avg
+		}
+		start = end
+	}
+	return result
+}
+
+// spearmanCorrelation returns the Spearman rank correlation of x and y: the
+// Pearson correlation of their ranks
+func spearmanCorrelation(x, y []float64) float64 {
+	return calculateCorrelation(ranks(x), ranks(y))
+}
@@ -0,0 +1,609 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AggSpec describes one aggregation computed by StreamGroupBy
+type AggSpec struct {
+	Column string // Column to aggregate, or CountAll to count rows
+	Func   string // count, sum, avg, minimum, maximum, stddev, count_distinct, first or last
+	As     string // Result column name, defaults to Column ("count" for CountAll)
+}
+
+// runningAgg holds the state of one aggregation for one group
+type runningAgg struct {
+	count    int64
+	sum      float64
+	mean, m2 float64 // Welford's online variance
+	min, max string
+
+	// Numeric extremes, used instead of min and max when the column turns
+	// out to be numeric
+	minNum, maxNum string
+	lo, hi         float64
+
+	first, last string
+	distinct    map[string]struct{} // non-null values, for count_distinct
+}
+
+// StreamGroupBy groups the CSV in r by groupCols and computes aggs while
+// reading records one at a time, so memory is bounded by the number of
+// distinct groups rather than the number of rows. The first record is the
+// header unless cfg.NoHeader is set. The values match those of
+// Table.GroupBy on a table read with cfg, including its NumberFormat and
+// boolean words: groups appear in the order they are first seen, minimum and
+// maximum compare values as numbers in numeric columns and as strings
+// otherwise, and stddev is the sample standard deviation, computed with an
+// online algorithm, so its last digits may differ. Unlike GroupBy, which
+// sorts the aggregate columns by name, the aggregate columns follow the
+// order of aggs, and a column may be aggregated more than once.
+// count_distinct keeps each group's distinct values in memory. Aggregations
+// that need every value at once, such as a median, are not supported in
+// streaming mode.
+func StreamGroupBy(r io.Reader, cfg Config, groupCols []string, aggs []AggSpec) (*Table, error) {
+	cfg.ReuseRecord = true // group keys are copied out of each record
+	reader, err := NewReader(r, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := reader.ReadRecord()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read headers: %w", err)
+	}
+	first = append([]string(nil), first...)
+	header, err := tableHeaders(cfg, first)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[h] = i
+	}
+	numCols := len(header)
+
+	// Validate columns and aggregations
+	groupIndices := make([]int, len(groupCols))
+	for i, col := range groupCols {
+		idx, ok := index[col]
+		if !ok {
+			return nil, fmt.Errorf("group column %q not found", col)
+		}
+		groupIndices[i] = idx
+	}
+
+	headers := append([]string{}, groupCols...)
+	aggIndices := make([]int, len(aggs))
+	for i, spec := range aggs {
+		fn := strings.ToLower(spec.Func)
+		switch fn {
+		case "count", "sum", "avg", "minimum", "maximum", "stddev", "count_distinct", "first", "last":
+		default:
+			return nil, fmt.Errorf("unknown aggregation %q", spec.Func)
+		}
+
+		name := spec.As
+		if spec.Column == CountAll {
+			if fn != "count" {
+				return nil, fmt.Errorf("aggregation %q is not supported for %q, only count", spec.Func, CountAll)
+			}
+			aggIndices[i] = -1
+			if name == "" {
+				name = "count"
+			}
+		} else {
+			idx, ok := index[spec.Column]
+			if !ok {
+				return nil, fmt.Errorf("aggregation column %q not found", spec.Column)
+			}
+			aggIndices[i] = idx
+			if name == "" {
+				name = spec.Column
+			}
+		}
+		headers = append(headers, name)
+	}
+
+	type group struct {
+		key  []string
+		aggs []runningAgg
+	}
+	groups := make(map[string]*group)
+	var order []*group
+
+	// Column types are tracked as a Table would detect them, so minimum and
+	// maximum can compare like GroupBy once the whole column has been seen
+	bools := boolWords{cfg.BoolTrue, cfg.BoolFalse}
+	colTypes := make([]ColumnType, len(aggs))
+	for i := range colTypes {
+		colTypes[i] = TypeNull
+	}
+
+	keyParts := make([]string, len(groupIndices))
+	// Without a header row the first record is data
+	var pending []string
+	if cfg.NoHeader {
+		pending = first
+	}
+	for {
+		record := pending
+		if record != nil {
+			pending = nil
+		} else if record, err = reader.ReadRecord(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read record: %w", err)
+		}
+		record, keep, err := reader.fitRow(record, numCols)
+		if err != nil {
+			return nil, err
+		}
+		if !keep {
+			continue
+		}
+
+		for i, idx := range groupIndices {
+			keyParts[i] = record[idx]
+		}
+		groupKey := strings.Join(keyParts, "\x00")
+		g, ok := groups[groupKey]
+		if !ok {
+			g = &group{key: append([]string{}, keyParts...), aggs: make([]runningAgg, len(aggs))}
+			groups[groupKey] = g
+			order = append(order, g)
+		}
+
+		for i, spec := range aggs {
+			if aggIndices[i] < 0 {
+				g.aggs[i].count++
+				continue
+			}
+			colTypes[i] = widenType(colTypes[i], detectTypeWith(record[aggIndices[i]], cfg.NumberFormat, bools))
+			if err := g.aggs[i].add(record[aggIndices[i]], strings.ToLower(spec.Func), cfg.NumberFormat); err != nil {
+				return nil, fmt.Errorf("%s: aggregation error for %q: %w", reader.Position(), spec.Column, err)
+			}
+		}
+	}
+
+	result := NewTable(headers)
+	for _, g := range order {
+		row := append([]string{}, g.key...)
+		for i, spec := range aggs {
+			row = append(row, g.aggs[i].result(strings.ToLower(spec.Func), colTypes[i]))
+		}
+		if err := result.AddRow(row); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// aggNames are the descriptions used in number parse errors
+var aggNames = map[string]string{
+	"sum":    "sum",
+	"avg":    "average",
+	"stddev": "standard deviation",
+}
+
+// add folds one value, with numbers written in format nf, into the running
+// aggregate
+func (a *runningAgg) add(v, fn string, nf NumberFormat) error {
+	a.count++
+	switch fn {
+	case "minimum":
+		if a.count == 1 || v < a.min {
+			a.min = v
+		}
+		if f, err := nf.ParseFloat(v); err == nil && (a.minNum == "" || f < a.lo) {
+			a.minNum, a.lo = v, f
+		}
+	case "maximum":
+		if a.count == 1 || v > a.max {
+			a.max = v
+		}
+		if f, err := nf.ParseFloat(v); err == nil && (a.maxNum == "" || f > a.hi) {
+			a.maxNum, a.hi = v, f
+		}
+	case "count_distinct":
+		if DetectType(v) == TypeNull {
+			break
+		}
+		if a.distinct == nil {
+			a.distinct = make(map[string]struct{})
+		}
+		a.distinct[v] = struct{}{}
+	case "first":
+		if a.count == 1 {
+			a.first = v
+		}
+	case "last":
+		a.last = v
+	case "sum", "avg", "stddev":
+		f, err := nf.ParseFloat(v)
+		if err != nil {
+			return fmt.Errorf("invalid number %q for %s", v, aggNames[fn])
+		}
+		a.sum += f
+		delta := f - a.mean
+		a.mean += delta / float64(a.count)
+		a.m2 += delta * (f - a.mean)
+	}
+	return nil
+}
+
+// result formats the final value of the aggregate over a column of type
+// colType
+func (a *runningAgg) result(fn string, colType ColumnType) string {
+	numeric := colType == TypeInteger || colType == TypeFloat
+	switch fn {
+	case "count":
+		return strconv.FormatInt(a.count, 10)
+	case "sum":
+		return formatNumber(a.sum, FullPrecision)
+	case "avg":
+		if a.count == 0 {
+			return "0"
+		}
+		return formatNumber(a.sum/float64(a.count), FullPrecision)
+	case "minimum":
+		if numeric {
+			return a.minNum
+		}
+		return a.min
+	case "maximum":
+		if numeric {
+			return a.maxNum
+		}
+		return a.max
+	case "count_distinct":
+		return strconv.Itoa(len(a.distinct))
+	case "first":
+		return a.first
+	case "last":
+		return a.last
+	case "stddev":
+		if a.count < 2 {
+			return "0"
+		}
+		return formatNumber(math.Sqrt(a.m2/float64(a.count-1)), FullPrecision)
+	}
+	return ""
+}
+
+// CountRecords reads the CSV in r once and returns the number of data rows and
+// the number of fields in the first record, keeping only one record in
+// memory. The first record is the header and is not counted unless
+// cfg.NoHeader is set. Quoted line breaks do not end a record, so the count
+// can differ from the number of lines.
+func CountRecords(r io.Reader, cfg Config) (rows int64, fields int, err error) {
+	cfg.ReuseRecord = true // records are only counted
+	reader, err := NewReader(r, cfg)
+	if err != nil {
+		return 0, 0, err
+	}
+	for {
+		record, err := reader.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		if reader.CurrentRow() == 1 {
+			fields = len(record)
+			if !cfg.NoHeader {
+				continue
+			}
+		}
+		rows++
+	}
+	return rows, fields, nil
+}
+
+// ReservoirSample reads the CSV in r once and returns a uniformly random
+// sample of k rows, keeping no more than k rows in memory. The same seed
+// always gives the same sample. Rows keep their input order; inputs with k
+// rows or fewer are returned whole. The first record is the header unless
+// cfg.NoHeader is set.
+func ReservoirSample(r io.Reader, cfg Config, k int, seed int64) (*Table, error) {
+	if k < 0 {
+		return nil, fmt.Errorf("sample size must not be negative, got %d", k)
+	}
+	cfg.ReuseRecord = false // sampled records are retained
+	reader, err := NewReader(r, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := reader.ReadRecord()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read headers: %w", err)
+	}
+	header, err := tableHeaders(cfg, first)
+	if err != nil {
+		return nil, err
+	}
+	numCols := len(header)
+
+	type sampled struct {
+		seq    int
+		record []string
+	}
+	reservoir := make([]sampled, 0, k)
+	rng := rand.New(rand.NewSource(seed))
+
+	var pending []string
+	if cfg.NoHeader {
+		pending = first
+	}
+	seq := 0 // rows kept so far
+	for {
+		record := pending
+		if record != nil {
+			pending = nil
+		} else if record, err = reader.ReadRecord(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read record: %w", err)
+		}
+		record, keep, err := reader.fitRow(record, numCols)
+		if err != nil {
+			return nil, err
+		}
+		if !keep {
+			continue
+		}
+
+		// Algorithm R: the n-th row replaces a random slot with probability k/n
+		if len(reservoir) < k {
+			reservoir = append(reservoir, sampled{seq, record})
+		} else if j := rng.Intn(seq + 1); j < k {
+			reservoir[j] = sampled{seq, record}
+		}
+		seq++
+	}
+
+	sort.Slice(reservoir, func(i, j int) bool { return reservoir[i].seq < reservoir[j].seq })
+	table := NewTable(header)
+	table.setFormats(cfg)
+	for _, s := range reservoir {
+		if err := table.AddRow(s.record); err != nil {
+			return nil, err
+		}
+	}
+	return table, nil
+}
+
+// SelectOptions controls StreamSelect
+type SelectOptions struct {
+	Head    int      // Keep only the first Head data rows (0 for all)
+	Tail    int      // Then keep only the last Tail of those (0 for all)
+	Columns []string // Columns to keep, in this order (nil for all)
+}
+
+// StreamSelect reads the CSV in r and calls fn with the header, then with
+// each selected data row, reading no further than Head rows and buffering
+// at most Tail rows. With both Head and Tail set, the last Tail of the first
+// Head rows are selected. The first record is the header unless
+// cfg.NoHeader is set. Rows whose field count differs from the header's are
+// padded, truncated, skipped or rejected according to cfg, as ReadTable does,
+// or passed on as they are under RaggedKeep; skipped rows do not count towards
+// Head. Records passed to fn must not be
+// retained unless cfg.ReuseRecord is false and no columns are selected.
+func StreamSelect(r io.Reader, cfg Config, opts SelectOptions, fn func(record []string) error) error {
+	if opts.Head < 0 || opts.Tail < 0 {
+		return fmt.Errorf("head and tail must not be negative")
+	}
+	if opts.Tail > 0 {
+		cfg.ReuseRecord = false // buffered records are retained
+	}
+	reader, err := NewReader(r, cfg)
+	if err != nil {
+		return err
+	}
+
+	first, err := reader.ReadRecord()
+	if err != nil {
+		return fmt.Errorf("failed to read headers: %w", err)
+	}
+	first = append([]string(nil), first...)
+	header, err := tableHeaders(cfg, first)
+	if err != nil {
+		return err
+	}
+
+	var indices []int
+	selected := make([]string, len(opts.Columns))
+	for _, col := range opts.Columns {
+		// Like table lookups, the last column with a repeated name wins
+		idx, ok := -1, false
+		for i, h := range header {
+			if h == col {
+				idx, ok = i, true
+			}
+		}
+		if !ok {
+			return fmt.Errorf("column %q not found", col)
+		}
+		indices = append(indices, idx)
+	}
+	project := func(record []string) []string {
+		if indices == nil {
+			return record
+		}
+		for i, idx := range indices {
+			selected[i] = ""
+			if idx < len(record) {
+				selected[i] = record[idx]
+			}
+		}
+		return selected
+	}
+
+	if err := fn(project(header)); err != nil {
+		return err
+	}
+
+	var (
+		ring    [][]string
+		pending []string
+		rows    int
+	)
+	if cfg.NoHeader {
+		pending = first
+	}
+	for opts.Head == 0 || rows < opts.Head {
+		record := pending
+		if record != nil {
+			pending = nil
+		} else if record, err = reader.ReadRecord(); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to read record: %w", err)
+		}
+		if cfg.OnRagged != RaggedKeep {
+			var keep bool
+			if record, keep, err = reader.fitRow(record, len(header)); err != nil {
+				return err
+			}
+			if !keep {
+				continue
+			}
+		}
+		rows++
+
+		if opts.Tail == 0 {
+			if err := fn(project(record)); err != nil {
+				return err
+			}
+			continue
+		}
+		// Keep the last Tail records in a ring buffer
+		if len(ring) < opts.Tail {
+			ring = append(ring, record)
+		} else {
+			ring[(rows-1)%opts.Tail] = record
+		}
+	}
+
+	// Emit the buffered records oldest first
+	for i := range ring {
+		idx := i
+		if rows > opts.Tail {
+			idx = (rows + i) % opts.Tail
+		}
+		if err := fn(project(ring[idx])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadSelection reads the rows and columns selected by opts into a table,
+// like StreamSelect
+func ReadSelection(r io.Reader, cfg Config, opts SelectOptions) (*Table, error) {
+	var table *Table
+	err := StreamSelect(r, cfg, opts, func(record []string) error {
+		if table == nil {
+			table = NewTable(append([]string(nil), record...))
+			table.setFormats(cfg)
+			return nil
+		}
+		return table.AddRow(append([]string(nil), record...))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// TransformStream copies the CSV in r to w one record at a time, passing each
+// data row to transform along with the header. transform returns the row to
+// write, which may be the one it was given after modification, and whether
+// to keep it. The header is written first unless cfg.NoHeader is set, in
+// which case transform sees generated col1, col2, ... names. Rows are
+// written with a Writer using cfg, so memory use does not grow with the
+// input. Rows passed to transform must not be retained.
+func TransformStream(r io.Reader, w io.Writer, cfg Config, transform func(header []string, row []string) ([]string, bool)) error {
+	return TranscodeStream(r, w, cfg, cfg, transform)
+}
+
+// TranscodeStream is TransformStream with separate configs for reading r and
+// writing w, so the output can use another delimiter, quote character or line
+// terminator than the input, e.g. to turn semicolon-separated values into
+// comma-separated ones. A nil transform copies every row unchanged, and
+// writeCfg.SkipHeaderOnWrite leaves out the header. Rows whose field count
+// differs from the header's are fitted according to readCfg.OnRagged before
+// transform sees them, as StreamSelect does.
+func TranscodeStream(r io.Reader, w io.Writer, readCfg, writeCfg Config, transform func(header []string, row []string) ([]string, bool)) error {
+	writer, err := NewWriter(w, writeCfg)
+	if err != nil {
+		return err
+	}
+	cfg := readCfg
+	cfg.ReuseRecord = true // each row is written before the next is read
+	reader, err := NewReader(r, cfg)
+	if err != nil {
+		return err
+	}
+
+	first, err := reader.ReadRecord()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read headers: %w", err)
+	}
+	first = append([]string(nil), first...)
+	header, err := tableHeaders(cfg, first)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	if cfg.NoHeader {
+		pending = first
+	} else if !writeCfg.SkipHeaderOnWrite {
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+	}
+	for {
+		record := pending
+		if record != nil {
+			pending = nil
+		} else if record, err = reader.ReadRecord(); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to read record: %w", err)
+		}
+		if cfg.OnRagged != RaggedKeep {
+			var keep bool
+			if record, keep, err = reader.fitRow(record, len(header)); err != nil {
+				return err
+			}
+			if !keep {
+				continue
+			}
+		}
+
+		row, keep := record, true
+		if transform != nil {
+			row, keep = transform(header, record)
+		}
+		if !keep {
+			continue
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,332 @@
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// timeLayouts are tried in order when parsing a time.Time field without an
+// explicit layout
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"01/02/2006",
+	"2006/01/02",
+}
+
+// structField describes a struct field mapped to a table column
+type structField struct {
+	header string
+	index  []int
+	layout string // time layout from the tag, if any
+}
+
+// structFields returns the exported fields of struct type t, including the
+// fields of embedded structs; embedded struct pointers are skipped. Column
+// names come from the `csv:"name"` tag or the field name; a tag of "-" skips
+// the field. A tag may also carry a time layout for time.Time fields, e.g.
+// `csv:"born,layout=2006-01-02"`.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("csv")
+		if tag == "-" {
+			continue
+		}
+
+		// Flatten untagged embedded structs into the parent
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct && f.Type != timeType {
+			for _, inner := range structFields(f.Type) {
+				inner.index = append([]int{i}, inner.index...)
+				fields = append(fields, inner)
+			}
+			continue
+		}
+		if !f.IsExported() || (f.Anonymous && f.Type.Kind() == reflect.Ptr) {
+			continue
+		}
+
+		field := structField{header: f.Name, index: []int{i}}
+		parts := strings.Split(tag, ",")
+		if parts[0] != "" {
+			field.header = parts[0]
+		}
+		for _, opt := range parts[1:] {
+			if layout, ok := strings.CutPrefix(opt, "layout="); ok {
+				field.layout = layout
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// ToStructs fills out, which must be a pointer to a slice of structs (or of
+// struct pointers), with one element per row. Struct fields are matched to
+// columns by their `csv:"header"` tag or, failing that, their name (case
+// insensitively). Supported field types are strings, integers, floats,
+// booleans, time.Time and pointers to these; numbers are read in the
+// table's NumberFormat, booleans also as its boolean words, and null values
+// leave the field at its zero value. Fields without a matching column are left untouched.
+func (t *Table) ToStructs(out interface{}) error {
+	ptr := reflect.ValueOf(out)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("ToStructs requires a pointer to a slice, got %T", out)
+	}
+	sliceType := ptr.Elem().Type()
+	elemType := sliceType.Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("ToStructs requires a slice of structs, got %s", sliceType)
+	}
+
+	// Map struct fields to column indices
+	type mapping struct {
+		field structField
+		col   int
+	}
+	var mappings []mapping
+	for _, f := range structFields(elemType) {
+		if col, ok := t.columnIndexFold(f.header); ok {
+			mappings = append(mappings, mapping{f, col})
+		}
+	}
+
+	result := reflect.MakeSlice(sliceType, 0, len(t.Rows))
+	for i, row := range t.Rows {
+		elem := reflect.New(elemType).Elem()
+		for _, m := range mappings {
+			if err := t.setField(elem.FieldByIndex(m.field.index), row[m.col], m.field.layout); err != nil {
+				return fmt.Errorf("row %d, column %q: %w", i+1, t.Headers[m.col], err)
+			}
+		}
+		if isPtr {
+			elem = elem.Addr()
+		}
+		result = reflect.Append(result, elem)
+	}
+
+	ptr.Elem().Set(result)
+	return nil
+}
+
+// columnIndexFold finds a column by exact name, then case-insensitively
+func (t *Table) columnIndexFold(name string) (int, bool) {
+	if idx, ok := t.index[name]; ok {
+		return idx, true
+	}
+	for i, h := range t.Headers {
+		if strings.EqualFold(h, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// setField parses s into v according to v's type
+func (t *Table) setField(v reflect.Value, s, layout string) error {
+	isNull := DetectType(s) == TypeNull
+
+	if v.Kind() == reflect.Ptr {
+		if isNull {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		p := reflect.New(v.Type().Elem())
+		if err := t.setField(p.Elem(), s, layout); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+
+	if v.Kind() == reflect.String {
+		v.SetString(s)
+		return nil
+	}
+	if isNull {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if v.Type() == timeType {
+		tm, err := parseTime(s, layout)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(tm))
+		return nil
+	}
+
+	// Numbers are parsed from their plain form
+	num := s
+	if n, ok := t.numbers.Normalize(s); ok {
+		num = n
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(num, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as %s", s, v.Type())
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(num, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as %s", s, v.Type())
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(num, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as %s", s, v.Type())
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		if b, ok := t.bools.parse(s); ok {
+			v.SetBool(b)
+			break
+		}
+		b, err := strconv.ParseBool(strings.ToLower(s))
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as bool", s)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+// parseTime parses s with layout, or with each of timeLayouts if layout is empty
+func parseTime(s, layout string) (time.Time, error) {
+	if layout != "" {
+		tm, err := time.Parse(layout, s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cannot parse %q as time with layout %q", s, layout)
+		}
+		return tm, nil
+	}
+	for _, l := range timeLayouts {
+		if tm, err := time.Parse(l, s); err == nil {
+			return tm, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as time", s)
+}
+
+// detectTimeLayout returns the first of timeLayouts that parses s
+func detectTimeLayout(s string) (string, bool) {
+	for _, l := range timeLayouts {
+		if _, err := time.Parse(l, s); err == nil {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// FromStructs builds a table from a slice of structs (or struct pointers),
+// or a pointer to one. Headers come from the `csv:"header"` tags or field
+// names, in field order, with embedded struct fields flattened in place.
+// Unexported fields are skipped. time.Time values are formatted with the
+// tag's layout option, defaulting to RFC 3339; zero times, nil pointers and
+// nil elements become empty values.
+func FromStructs(v interface{}) (*Table, error) {
+	val := reflect.ValueOf(v)
+	if val.Kind() == reflect.Ptr && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return nil, fmt.Errorf("FromStructs requires a slice of structs, got %T", v)
+	}
+	elemType := val.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("FromStructs requires a slice of structs, got %T", v)
+	}
+
+	fields := structFields(elemType)
+	headers := make([]string, len(fields))
+	for i, f := range fields {
+		headers[i] = f.header
+	}
+
+	table := NewTable(headers)
+	for i := 0; i < val.Len(); i++ {
+		elem := val.Index(i)
+		row := make([]string, len(fields))
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				if err := table.AddRow(row); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			elem = elem.Elem()
+		}
+		for j, f := range fields {
+			s, err := formatField(elem.FieldByIndex(f.index), f.layout)
+			if err != nil {
+				return nil, fmt.Errorf("element %d, field %q: %w", i, f.header, err)
+			}
+			row[j] = s
+		}
+		if err := table.AddRow(row); err != nil {
+			return nil, err
+		}
+	}
+	return table, nil
+}
+
+// formatField converts a struct field to its table representation
+func formatField(v reflect.Value, layout string) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		return formatField(v.Elem(), layout)
+	}
+
+	if v.Type() == timeType {
+		tm := v.Interface().(time.Time)
+		if tm.IsZero() {
+			return "", nil
+		}
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		return tm.Format(layout), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+	return "", fmt.Errorf("unsupported field type %s", v.Type())
+}
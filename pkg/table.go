@@ -1,13 +1,18 @@
 package pkg
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"io"
+	"iter"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
-	"text/template"
+	"sync"
+	"unsafe"
 )
 
 // Table represents a data table with headers and rows
@@ -16,6 +21,12 @@ type Table struct {
 	Rows    [][]string
 	types   []ColumnType
 	index   map[string]int // Header to column index mapping
+	numbers NumberFormat   // how numbers are written, for type detection and statistics
+	bools   boolWords      // extra words read as true and false
+
+	widths    []int      // cached width of the widest cell per column, nil when stale
+	widthRows int        // number of rows widths was computed from
+	widthMu   sync.Mutex // lets concurrent readers such as Format share the cache
 }
 
 // ColumnType represents the detected type of a column
@@ -29,20 +40,48 @@ const (
 	TypeNull
 )
 
+// String returns the type's name, e.g. "integer", as used in compact JSON
+func (ct ColumnType) String() string {
+	if name, ok := columnTypeNames[ct]; ok {
+		return name
+	}
+	return "ColumnType(" + strconv.Itoa(int(ct)) + ")"
+}
+
 // NewTable creates a new table with the given headers
 func NewTable(headers []string) *Table {
 	index := make(map[string]int, len(headers))
 	for i, h := range headers {
 		index[h] = i
 	}
+	// Columns start as null until a value is seen
+	types := make([]ColumnType, len(headers))
+	for i := range types {
+		types[i] = TypeNull
+	}
 	return &Table{
 		Headers: headers,
 		Rows:    make([][]string, 0),
-		types:   make([]ColumnType, len(headers)),
+		types:   types,
 		index:   index,
 	}
 }
 
+// HasDuplicateHeaders returns the header names that appear more than once,
+// in the order they are first repeated. Only the last column with a repeated name
+// can be looked up by name.
+func (t *Table) HasDuplicateHeaders() []string {
+	counts := make(map[string]int, len(t.Headers))
+	var dups []string
+	for _, h := range t.Headers {
+		counts[h]++
+		if counts[h] == 2 {
+			dups = append(dups, h)
+		}
+	}
+	return dups
+}
+
 // AddRow adds a row to the table
 func (t *Table) AddRow(row []string) error {
 	if len(row) != len(t.Headers) {
@@ -50,21 +89,58 @@ func (t *Table) AddRow(row []string) error {
 	}
 	t.Rows = append(t.Rows, row)
 	t.updateTypes(row)
+	t.updateWidths(row)
+	return nil
+}
+
+// AppendRows adds several rows to the table. Every row's length is checked
+// before any is added, so on error the table is unchanged. Types are detected
+// column by column, skipping the rest of a column once it has become a string
+// column, which makes this faster than calling AddRow for each row.
+func (t *Table) AppendRows(rows [][]string) error {
+	for i, row := range rows {
+		if len(row) != len(t.Headers) {
+			return fmt.Errorf("row %d: row length %d does not match headers length %d", i+1, len(row), len(t.Headers))
+		}
+	}
+	t.Rows = append(t.Rows, rows...)
+	for col, colType := range t.types {
+		for _, row := range rows {
+			if colType == TypeString {
+				// No value can change a string column's type
+				break
+			}
+			colType = widenType(colType, t.detectType(row[col]))
+		}
+		t.types[col] = colType
+	}
+	t.updateWidths(rows...)
 	return nil
 }
 
 // updateTypes updates the detected types for each column based on the new row
 func (t *Table) updateTypes(row []string) {
 	for i, val := range row {
-		if t.types[i] == TypeNull {
-			t.types[i] = DetectType(val)
-			continue
-		}
-		newType := DetectType(val)
-		if newType != t.types[i] {
-			// If types conflict, fall back to string
-			t.types[i] = TypeString
-		}
+		t.types[i] = widenType(t.types[i], t.detectType(val))
+	}
+}
+
+// widenType returns the type of a column of type cur after a value of type
+// newType is added
+func widenType(cur, newType ColumnType) ColumnType {
+	switch {
+	case newType == TypeNull || newType == cur:
+		// Nulls never change a column's type
+		return cur
+	case cur == TypeNull:
+		return newType
+	case (cur == TypeInteger && newType == TypeFloat) ||
+		(cur == TypeFloat && newType == TypeInteger):
+		// Mixed integers and floats widen to float
+		return TypeFloat
+	default:
+		// If types conflict, fall back to string
+		return TypeString
 	}
 }
 
@@ -85,12 +161,242 @@ func DetectType(val string) ColumnType {
 	return TypeString
 }
 
+// SetNumberFormat sets how numbers are written in the table, e.g. with
+// thousands separators or currency symbols, and detects the column types
+// again. Type detection and statistics such as Summarize, GroupBy sums and
+// GetColumnFloats then recognize numbers in that format; the cells
+// themselves are not changed. Tables read with a Config take its
+// NumberFormat.
+func (t *Table) SetNumberFormat(nf NumberFormat) {
+	t.numbers = nf
+	t.retype()
+}
+
+// SetBoolWords sets the words read as true and false besides "true" and
+// "false", matched case-insensitively, and detects the column types again.
+// Tables read with a Config take its BoolTrue and BoolFalse.
+func (t *Table) SetBoolWords(trueWords, falseWords []string) {
+	t.bools = boolWords{trueWords, falseWords}
+	t.retype()
+}
+
+// setFormats sets how numbers and booleans are written from cfg
+func (t *Table) setFormats(cfg Config) {
+	t.numbers = cfg.NumberFormat
+	t.bools = boolWords{cfg.BoolTrue, cfg.BoolFalse}
+}
+
+// detectType is DetectType using the table's number format and boolean words
+func (t *Table) detectType(val string) ColumnType {
+	return detectTypeWith(val, t.numbers, t.bools)
+}
+
+// detectTypeWith is DetectType using number format nf and boolean words bools
+func detectTypeWith(val string, nf NumberFormat, bools boolWords) ColumnType {
+	if _, ok := bools.parse(val); ok {
+		return TypeBoolean
+	}
+	return nf.DetectType(val)
+}
+
+// boolWords holds the words read as true and false besides "true" and
+// "false"
+type boolWords struct {
+	trueWords, falseWords []string
+}
+
+// parse returns the boolean s stands for, and whether it is "true", "false"
+// or one of the words, ignoring case
+func (b boolWords) parse(s string) (value, ok bool) {
+	switch {
+	case strings.EqualFold(s, "true"):
+		return true, true
+	case strings.EqualFold(s, "false"):
+		return false, true
+	case s == "":
+		return false, false
+	}
+	for _, w := range b.trueWords {
+		if strings.EqualFold(s, w) {
+			return true, true
+		}
+	}
+	for _, w := range b.falseWords {
+		if strings.EqualFold(s, w) {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// ColumnNames returns a copy of the table's headers
+func (t *Table) ColumnNames() []string {
+	return append([]string(nil), t.Headers...)
+}
+
+// ColumnIndex returns the position of the named column
+func (t *Table) ColumnIndex(name string) (int, bool) {
+	idx, ok := t.index[name]
+	return idx, ok
+}
+
+// RenameColumn changes a column's header, keeping its position and type
+func (t *Table) RenameColumn(oldName, newName string) error {
+	idx, ok := t.index[oldName]
+	if !ok {
+		return fmt.Errorf("column %q not found", oldName)
+	}
+	if oldName == newName {
+		return nil
+	}
+	if _, exists := t.index[newName]; exists {
+		return fmt.Errorf("column %q already exists", newName)
+	}
+	// Tables derived from t, such as by Filter, may share its Headers
+	t.Headers = append([]string(nil), t.Headers...)
+	t.Headers[idx] = newName
+	delete(t.index, oldName)
+	t.index[newName] = idx
+	return nil
+}
+
+// ReorderColumns rearranges the columns into the given order, which must
+// name every column exactly once
+func (t *Table) ReorderColumns(order []string) error {
+	if dups := t.HasDuplicateHeaders(); len(dups) > 0 {
+		return fmt.Errorf("cannot reorder columns with duplicate headers %q", dups)
+	}
+	if len(order) != len(t.Headers) {
+		return fmt.Errorf("column order has %d columns, table has %d", len(order), len(t.Headers))
+	}
+	perm := make([]int, len(order))
+	used := make(map[string]bool, len(order))
+	for i, name := range order {
+		idx, ok := t.index[name]
+		if !ok {
+			return fmt.Errorf("column %q not found", name)
+		}
+		if used[name] {
+			return fmt.Errorf("column %q listed more than once", name)
+		}
+		used[name] = true
+		perm[i] = idx
+	}
+
+	headers := make([]string, len(perm))
+	types := make([]ColumnType, len(perm))
+	for i, idx := range perm {
+		headers[i] = t.Headers[idx]
+		types[i] = t.types[idx]
+	}
+	for r, row := range t.Rows {
+		newRow := make([]string, len(perm))
+		for i, idx := range perm {
+			newRow[i] = row[idx]
+		}
+		t.Rows[r] = newRow
+	}
+	t.Headers = headers
+	t.types = types
+	t.widths = nil
+	for i, h := range headers {
+		t.index[h] = i
+	}
+	return nil
+}
+
+// AddRowNumberColumn inserts a column named name before the others holding
+// each row's number, counting from start, e.g. 1 for 1-based numbering.
+// Unlike FormatOptions.NumberedRows the numbers are data, so they are kept
+// by exports and by Sort, which reorders them with their rows.
+func (t *Table) AddRowNumberColumn(name string, start int) error {
+	if _, exists := t.index[name]; exists {
+		return fmt.Errorf("column %q already exists", name)
+	}
+	for r, row := range t.Rows {
+		// Rows may be shared with other tables, so each gets a new slice
+		newRow := make([]string, 0, len(row)+1)
+		newRow = append(newRow, strconv.Itoa(start+r))
+		t.Rows[r] = append(newRow, row...)
+	}
+	t.Headers = append([]string{name}, t.Headers...)
+	colType := TypeNull
+	if len(t.Rows) > 0 {
+		colType = TypeInteger
+	}
+	t.types = append([]ColumnType{colType}, t.types...)
+	for i, h := range t.Headers {
+		t.index[h] = i
+	}
+	t.widths = nil
+	return nil
+}
+
 // GetColumn returns all values in a column by header name
 func (t *Table) GetColumn(header string) ([]string, error) {
 	idx, ok := t.index[header]
 	if !ok {
 		return nil, fmt.Errorf("column %q not found", header)
 	}
+	return t.GetColumnAt(idx)
+}
+
+// Rows2 iterates over the rows, yielding each row's index and a map from
+// header to value, e.g. for i, row := range t.Rows2() { ... row["name"] }.
+// Each row gets a new map, which the caller may keep or modify. With
+// repeated headers the last column of a name wins.
+func (t *Table) Rows2() iter.Seq2[int, map[string]string] {
+	return func(yield func(int, map[string]string) bool) {
+		for i, row := range t.Rows {
+			m := make(map[string]string, len(t.Headers))
+			for j, h := range t.Headers {
+				m[h] = row[j]
+			}
+			if !yield(i, m) {
+				return
+			}
+		}
+	}
+}
+
+// IndexBy returns a map from each value of keyCol to the first row with that
+// value, for repeated lookups such as enriching rows from a reference table
+// without scanning it each time. Later rows with the same key are ignored;
+// IndexAllBy keeps them. The rows are t's own, not copies.
+func (t *Table) IndexBy(keyCol string) (map[string][]string, error) {
+	idx, ok := t.index[keyCol]
+	if !ok {
+		return nil, fmt.Errorf("column %q not found", keyCol)
+	}
+	index := make(map[string][]string, len(t.Rows))
+	for _, row := range t.Rows {
+		if _, seen := index[row[idx]]; !seen {
+			index[row[idx]] = row
+		}
+	}
+	return index, nil
+}
+
+// IndexAllBy is like IndexBy, but maps each value of keyCol to every row
+// with that value, in table order
+func (t *Table) IndexAllBy(keyCol string) (map[string][][]string, error) {
+	idx, ok := t.index[keyCol]
+	if !ok {
+		return nil, fmt.Errorf("column %q not found", keyCol)
+	}
+	index := make(map[string][][]string)
+	for _, row := range t.Rows {
+		index[row[idx]] = append(index[row[idx]], row)
+	}
+	return index, nil
+}
+
+// GetColumnAt returns all values in the column at position idx (0-based),
+// which works even when headers repeat
+func (t *Table) GetColumnAt(idx int) ([]string, error) {
+	if idx < 0 || idx >= len(t.Headers) {
+		return nil, fmt.Errorf("column index %d out of range [0, %d)", idx, len(t.Headers))
+	}
 	col := make([]string, len(t.Rows))
 	for i, row := range t.Rows {
 		col[i] = row[idx]
@@ -98,6 +404,14 @@ func (t *Table) GetColumn(header string) ([]string, error) {
 	return col, nil
 }
 
+// HeaderAt returns the header of the column at position idx (0-based)
+func (t *Table) HeaderAt(idx int) (string, error) {
+	if idx < 0 || idx >= len(t.Headers) {
+		return "", fmt.Errorf("column index %d out of range [0, %d)", idx, len(t.Headers))
+	}
+	return t.Headers[idx], nil
+}
+
 // GetColumnType returns the detected type of a column
 func (t *Table) GetColumnType(header string) (ColumnType, error) {
 	idx, ok := t.index[header]
@@ -110,19 +424,115 @@ func (t *Table) GetColumnType(header string) (ColumnType, error) {
 // Filter returns a new table containing only rows that match the predicate
 func (t *Table) Filter(predicate func(row []string) bool) *Table {
 	newTable := NewTable(t.Headers)
+	newTable.numbers, newTable.bools = t.numbers, t.bools
 	for _, row := range t.Rows {
 		if predicate(row) {
-			err := newTable.AddRow(row)
-			if err != nil {
-				return nil
-			}
+			// Rows already match the headers, so they can be added directly
+			newTable.Rows = append(newTable.Rows, row)
+			newTable.updateTypes(row)
 		}
 	}
 	return newTable
 }
 
+// DeleteWhere removes the rows that match the predicate in place, keeping
+// the order of the rest, and returns how many were removed. Column types are
+// detected again from the remaining rows.
+func (t *Table) DeleteWhere(predicate func(row []string) bool) int {
+	kept := t.Rows[:0]
+	for _, row := range t.Rows {
+		if !predicate(row) {
+			kept = append(kept, row)
+		}
+	}
+	removed := len(t.Rows) - len(kept)
+	// Clear the tail so removed rows can be garbage collected
+	clear(t.Rows[len(kept):])
+	t.Rows = kept
+	t.retype()
+	return removed
+}
+
+// Recode replaces each value in column with its entry in mapping, such as
+// "M" to "Male". Values missing from mapping become defaultVal, or are left
+// unchanged if defaultVal is empty. The column's type is detected again
+// afterwards.
+func (t *Table) Recode(column string, mapping map[string]string, defaultVal string) error {
+	idx, ok := t.index[column]
+	if !ok {
+		return fmt.Errorf("column %q not found", column)
+	}
+	colType := TypeNull
+	for r, row := range t.Rows {
+		v, ok := mapping[row[idx]]
+		if !ok && defaultVal != "" {
+			v, ok = defaultVal, true
+		}
+		if ok && v != row[idx] {
+			t.setCell(r, idx, v)
+		}
+		colType = widenType(colType, t.detectType(t.Rows[r][idx]))
+	}
+	t.types[idx] = colType
+	t.widths = nil
+	return nil
+}
+
+// Clip caps the numbers in column to the range [min, max], e.g. to
+// limit outliers: smaller numbers become min and larger ones max, written
+// as plain numbers. Numbers in range and cells that are not numbers are left
+// as they are. The column's type is detected again afterwards, since a
+// fractional bound turns an integer column into a float one.
+func (t *Table) Clip(column string, min, max float64) error {
+	idx, ok := t.index[column]
+	if !ok {
+		return fmt.Errorf("column %q not found", column)
+	}
+	if math.IsNaN(min) || math.IsNaN(max) || min > max {
+		return fmt.Errorf("invalid clip range [%v, %v]", min, max)
+	}
+	lo, hi := formatNumber(min, FullPrecision), formatNumber(max, FullPrecision)
+	colType := TypeNull
+	for r, row := range t.Rows {
+		if f, err := t.numbers.ParseFloat(row[idx]); err == nil && (f < min || f > max) {
+			if f < min {
+				t.setCell(r, idx, lo)
+			} else {
+				t.setCell(r, idx, hi)
+			}
+		}
+		colType = widenType(colType, t.detectType(t.Rows[r][idx]))
+	}
+	t.types[idx] = colType
+	t.widths = nil
+	return nil
+}
+
+// setCell sets the cell at row r and column col to v. Rows may be shared with
+// other tables, such as the source of a Filter, so the row is copied and the
+// copy swapped in rather than edited in place.
+func (t *Table) setCell(r, col int, v string) {
+	row := append([]string(nil), t.Rows[r]...)
+	row[col] = v
+	t.Rows[r] = row
+}
+
+// retype detects the column types again from every row and drops the cached
+// widths, after rows were changed or removed
+func (t *Table) retype() {
+	for i := range t.types {
+		t.types[i] = TypeNull
+	}
+	for _, row := range t.Rows {
+		t.updateTypes(row)
+	}
+	t.widths = nil
+}
+
 // Sort sorts the table by the specified columns
 // columns should be in the format: ["name:asc", "age:desc"]
+// The sort is stable: rows that compare equal on every key, in either
+// direction, keep their original relative order.
 func (t *Table) Sort(columns []string) error {
 	type sortKey struct {
 		col  string
@@ -152,10 +562,10 @@ func (t *Table) Sort(columns []string) error {
 	}
 
 	// Sort rows
-	sort.SliceStable(t.Rows, func(i, j int) bool {
+	t.SortByFunc(func(rowA, rowB []string) bool {
 		for k, key := range keys {
 			idx := indices[k]
-			a, b := t.Rows[i][idx], t.Rows[j][idx]
+			a, b := rowA[idx], rowB[idx]
 			if a == b {
 				continue
 			}
@@ -171,8 +581,55 @@ func (t *Table) Sort(columns []string) error {
 	return nil
 }
 
-// GroupBy groups rows by the specified columns and applies aggregations
+// SortByFunc sorts the rows with a custom comparator, for orders Sort cannot
+// express, such as by a parsed date or a value computed from several
+// columns. less reports whether row a belongs before row b. The sort is
+// stable, so rows for which neither is less keep their relative order.
+func (t *Table) SortByFunc(less func(a, b []string) bool) {
+	sort.SliceStable(t.Rows, func(i, j int) bool {
+		return less(t.Rows[i], t.Rows[j])
+	})
+}
+
+// CountAll is the aggregation column that counts the rows in each group
+// regardless of their values, e.g. GroupBy([]string{"dept"}, map[string]string{"*": "count"}).
+// Its result column is named "count", or "count_2", "count_3" and so on if
+// a group or aggregation column is already named "count".
+const CountAll = "*"
+
+// countHeader returns the name of the CountAll result column in a table that
+// also has the columns in taken, suffixed like DuplicateHeadersRename does
+// if "count" is taken
+func countHeader(taken []string) string {
+	seen := make(map[string]bool, len(taken))
+	for _, h := range taken {
+		seen[h] = true
+	}
+	name := "count"
+	for n := 2; seen[name]; n++ {
+		name = fmt.Sprintf("count_%d", n)
+	}
+	return name
+}
+
+// FullPrecision formats aggregation results with the fewest digits that
+// represent them exactly
+const FullPrecision = -1
+
+// GroupBy groups rows by the specified columns and applies aggregations:
+// count, sum, avg, minimum, maximum, stddev, count_distinct (the number of
+// distinct non-null values), and first and last (the group's first and last
+// value in row order, so they depend on how the table is sorted).
+// Groups appear in the order they are first seen and aggregation columns
+// are sorted by name. Numeric results are formatted with FullPrecision.
 func (t *Table) GroupBy(groupCols []string, aggs map[string]string) (*Table, error) {
+	return t.GroupByPrecision(groupCols, aggs, FullPrecision)
+}
+
+// GroupByPrecision is like GroupBy but formats the results of sum, avg and
+// stddev with precision decimal places, e.g. an average of 1000 and 2000 is
+// "1500.00" with a precision of 2.
+func (t *Table) GroupByPrecision(groupCols []string, aggs map[string]string, precision int) (*Table, error) {
 	// Validate group columns
 	groupIndices := make([]int, len(groupCols))
 	for i, col := range groupCols {
@@ -183,37 +640,72 @@ func (t *Table) GroupBy(groupCols []string, aggs map[string]string) (*Table, err
 		groupIndices[i] = idx
 	}
 
+	// Validate aggregation columns, fixing their order so headers and
+	// values line up
+	aggCols := make([]string, 0, len(aggs))
+	for col := range aggs {
+		aggCols = append(aggCols, col)
+	}
+	sort.Strings(aggCols)
+
+	aggIndices := make([]int, len(aggCols))
+	for i, col := range aggCols {
+		if col == CountAll {
+			if !strings.EqualFold(aggs[col], "count") {
+				return nil, fmt.Errorf("aggregation %q is not supported for %q, only count", aggs[col], CountAll)
+			}
+			aggIndices[i] = -1
+			continue
+		}
+		idx, ok := t.index[col]
+		if !ok {
+			return nil, fmt.Errorf("aggregation column %q not found", col)
+		}
+		aggIndices[i] = idx
+	}
+
 	// Create result headers
 	headers := make([]string, 0, len(groupCols)+len(aggs))
 	headers = append(headers, groupCols...)
-	for col := range aggs {
+	countCol := countHeader(append(append([]string(nil), groupCols...), aggCols...))
+	for _, col := range aggCols {
+		if col == CountAll {
+			col = countCol
+		}
 		headers = append(headers, col)
 	}
 
 	// Group rows
 	groups := make(map[string][][]string)
+	var order []string
 	for _, row := range t.Rows {
 		key := make([]string, len(groupIndices))
 		for i, idx := range groupIndices {
 			key[i] = row[idx]
 		}
 		groupKey := strings.Join(key, "\x00")
+		if _, ok := groups[groupKey]; !ok {
+			order = append(order, groupKey)
+		}
 		groups[groupKey] = append(groups[groupKey], row)
 	}
 
 	// Apply aggregations
 	result := NewTable(headers)
-	for groupKey, rows := range groups {
+	for _, groupKey := range order {
+		rows := groups[groupKey]
 		groupVals := strings.Split(groupKey, "\x00")
 		newRow := make([]string, len(headers))
 		copy(newRow, groupVals)
 
 		// Calculate aggregations
 		i := len(groupVals)
-		for col, agg := range aggs {
-			idx, ok := t.index[col]
-			if !ok {
-				return nil, fmt.Errorf("aggregation column %q not found", col)
+		for k, col := range aggCols {
+			idx := aggIndices[k]
+			if idx < 0 {
+				newRow[i] = strconv.Itoa(len(rows))
+				i++
+				continue
 			}
 
 			vals := make([]string, len(rows))
@@ -221,7 +713,7 @@ func (t *Table) GroupBy(groupCols []string, aggs map[string]string) (*Table, err
 				vals[j] = row[idx]
 			}
 
-			aggVal, err := aggregate(vals, agg)
+			aggVal, err := aggregate(vals, aggs[col], precision, t.types[idx], t.numbers)
 			if err != nil {
 				return nil, fmt.Errorf("aggregation error for %q: %w", col, err)
 			}
@@ -238,39 +730,66 @@ func (t *Table) GroupBy(groupCols []string, aggs map[string]string) (*Table, err
 	return result, nil
 }
 
-// aggregate performs the specified aggregation on values
-func aggregate(vals []string, agg string) (string, error) {
+// aggregate performs the specified aggregation on values, formatting
+// numeric results with precision decimal places. minimum and maximum compare
+// the values as numbers if colType is numeric, skipping nulls, and as
+// strings otherwise. Numbers are parsed in format nf.
+func aggregate(vals []string, agg string, precision int, colType ColumnType, nf NumberFormat) (string, error) {
 	switch strings.ToLower(agg) {
 	case "count":
 		return strconv.Itoa(len(vals)), nil
 
+	case "count_distinct":
+		distinct := make(map[string]bool)
+		for _, v := range vals {
+			if DetectType(v) != TypeNull {
+				distinct[v] = true
+			}
+		}
+		return strconv.Itoa(len(distinct)), nil
+
+	case "first":
+		if len(vals) == 0 {
+			return "", nil
+		}
+		return vals[0], nil
+
+	case "last":
+		if len(vals) == 0 {
+			return "", nil
+		}
+		return vals[len(vals)-1], nil
+
 	case "sum":
 		var sum float64
 		for _, v := range vals {
-			f, err := strconv.ParseFloat(v, 64)
+			f, err := nf.ParseFloat(v)
 			if err != nil {
 				return "", fmt.Errorf("invalid number %q for sum", v)
 			}
 			sum += f
 		}
-		return strconv.FormatFloat(sum, 'f', -1, 64), nil
+		return formatNumber(sum, precision), nil
 
 	case "avg":
 		if len(vals) == 0 {
-			return "0", nil
+			return formatNumber(0, precision), nil
 		}
 		var sum float64
 		for _, v := range vals {
-			f, err := strconv.ParseFloat(v, 64)
+			f, err := nf.ParseFloat(v)
 			if err != nil {
 				return "", fmt.Errorf("invalid number %q for average", v)
 			}
 			sum += f
 		}
 		avg := sum / float64(len(vals))
-		return strconv.FormatFloat(avg, 'f', -1, 64), nil
+		return formatNumber(avg, precision), nil
 
 	case "minimum":
+		if colType == TypeInteger || colType == TypeFloat {
+			return numericExtreme(vals, false, nf), nil
+		}
 		if len(vals) == 0 {
 			return "", nil
 		}
@@ -283,6 +802,9 @@ func aggregate(vals []string, agg string) (string, error) {
 		return minValue, nil
 
 	case "maximum":
+		if colType == TypeInteger || colType == TypeFloat {
+			return numericExtreme(vals, true, nf), nil
+		}
 		if len(vals) == 0 {
 			return "", nil
 		}
@@ -294,11 +816,68 @@ func aggregate(vals []string, agg string) (string, error) {
 		}
 		return maximum, nil
 
+	case "stddev":
+		// Sample standard deviation
+		nums := make([]float64, len(vals))
+		var sum float64
+		for i, v := range vals {
+			f, err := nf.ParseFloat(v)
+			if err != nil {
+				return "", fmt.Errorf("invalid number %q for standard deviation", v)
+			}
+			nums[i] = f
+			sum += f
+		}
+		if len(nums) < 2 {
+			return formatNumber(0, precision), nil
+		}
+		mean := sum / float64(len(nums))
+		var sq float64
+		for _, f := range nums {
+			sq += (f - mean) * (f - mean)
+		}
+		return formatNumber(math.Sqrt(sq/float64(len(nums)-1)), precision), nil
+
 	default:
 		return "", fmt.Errorf("unknown aggregation %q", agg)
 	}
 }
 
+// numericExtreme returns the value in vals holding the smallest number, or
+// the largest if largest is set, as written. Values that are not numbers,
+// such as nulls, are skipped; with no numbers the result is empty.
+func numericExtreme(vals []string, largest bool, nf NumberFormat) string {
+	result, best := "", 0.0
+	for _, v := range vals {
+		f, err := nf.ParseFloat(v)
+		if err != nil {
+			continue
+		}
+		if result == "" || (largest && f > best) || (!largest && f < best) {
+			result, best = v, f
+		}
+	}
+	return result
+}
+
+// formatNumber formats f with precision decimal places, or with as many as
+// needed for FullPrecision. Values that are not finite are written as "NaN",
+// "Inf" and "-Inf".
+func formatNumber(f float64, precision int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	if precision < 0 {
+		precision = -1
+	}
+	return strconv.FormatFloat(f, 'f', precision, 64)
+}
+
 // String returns a string representation of the table
 func (t *Table) String() string {
 	if len(t.Headers) == 0 {
@@ -363,6 +942,7 @@ func (t *Table) String() string {
 func (t *Table) Copy() *Table {
 	newTable := NewTable(append([]string{}, t.Headers...))
 	newTable.types = append([]ColumnType{}, t.types...)
+	newTable.numbers, newTable.bools = t.numbers, t.bools
 	for k, v := range t.index {
 		newTable.index[k] = v
 	}
@@ -373,6 +953,120 @@ func (t *Table) Copy() *Table {
 	return newTable
 }
 
+// Transpose returns a new table whose rows are the columns of t. When the
+// first column holds unique, non-empty values they become the new headers,
+// under the first column's name, and every other column becomes a row led by
+// its name. Otherwise the headers are "column", "row1", "row2", ... and every
+// column, including the first, becomes a row. Intended for small tables such
+// as summaries.
+func (t *Table) Transpose() *Table {
+	if len(t.Headers) == 0 {
+		return NewTable([]string{})
+	}
+
+	useFirst := true
+	seen := make(map[string]bool, len(t.Rows)+1)
+	seen[t.Headers[0]] = true
+	for _, row := range t.Rows {
+		if row[0] == "" || seen[row[0]] {
+			useFirst = false
+			break
+		}
+		seen[row[0]] = true
+	}
+
+	headers := make([]string, 0, len(t.Rows)+1)
+	start := 0
+	if useFirst {
+		headers = append(headers, t.Headers[0])
+		for _, row := range t.Rows {
+			headers = append(headers, row[0])
+		}
+		start = 1
+	} else {
+		headers = append(headers, "column")
+		for i := range t.Rows {
+			headers = append(headers, "row"+strconv.Itoa(i+1))
+		}
+	}
+
+	result := NewTable(headers)
+	for col := start; col < len(t.Headers); col++ {
+		newRow := make([]string, 0, len(headers))
+		newRow = append(newRow, t.Headers[col])
+		for _, row := range t.Rows {
+			newRow = append(newRow, row[col])
+		}
+		result.AddRow(newRow)
+	}
+	return result
+}
+
+// Equal reports whether t and other have the same headers, rows and column
+// types
+func (t *Table) Equal(other *Table) bool {
+	return t.equal(other, true)
+}
+
+// EqualValues reports whether t and other have the same headers and rows,
+// whatever types their columns were detected as, e.g. when one was read with
+// a NumberFormat and the other without
+func (t *Table) EqualValues(other *Table) bool {
+	return t.equal(other, false)
+}
+
+// equal compares t and other for Equal and EqualValues
+func (t *Table) equal(other *Table, compareTypes bool) bool {
+	if t == other {
+		return true
+	}
+	if t == nil || other == nil {
+		return false
+	}
+	if len(t.Headers) != len(other.Headers) || len(t.Rows) != len(other.Rows) {
+		return false
+	}
+	for i, h := range t.Headers {
+		if h != other.Headers[i] || (compareTypes && t.types[i] != other.types[i]) {
+			return false
+		}
+	}
+	for i, row := range t.Rows {
+		for j, v := range row {
+			if v != other.Rows[i][j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// jsonValue converts a cell to its JSON value based on the column type,
+// reading numbers in the table's NumberFormat and booleans as its boolean
+// words, and falling back to the string when the cell does not parse as
+// that type
+func (t *Table) jsonValue(value string, colType ColumnType) interface{} {
+	switch colType {
+	case TypeInteger:
+		if val, err := t.numbers.ParseInt(value, 64); err == nil {
+			return val
+		}
+	case TypeFloat:
+		if val, err := t.numbers.ParseFloat(value); err == nil {
+			return val
+		}
+	case TypeBoolean:
+		if b, ok := t.bools.parse(value); ok {
+			return b
+		}
+	case TypeNull:
+		if value == "" || strings.EqualFold(value, "null") || strings.EqualFold(value, "\\N") {
+			return nil
+		}
+	}
+	return value
+}
+
 // ExportToJSON exports the table to a JSON file with optional formatting
 func (t *Table) ExportToJSON(writer io.Writer) error {
 	if t == nil || len(t.Headers) == 0 {
@@ -384,36 +1078,7 @@ func (t *Table) ExportToJSON(writer io.Writer) error {
 	for i, row := range t.Rows {
 		rowMap := make(map[string]interface{})
 		for j, header := range t.Headers {
-			// Convert values based on column type
-			colType, _ := t.GetColumnType(header)
-			value := row[j]
-
-			switch colType {
-			case TypeInteger:
-				if val, err := strconv.ParseInt(value, 10, 64); err == nil {
-					rowMap[header] = val
-					continue
-				}
-			case TypeFloat:
-				if val, err := strconv.ParseFloat(value, 64); err == nil {
-					rowMap[header] = val
-					continue
-				}
-			case TypeBoolean:
-				if strings.EqualFold(value, "true") {
-					rowMap[header] = true
-					continue
-				} else if strings.EqualFold(value, "false") {
-					rowMap[header] = false
-					continue
-				}
-			case TypeNull:
-				if value == "" || strings.EqualFold(value, "null") || strings.EqualFold(value, "\\N") {
-					rowMap[header] = nil
-					continue
-				}
-			}
-			rowMap[header] = value
+			rowMap[header] = t.jsonValue(row[j], t.types[j])
 		}
 		data[i] = rowMap
 	}
@@ -424,18 +1089,40 @@ func (t *Table) ExportToJSON(writer io.Writer) error {
 	return encoder.Encode(data)
 }
 
+// HTMLOptions controls ExportToHTMLWithOptions
+type HTMLOptions struct {
+	Title      string // Page title, defaults to "CSV Data"
+	Caption    string // Table caption, omitted if empty
+	TableClass string // class attribute of the table element, omitted if empty
+	ID         string // id attribute of the table element, omitted if empty
+
+	// Minimal leaves out the built-in stylesheet, for pages styled by the
+	// caller's own CSS through TableClass or ID
+	Minimal bool
+}
+
 // ExportToHTML exports the table to an HTML file with responsive styling
 func (t *Table) ExportToHTML(writer io.Writer) error {
+	return t.ExportToHTMLWithOptions(writer, HTMLOptions{})
+}
+
+// ExportToHTMLWithOptions exports the table to an HTML file like
+// ExportToHTML, with the page title, caption, table attributes and styling
+// set by opts. Headers, cells and options are HTML-escaped.
+func (t *Table) ExportToHTMLWithOptions(writer io.Writer, opts HTMLOptions) error {
 	if t == nil || len(t.Headers) == 0 {
 		return fmt.Errorf("cannot export empty table")
 	}
+	if opts.Title == "" {
+		opts.Title = "CSV Data"
+	}
 
 	const htmlTemplate = `<!DOCTYPE html>
 <html>
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>CSV Data</title>
+    <title>{{.Options.Title}}</title>{{if not .Options.Minimal}}
     <style>
         body {
             font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, Helvetica, Arial, sans-serif;
@@ -478,17 +1165,18 @@ func (t *Table) ExportToHTML(writer io.Writer) error {
                 min-width: 120px;
             }
         }
-    </style>
+    </style>{{end}}
 </head>
 <body>
-    <table>
+    <table{{with .Options.ID}} id="{{.}}"{{end}}{{with .Options.TableClass}} class="{{.}}"{{end}}>{{with .Options.Caption}}
+        <caption>{{.}}</caption>{{end}}
         <thead>
             <tr>
-                {{range .Headers}}<th>{{.}}</th>{{end}}
+                {{range .Table.Headers}}<th>{{.}}</th>{{end}}
             </tr>
         </thead>
         <tbody>
-            {{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>{{end}}
+            {{range .Table.Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>{{end}}
         </tbody>
     </table>
 </body>
@@ -499,7 +1187,60 @@ func (t *Table) ExportToHTML(writer io.Writer) error {
 		return fmt.Errorf("error parsing HTML template: %w", err)
 	}
 
-	return tmpl.Execute(writer, t)
+	return tmpl.Execute(writer, struct {
+		Table   *Table
+		Options HTMLOptions
+	}{t, opts})
+}
+
+// WriteCSV writes the table, headers first, as CSV using a Writer with
+// cfg's Delimiter, Quote and LineTerminator. With cfg.SkipHeaderOnWrite only
+// the rows are written, so batches can be appended to an existing file.
+func (t *Table) WriteCSV(writer io.Writer, cfg Config) error {
+	w, err := NewWriter(writer, cfg)
+	if err != nil {
+		return err
+	}
+	if !cfg.SkipHeaderOnWrite {
+		if err := w.Write(t.Headers); err != nil {
+			return err
+		}
+	}
+	for _, row := range t.Rows {
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// ExportToMarkdown exports the table as a GitHub-flavored Markdown table.
+// Pipes are escaped and line breaks become <br>.
+func (t *Table) ExportToMarkdown(writer io.Writer) error {
+	if t == nil || len(t.Headers) == 0 {
+		return fmt.Errorf("cannot export empty table")
+	}
+
+	escape := strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+	w := bufio.NewWriter(writer)
+	writeRow := func(cells []string) {
+		w.WriteString("|")
+		for _, cell := range cells {
+			w.WriteString(" " + escape.Replace(cell) + " |")
+		}
+		w.WriteString("\n")
+	}
+
+	writeRow(t.Headers)
+	w.WriteString("|")
+	for range t.Headers {
+		w.WriteString(" --- |")
+	}
+	w.WriteString("\n")
+	for _, row := range t.Rows {
+		writeRow(row)
+	}
+	return w.Flush()
 }
 
 // GetTypes returns the column types
@@ -511,3 +1252,49 @@ func (t *Table) GetTypes() []ColumnType {
 func (t *Table) GetIndex() map[string]int {
 	return t.index
 }
+
+// Shape returns the number of rows and columns
+func (t *Table) Shape() (rows, cols int) {
+	return len(t.Rows), len(t.Headers)
+}
+
+// Sizes used by MemoryEstimate for the string and slice headers that refer
+// to the cell data
+const (
+	stringHeaderSize = int64(unsafe.Sizeof(""))
+	sliceHeaderSize  = int64(unsafe.Sizeof([]string(nil)))
+)
+
+// MemoryEstimate returns the approximate number of bytes the table's headers
+// and rows occupy: the text of every cell plus the string and slice headers
+// that hold them. Cells that share memory, such as rows shared with a
+// filtered copy, are counted in each table, and allocator overhead and
+// internal caches are not counted.
+func (t *Table) MemoryEstimate() int64 {
+	size := sliceHeaderSize*2 + stringHeaderSize*int64(len(t.Headers))
+	for _, h := range t.Headers {
+		size += int64(len(h))
+	}
+	for _, row := range t.Rows {
+		size += sliceHeaderSize + stringHeaderSize*int64(len(row))
+		for _, cell := range row {
+			size += int64(len(cell))
+		}
+	}
+	return size
+}
+
+// FormatBytes formats a byte count for display with binary units, e.g.
+// "512 B", "1.5 KiB" or "2.0 GiB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -1,8 +1,16 @@
 package pkg
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
 )
 
 // Color codes for terminal output
@@ -101,6 +109,24 @@ type FormatOptions struct {
 	WrapText        bool     // Whether to wrap text in cells
 	HideHeaders     bool     // Whether to hide headers
 	CompactBorders  bool     // Whether to use compact borders
+	FitToWidth      int      // Maximum line width (0 for unlimited, -1 to detect the terminal width)
+
+	// CellStyle, if set, returns an ANSI prefix for the data cell at the given
+	// row and column; the cell is reset after its content. An empty prefix
+	// leaves the cell unstyled.
+	CellStyle func(row, col int, value string) string
+
+	// ColumnFormat maps column names to a fmt verb, such as "%06d" or
+	// "%.2f", applied to their cells before sizing and alignment. Numbers
+	// are read in the table's NumberFormat. Cells the verb does not apply to
+	// are shown as they are.
+	ColumnFormat map[string]string
+
+	// AlignmentByName maps column names to an alignment ("left", "right",
+	// "center"). A column listed here uses it instead of its Alignment
+	// entry, so the mapping survives reordering columns. Names that are not
+	// columns are ignored.
+	AlignmentByName map[string]string
 }
 
 // DefaultFormat returns the default formatting options
@@ -117,62 +143,208 @@ func DefaultFormat() FormatOptions {
 	}
 }
 
-// Format returns a formatted string representation of the table
+// ColumnWidths returns the display width, as measured by DisplayWidth, of the
+// widest value in each column, header included. The cell widths are cached
+// and kept up to date by AddRow and AppendRows; they are recomputed after
+// other changes through Table methods, or when the number of rows changes.
+// Editing cells directly in Rows is not detected.
+func (t *Table) ColumnWidths() []int {
+	cells := t.cachedCellWidths()
+	widths := make([]int, len(t.Headers))
+	for i, h := range t.Headers {
+		widths[i] = max(DisplayWidth(h), cells[i])
+	}
+	return widths
+}
+
+// cachedCellWidths returns cellWidths for the table's rows, computing it only
+// if it is missing or stale. It may be called by several readers at once; a
+// recomputation replaces the cache rather than updating it in place, so the
+// returned slice stays valid until the table is modified.
+func (t *Table) cachedCellWidths() []int {
+	t.widthMu.Lock()
+	defer t.widthMu.Unlock()
+	if t.widths == nil || len(t.widths) != len(t.Headers) || t.widthRows != len(t.Rows) {
+		t.widths = cellWidths(len(t.Headers), t.Rows)
+		t.widthRows = len(t.Rows)
+	}
+	return t.widths
+}
+
+// updateWidths extends the cached cell widths with rows just appended by
+// AddRow or AppendRows, if the cache was up to date before them
+func (t *Table) updateWidths(rows ...[]string) {
+	if t.widths == nil || t.widthRows != len(t.Rows)-len(rows) {
+		return
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			t.widths[i] = max(t.widths[i], DisplayWidth(cell))
+		}
+	}
+	t.widthRows += len(rows)
+}
+
+// cellWidths returns the display width of the widest cell in each of numCols
+// columns
+func cellWidths(numCols int, rows [][]string) []int {
+	widths := make([]int, numCols)
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= numCols {
+				continue
+			}
+			if w := DisplayWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	return widths
+}
+
+// Format returns a formatted string representation of the table. Use
+// WriteFormatted to print large tables without holding the output in memory.
 func (t *Table) Format(opts FormatOptions) string {
+	var sb strings.Builder
+	// Writing to a strings.Builder cannot fail
+	_, _ = t.WriteFormatted(&sb, opts)
+	return sb.String()
+}
+
+// WriteFormatted writes the table to w exactly as Format returns it, a row at
+// a time, so the output of a large table is never held in memory as a whole.
+// It returns the number of bytes written and the first error from w.
+func (t *Table) WriteFormatted(w io.Writer, opts FormatOptions) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
 	if len(t.Headers) == 0 {
-		return "empty table"
+		bw.WriteString("empty table")
+	} else {
+		t.writeFormatted(bw, opts)
 	}
+	err := bw.Flush()
+	return cw.n, err
+}
 
-	// Calculate column widths
+// writeFormatted writes the formatted table to sb. Write errors are kept by
+// sb and reported when it is flushed.
+func (t *Table) writeFormatted(sb *bufio.Writer, opts FormatOptions) {
+	// Calculate column widths, reusing the cached cell widths unless the
+	// cells are reformatted
+	verbs := t.columnVerbs(opts.ColumnFormat)
+	var cells []int
+	if verbs != nil {
+		cells = make([]int, len(t.Headers))
+		for _, row := range t.Rows {
+			for i, cell := range formatRow(row, verbs, t.numbers) {
+				if w := DisplayWidth(cell); i < len(cells) && w > cells[i] {
+					cells[i] = w
+				}
+			}
+		}
+	} else {
+		cells = t.cachedCellWidths()
+	}
 	widths := make([]int, len(t.Headers))
 	for i, h := range t.Headers {
-		widths[i] = len(h)
-	}
-	for _, row := range t.Rows {
-		for i, cell := range row {
-			if opts.MaxColumnWidth > 0 && len(cell) > opts.MaxColumnWidth {
-				if len(cell) > widths[i] {
-					widths[i] = opts.MaxColumnWidth
-				}
-			} else if len(cell) > widths[i] {
-				widths[i] = len(cell)
+		widths[i] = DisplayWidth(h)
+		// Wrapped headers are held to the column limit like data cells
+		if opts.WrapText && opts.MaxColumnWidth > 0 && widths[i] > opts.MaxColumnWidth {
+			widths[i] = opts.MaxColumnWidth
+		}
+		if opts.MaxColumnWidth > 0 && cells[i] > opts.MaxColumnWidth {
+			if cells[i] > widths[i] {
+				widths[i] = opts.MaxColumnWidth
 			}
+		} else if cells[i] > widths[i] {
+			widths[i] = cells[i]
+		}
+	}
+	if target := opts.FitToWidth; target != 0 {
+		if target < 0 {
+			target = terminalWidth()
+		}
+		if target > 0 {
+			fitWidths(widths, target-tableOverhead(len(widths), opts))
 		}
 	}
 
-	var sb strings.Builder
+	alignments := opts.Alignment
+	if len(opts.AlignmentByName) > 0 {
+		alignments = make([]string, len(t.Headers))
+		copy(alignments, opts.Alignment)
+		for name, align := range opts.AlignmentByName {
+			if idx, ok := t.index[name]; ok {
+				alignments[idx] = align
+			}
+		}
+	}
 
 	// Write top border
-	writeHorizontalBorder(&sb, widths, opts, true)
+	writeHorizontalBorder(sb, widths, opts, true)
 	sb.WriteString("\n")
 
 	// Write headers
 	if !opts.HideHeaders {
-		sb.WriteString(opts.Style.Vertical)
-		if opts.NumberedRows {
-			sb.WriteString(" # ")
-			sb.WriteString(opts.Style.Vertical)
-		}
+		headerLines := make([][]string, len(t.Headers))
+		maxLines := 1
 		for i, h := range t.Headers {
-			sb.WriteString(" ")
-			cell := FormatCell(h, widths[i], getAlignment(opts.Alignment, i, "center"))
-			sb.WriteString(opts.HeaderColor + opts.HeaderStyle + cell + Reset)
-			sb.WriteString(" " + opts.Style.Vertical)
+			if opts.WrapText && DisplayWidth(h) > widths[i] {
+				headerLines[i] = WrapText(h, widths[i])
+				if len(headerLines[i]) > maxLines {
+					maxLines = len(headerLines[i])
+				}
+			} else {
+				headerLines[i] = []string{h}
+			}
 		}
-		sb.WriteString("\n")
-		writeHorizontalBorder(&sb, widths, opts, false)
+
+		for lineIdx := 0; lineIdx < maxLines; lineIdx++ {
+			sb.WriteString(opts.Style.Vertical)
+			if opts.NumberedRows {
+				if lineIdx == 0 {
+					sb.WriteString(" # ")
+				} else {
+					sb.WriteString("   ")
+				}
+				sb.WriteString(opts.Style.Vertical)
+			}
+			for i := range t.Headers {
+				sb.WriteString(" ")
+				if lineIdx < len(headerLines[i]) {
+					cell := FormatCell(headerLines[i][lineIdx], widths[i], getAlignment(alignments, i, "center"))
+					sb.WriteString(opts.HeaderColor + opts.HeaderStyle + cell + Reset)
+				} else {
+					sb.WriteString(strings.Repeat(" ", widths[i]))
+				}
+				sb.WriteString(" " + opts.Style.Vertical)
+			}
+			sb.WriteString("\n")
+		}
+		writeHorizontalBorder(sb, widths, opts, false)
 		sb.WriteString("\n")
 	}
 
 	// Write rows
 	for rowIdx, row := range t.Rows {
+		if verbs != nil {
+			row = formatRow(row, verbs, t.numbers)
+		}
+
+		// Alternate rows are colored as one band from the first cell to the
+		// right border, so padding and separators share the color
+		bandStart, bandEnd := "", ""
+		if opts.AlternateRows && rowIdx%2 == 1 {
+			bandStart, bandEnd = opts.AlternateColor, Reset
+		}
+
 		// Handle text wrapping
 		if opts.WrapText {
 			wrappedCells := make([][]string, len(row))
 			maxLines := 1
 			for i, cell := range row {
-				if opts.MaxColumnWidth > 0 && len(cell) > opts.MaxColumnWidth {
-					wrappedCells[i] = WrapText(cell, opts.MaxColumnWidth)
+				if DisplayWidth(cell) > widths[i] {
+					wrappedCells[i] = WrapText(cell, widths[i])
 					if len(wrappedCells[i]) > maxLines {
 						maxLines = len(wrappedCells[i])
 					}
@@ -183,7 +355,8 @@ func (t *Table) Format(opts FormatOptions) string {
 
 			// Write each line of the wrapped cells
 			for lineIdx := 0; lineIdx < maxLines; lineIdx++ {
-				writeRowBorder(&sb, opts)
+				writeRowBorder(sb, opts)
+				sb.WriteString(bandStart)
 				if opts.NumberedRows {
 					if lineIdx == 0 {
 						sb.WriteString(fmt.Sprintf(" %2d ", rowIdx+1))
@@ -196,20 +369,18 @@ func (t *Table) Format(opts FormatOptions) string {
 				for i := range row {
 					sb.WriteString(" ")
 					if lineIdx < len(wrappedCells[i]) {
-						cell := FormatCell(wrappedCells[i][lineIdx], widths[i], getAlignment(opts.Alignment, i, "left"))
-						if opts.AlternateRows && rowIdx%2 == 1 {
-							cell = opts.AlternateColor + cell + Reset
-						}
-						sb.WriteString(cell)
+						cell := FormatCell(wrappedCells[i][lineIdx], widths[i], getAlignment(alignments, i, "left"))
+						sb.WriteString(styleCell(opts, rowIdx, i, t.Rows[rowIdx][i], cell, bandStart))
 					} else {
 						sb.WriteString(strings.Repeat(" ", widths[i]))
 					}
 					sb.WriteString(" " + opts.Style.Vertical)
 				}
-				sb.WriteString("\n")
+				sb.WriteString(bandEnd + "\n")
 			}
 		} else {
-			writeRowBorder(&sb, opts)
+			writeRowBorder(sb, opts)
+			sb.WriteString(bandStart)
 			if opts.NumberedRows {
 				sb.WriteString(fmt.Sprintf(" %2d ", rowIdx+1))
 				sb.WriteString(opts.Style.Vertical)
@@ -217,27 +388,98 @@ func (t *Table) Format(opts FormatOptions) string {
 
 			for i, cell := range row {
 				sb.WriteString(" ")
-				formattedCell := FormatCell(cell, widths[i], getAlignment(opts.Alignment, i, "left"))
-				if opts.AlternateRows && rowIdx%2 == 1 {
-					formattedCell = opts.AlternateColor + formattedCell + Reset
-				}
-				sb.WriteString(formattedCell)
+				formattedCell := FormatCell(cell, widths[i], getAlignment(alignments, i, "left"))
+				sb.WriteString(styleCell(opts, rowIdx, i, t.Rows[rowIdx][i], formattedCell, bandStart))
 				sb.WriteString(" " + opts.Style.Vertical)
 			}
-			sb.WriteString("\n")
+			sb.WriteString(bandEnd + "\n")
 		}
 	}
 
 	// Write bottom border
-	writeHorizontalBorder(&sb, widths, opts, false)
+	writeHorizontalBorder(sb, widths, opts, false)
 	sb.WriteString("\n")
+}
 
-	return sb.String()
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
 }
 
 // Helper functions
 
-func writeHorizontalBorder(sb *strings.Builder, widths []int, opts FormatOptions, isTop bool) {
+// minFitWidth is the narrowest a column is shrunk to, enough for "..."
+const minFitWidth = 3
+
+// tableOverhead returns the number of characters per line used by borders,
+// padding and row numbers for a table with cols columns
+func tableOverhead(cols int, opts FormatOptions) int {
+	overhead := 1 + cols*3 // left border, then " cell |" per column
+	if opts.NumberedRows {
+		overhead += 5
+	}
+	return overhead
+}
+
+// fitWidths shrinks widths so they sum to at most avail. Columns narrower
+// than the fair share keep their width, and the remaining space is split
+// evenly among the wider columns, so the widest are truncated first. No
+// column is shrunk below minFitWidth, so very narrow targets may still be
+// exceeded.
+func fitWidths(widths []int, avail int) {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	if total <= avail {
+		return
+	}
+
+	sorted := append([]int(nil), widths...)
+	sort.Ints(sorted)
+
+	// Find the largest cap such that the capped widths fit in avail
+	limit := minFitWidth
+	remaining := avail
+	for i, w := range sorted {
+		share := remaining / (len(sorted) - i)
+		if w > share {
+			limit = share
+			break
+		}
+		remaining -= w
+	}
+	if limit < minFitWidth {
+		limit = minFitWidth
+	}
+
+	for i, w := range widths {
+		if w > limit {
+			widths[i] = limit
+		}
+	}
+}
+
+// terminalWidth returns the width of the terminal on stdout, falling back to
+// the COLUMNS environment variable, or 0 if neither is available
+func terminalWidth() int {
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return 0
+}
+
+func writeHorizontalBorder(sb *bufio.Writer, widths []int, opts FormatOptions, isTop bool) {
 	if isTop {
 		sb.WriteString(opts.BorderColor + opts.Style.TopLeft + Reset)
 	} else {
@@ -271,37 +513,170 @@ func writeHorizontalBorder(sb *strings.Builder, widths []int, opts FormatOptions
 	}
 }
 
-func writeRowBorder(sb *strings.Builder, opts FormatOptions) {
+// columnVerbs returns the ColumnFormat verb of each column, empty for
+// columns without one, or nil if no column of the table has one
+func (t *Table) columnVerbs(formats map[string]string) []string {
+	var verbs []string
+	for name, verb := range formats {
+		col, ok := t.index[name]
+		if !ok {
+			continue
+		}
+		if verbs == nil {
+			verbs = make([]string, len(t.Headers))
+		}
+		verbs[col] = verb
+	}
+	return verbs
+}
+
+// formatRow returns a copy of row with each cell passed through its column's
+// verb, reading numbers in nf. The row itself is not changed.
+func formatRow(row, verbs []string, nf NumberFormat) []string {
+	formatted := append([]string(nil), row...)
+	for i, verb := range verbs {
+		if verb != "" && i < len(formatted) {
+			formatted[i] = formatValue(verb, formatted[i], nf)
+		}
+	}
+	return formatted
+}
+
+// formatValue applies a fmt verb to s, parsing s as the number the verb
+// expects, written in nf. If s does not parse, or the verb is unknown, s is
+// returned as is.
+func formatValue(verb, s string, nf NumberFormat) string {
+	if verb == "" || DetectType(s) == TypeNull {
+		return s
+	}
+	switch verb[len(verb)-1] {
+	case 'd', 'x', 'X', 'o', 'b', 'c':
+		if n, err := nf.ParseInt(s, 64); err == nil {
+			return fmt.Sprintf(verb, n)
+		}
+	case 'f', 'F', 'e', 'E', 'g', 'G':
+		if f, err := nf.ParseFloat(s); err == nil {
+			return fmt.Sprintf(verb, f)
+		}
+	case 's', 'q', 'v':
+		return fmt.Sprintf(verb, s)
+	}
+	return s
+}
+
+// styleCell applies opts.CellStyle to a formatted cell. The reset after the
+// cell also clears the alternate row color, so band is re-applied.
+func styleCell(opts FormatOptions, row, col int, value, cell, band string) string {
+	if opts.CellStyle == nil {
+		return cell
+	}
+	prefix := opts.CellStyle(row, col, value)
+	if prefix == "" {
+		return cell
+	}
+	return prefix + cell + Reset + band
+}
+
+func writeRowBorder(sb *bufio.Writer, opts FormatOptions) {
 	sb.WriteString(opts.BorderColor + opts.Style.Vertical + Reset)
 }
 
 func FormatCell(content string, width int, alignment string) string {
-	if len(content) > width {
-		return content[:width-3] + "..."
+	w := DisplayWidth(content)
+	if w > width {
+		head, _ := splitDisplay(content, width-3)
+		if strings.Contains(head, "\033") {
+			head += Reset // don't let a cut-off color run into the ellipsis
+		}
+		return head + "..."
 	}
 
+	padding := width - w
 	switch alignment {
 	case "right":
-		return fmt.Sprintf("%*s", width, content)
+		return strings.Repeat(" ", padding) + content
 	case "center":
-		padding := width - len(content)
 		leftPad := padding / 2
 		rightPad := padding - leftPad
-		return fmt.Sprintf("%*s%s%*s", leftPad, "", content, rightPad, "")
+		return strings.Repeat(" ", leftPad) + content + strings.Repeat(" ", rightPad)
 	default: // "left"
-		return fmt.Sprintf("%-*s", width, content)
+		return content + strings.Repeat(" ", padding)
+	}
+}
+
+// DisplayWidth returns the number of terminal columns s occupies: its rune
+// count, not counting ANSI escape sequences such as color codes
+func DisplayWidth(s string) int {
+	plain := true
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\033' || s[i] >= utf8.RuneSelf {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return len(s)
+	}
+
+	width := 0
+	for i := 0; i < len(s); {
+		if n := ansiLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		width++
+	}
+	return width
+}
+
+// ansiLen returns the length of the ANSI escape sequence at the start of s,
+// or 0 if s does not start with one
+func ansiLen(s string) int {
+	if len(s) < 2 || s[0] != '\033' || s[1] != '[' {
+		return 0
+	}
+	// A control sequence ends with a byte in the range '@' to '~'
+	for i := 2; i < len(s); i++ {
+		if s[i] >= '@' && s[i] <= '~' {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// splitDisplay splits s after width display columns, keeping escape
+// sequences whole. Escape sequences at the split point go with the head.
+func splitDisplay(s string, width int) (head, rest string) {
+	width = max(width, 0)
+	cols := 0
+	for i := 0; i < len(s); {
+		if n := ansiLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		if cols == width {
+			return s[:i], s[i:]
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		cols++
 	}
+	return s, ""
 }
 
+// getAlignment returns the alignment of column index, or defaultAlign if it
+// has none
 func getAlignment(alignments []string, index int, defaultAlign string) string {
-	if index < len(alignments) {
+	if index < len(alignments) && alignments[index] != "" {
 		return strings.ToLower(alignments[index])
 	}
 	return defaultAlign
 }
 
 func WrapText(text string, width int) []string {
-	if len(text) <= width {
+	if DisplayWidth(text) <= width {
 		return []string{text}
 	}
 
@@ -310,7 +685,7 @@ func WrapText(text string, width int) []string {
 	words := strings.Fields(text)
 
 	for _, word := range words {
-		if len(line)+len(word)+1 <= width {
+		if DisplayWidth(line)+DisplayWidth(word)+1 <= width {
 			if line != "" {
 				line += " "
 			}
@@ -319,11 +694,12 @@ func WrapText(text string, width int) []string {
 			if line != "" {
 				lines = append(lines, line)
 			}
-			if len(word) > width {
+			if DisplayWidth(word) > width {
 				// Word is longer than width, need to split it
-				for len(word) > width {
-					lines = append(lines, word[:width])
-					word = word[width:]
+				for DisplayWidth(word) > width {
+					var head string
+					head, word = splitDisplay(word, width)
+					lines = append(lines, head)
 				}
 				if word != "" {
 					line = word
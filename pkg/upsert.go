@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Upsert applies the rows of other to t, matching them by the values of
+// keyCols: every row of t with the same key is replaced by the row from
+// other, and rows whose key is not in t are appended. Rows of other are
+// applied in order, so a later row wins over an earlier one with the same
+// key. Both tables must have the same columns, though not necessarily in
+// the same order. Column types are detected again afterwards.
+func (t *Table) Upsert(other *Table, keyCols []string) error {
+	if len(keyCols) == 0 {
+		return fmt.Errorf("no key columns given")
+	}
+	if len(other.Headers) != len(t.Headers) {
+		return fmt.Errorf("other table has %d columns, table has %d", len(other.Headers), len(t.Headers))
+	}
+	// perm[i] is the column of other holding t's column i
+	perm := make([]int, len(t.Headers))
+	for i, h := range t.Headers {
+		j, ok := other.index[h]
+		if !ok {
+			return fmt.Errorf("column %q not found in other table", h)
+		}
+		perm[i] = j
+	}
+	keyIdx := make([]int, len(keyCols))
+	for i, col := range keyCols {
+		idx, ok := t.index[col]
+		if !ok {
+			return fmt.Errorf("key column %q not found", col)
+		}
+		keyIdx[i] = idx
+	}
+
+	keyOf := func(row []string) string {
+		parts := make([]string, len(keyIdx))
+		for i, idx := range keyIdx {
+			parts[i] = row[idx]
+		}
+		return strings.Join(parts, "\x00")
+	}
+	matches := make(map[string][]int, len(t.Rows))
+	for i, row := range t.Rows {
+		k := keyOf(row)
+		matches[k] = append(matches[k], i)
+	}
+
+	for _, otherRow := range other.Rows {
+		row := make([]string, len(perm))
+		for i, j := range perm {
+			row[i] = otherRow[j]
+		}
+		k := keyOf(row)
+		rows, ok := matches[k]
+		if !ok {
+			matches[k] = []int{len(t.Rows)}
+			t.Rows = append(t.Rows, row)
+			continue
+		}
+		for _, i := range rows {
+			for col, v := range row {
+				if t.Rows[i][col] != v {
+					t.setCell(i, col, v)
+				}
+			}
+		}
+	}
+	t.retype()
+	return nil
+}
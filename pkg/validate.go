@@ -0,0 +1,224 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ValidationError describes a single value that failed validation
+type ValidationError struct {
+	Row     int    // 1-based data row number (0 for column-level errors)
+	Column  string // Column header
+	Value   string // Offending value
+	Message string // Human readable description
+}
+
+// Error implements the error interface
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("Row %d, Column %s: %s", e.Row, e.Column, e.Message)
+}
+
+// Validate checks every value against its column's detected type, reading
+// numbers in the table's NumberFormat and booleans as its boolean words.
+// In strict mode empty values are reported as well, except in columns that
+// contain no values at all (TypeNull), which are treated as optional.
+func (t *Table) Validate(strict bool) []ValidationError {
+	var errs []ValidationError
+
+	for colIdx, header := range t.Headers {
+		colType := t.types[colIdx]
+
+		for i, row := range t.Rows {
+			val := row[colIdx]
+			isNull := DetectType(val) == TypeNull
+
+			if !isNull {
+				var msg string
+				if !t.matchesType(val, colType) {
+					switch colType {
+					case TypeInteger:
+						msg = fmt.Sprintf("Invalid integer value %q", val)
+					case TypeFloat:
+						msg = fmt.Sprintf("Invalid float value %q", val)
+					case TypeBoolean:
+						msg = fmt.Sprintf("Invalid boolean value %q", val)
+					}
+				}
+				if msg != "" {
+					errs = append(errs, ValidationError{Row: i + 1, Column: header, Value: val, Message: msg})
+				}
+			}
+
+			// In strict mode, check for empty fields
+			if strict && val == "" && colType != TypeNull {
+				errs = append(errs, ValidationError{
+					Row:     i + 1,
+					Column:  header,
+					Value:   val,
+					Message: "Empty field not allowed in strict mode",
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
+// ColumnSpec declares the constraints for a single column in a Schema
+type ColumnSpec struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type,omitempty"`           // "string", "integer", "float" or "boolean"
+	Required      bool     `json:"required,omitempty"`       // column must exist and values must be non-null
+	Min           *float64 `json:"min,omitempty"`            // inclusive lower bound for numeric values
+	Max           *float64 `json:"max,omitempty"`            // inclusive upper bound for numeric values
+	Pattern       string   `json:"pattern,omitempty"`        // regular expression the whole value must match
+	AllowedValues []string `json:"allowed_values,omitempty"` // exhaustive list of permitted values
+}
+
+// Schema is a list of column specifications to validate a table against
+type Schema []ColumnSpec
+
+// LoadSchema decodes a JSON array of column specs
+func LoadSchema(r io.Reader) (Schema, error) {
+	var s Schema
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("error decoding schema: %w", err)
+	}
+	for i, spec := range s {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("schema column %d has no name", i+1)
+		}
+	}
+	return s, nil
+}
+
+// ValidateSchema checks the table against the declared column specs.
+// Null values are only checked by Required; every other constraint applies
+// to non-null values. Columns in the table that are not in the schema are ignored.
+func (t *Table) ValidateSchema(s Schema) []ValidationError {
+	var errs []ValidationError
+
+	for _, spec := range s {
+		colIdx, ok := t.index[spec.Name]
+		if !ok {
+			if spec.Required {
+				errs = append(errs, ValidationError{Column: spec.Name, Message: "Required column is missing"})
+			}
+			continue
+		}
+
+		wantType := TypeString
+		if spec.Type != "" {
+			var err error
+			if wantType, err = parseColumnType(spec.Type); err != nil {
+				errs = append(errs, ValidationError{Column: spec.Name, Message: err.Error()})
+				continue
+			}
+		}
+
+		var pattern *regexp.Regexp
+		if spec.Pattern != "" {
+			var err error
+			if pattern, err = regexp.Compile(`^(?:` + spec.Pattern + `)$`); err != nil {
+				errs = append(errs, ValidationError{Column: spec.Name, Message: fmt.Sprintf("Invalid pattern: %v", err)})
+				continue
+			}
+		}
+
+		var allowed map[string]struct{}
+		if len(spec.AllowedValues) > 0 {
+			allowed = make(map[string]struct{}, len(spec.AllowedValues))
+			for _, v := range spec.AllowedValues {
+				allowed[v] = struct{}{}
+			}
+		}
+
+		for i, row := range t.Rows {
+			val := row[colIdx]
+			fail := func(format string, args ...interface{}) {
+				errs = append(errs, ValidationError{
+					Row:     i + 1,
+					Column:  spec.Name,
+					Value:   val,
+					Message: fmt.Sprintf(format, args...),
+				})
+			}
+
+			if DetectType(val) == TypeNull {
+				if spec.Required {
+					fail("Required value is missing")
+				}
+				continue
+			}
+
+			if !t.matchesType(val, wantType) {
+				fail("Value %q is not of type %s", val, spec.Type)
+				continue
+			}
+
+			if spec.Min != nil || spec.Max != nil {
+				f, err := t.numbers.ParseFloat(val)
+				switch {
+				case err != nil:
+					fail("Value %q is not numeric", val)
+				case spec.Min != nil && f < *spec.Min:
+					fail("Value %s is less than minimum %v", val, *spec.Min)
+				case spec.Max != nil && f > *spec.Max:
+					fail("Value %s is greater than maximum %v", val, *spec.Max)
+				}
+			}
+
+			if pattern != nil && !pattern.MatchString(val) {
+				fail("Value %q does not match pattern %q", val, spec.Pattern)
+			}
+
+			if allowed != nil {
+				if _, ok := allowed[val]; !ok {
+					fail("Value %q is not one of the allowed values", val)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// parseColumnType converts a type name used in schemas to a ColumnType
+func parseColumnType(name string) (ColumnType, error) {
+	switch strings.ToLower(name) {
+	case "string", "text":
+		return TypeString, nil
+	case "integer", "int":
+		return TypeInteger, nil
+	case "float", "number":
+		return TypeFloat, nil
+	case "boolean", "bool":
+		return TypeBoolean, nil
+	default:
+		return TypeString, fmt.Errorf("unknown column type %q", name)
+	}
+}
+
+// matchesType reports whether a non-null value can be read as colType,
+// with numbers written in the table's NumberFormat and booleans as its
+// boolean words
+func (t *Table) matchesType(val string, colType ColumnType) bool {
+	switch colType {
+	case TypeInteger:
+		_, err := t.numbers.ParseInt(val, 64)
+		return err == nil
+	case TypeFloat:
+		_, err := t.numbers.ParseFloat(val)
+		return err == nil
+	case TypeBoolean:
+		_, ok := t.bools.parse(val)
+		return ok
+	default:
+		return true
+	}
+}
@@ -0,0 +1,86 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Writer writes CSV records. Fields containing the delimiter, the quote
+// character, a line break or leading or trailing spaces are quoted, with
+// quotes doubled, and so is a field that would be read back as the start of a
+// Config.Comment line; Config.QuoteAll and Config.QuoteEmpty quote more
+// fields.
+// Output is buffered until Flush.
+type Writer struct {
+	w   *bufio.Writer
+	cfg Config
+}
+
+// NewWriter returns a Writer using cfg's Delimiter, Quote and
+// LineTerminator, defaulting to ',', '"' and "\n"
+func NewWriter(w io.Writer, cfg Config) (*Writer, error) {
+	if cfg.Delimiter == 0 {
+		cfg.Delimiter = ','
+	}
+	if cfg.Quote == 0 {
+		cfg.Quote = '"'
+	}
+	if cfg.Delimiter == cfg.Quote {
+		return nil, fmt.Errorf("delimiter and quote must be distinct")
+	}
+	switch cfg.LineTerminator {
+	case "":
+		cfg.LineTerminator = "\n"
+	case "\n", "\r\n":
+	default:
+		return nil, fmt.Errorf("invalid line terminator %q, expected %q or %q", cfg.LineTerminator, "\n", "\r\n")
+	}
+	return &Writer{w: bufio.NewWriter(w), cfg: cfg}, nil
+}
+
+// Write writes one record
+func (w *Writer) Write(record []string) error {
+	for i, field := range record {
+		if i > 0 {
+			if _, err := w.w.WriteRune(w.cfg.Delimiter); err != nil {
+				return err
+			}
+		}
+		if _, err := w.w.WriteString(w.quoteField(field, i == 0)); err != nil {
+			return err
+		}
+	}
+	_, err := w.w.WriteString(w.cfg.LineTerminator)
+	return err
+}
+
+// Flush writes any buffered data to the underlying writer
+func (w *Writer) Flush() error {
+	return w.w.Flush()
+}
+
+// quoteField quotes field if it cannot be written as-is or the config asks
+// for it. first is set for the first field of a record, where a leading
+// comment character would turn the line into a comment.
+func (w *Writer) quoteField(field string, first bool) string {
+	var needsQuotes bool
+	switch {
+	case w.cfg.QuoteAll:
+		needsQuotes = true
+	case field == "":
+		needsQuotes = w.cfg.QuoteEmpty
+	default:
+		needsQuotes = field[0] == ' ' || field[len(field)-1] == ' ' ||
+			strings.ContainsAny(field, "\r\n") ||
+			strings.ContainsRune(field, w.cfg.Delimiter) || strings.ContainsRune(field, w.cfg.Quote) ||
+			(w.cfg.Comment != 0 && (first || w.cfg.AllowInlineComments) &&
+				strings.HasPrefix(field, string(w.cfg.Comment)))
+	}
+	if !needsQuotes {
+		return field
+	}
+	q := string(w.cfg.Quote)
+	return q + strings.ReplaceAll(field, q, q+q) + q
+}
@@ -0,0 +1,131 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXOptions controls ExportToXLSX
+type XLSXOptions struct {
+	// SheetName names the worksheet; "Sheet1" if empty. Excel limits names to
+	// 31 characters and does not allow any of []:*?/\
+	SheetName string
+
+	// FreezeHeader keeps the header row in view while scrolling
+	FreezeHeader bool
+}
+
+// xlsxMaxDigits is the most digits a number can have and still be stored
+// exactly, since spreadsheets keep numbers as float64. Longer integers such
+// as account numbers are written as text.
+const xlsxMaxDigits = 15
+
+// ExportToXLSX writes the table as an Excel workbook with a single worksheet.
+// The header row is bold. Cells of integer and float columns are stored as
+// numbers, using the table's NumberFormat, and cells of boolean columns as
+// booleans, so spreadsheets can sum and filter them; everything else is
+// stored as text, as are numbers a spreadsheet would alter, such as those
+// with leading zeros or more than 15 digits. Null cells are left empty.
+// Rows are streamed into the workbook, so only the finished file is held in
+// memory alongside the table.
+func (t *Table) ExportToXLSX(writer io.Writer, opts XLSXOptions) error {
+	if t == nil || len(t.Headers) == 0 {
+		return fmt.Errorf("cannot export empty table")
+	}
+	sheet := opts.SheetName
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return fmt.Errorf("invalid sheet name %q: %w", sheet, err)
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"D9D9D9"}},
+	})
+	if err != nil {
+		return err
+	}
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	if opts.FreezeHeader {
+		err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+		if err != nil {
+			return err
+		}
+	}
+
+	cells := make([]interface{}, len(t.Headers))
+	for i, h := range t.Headers {
+		cells[i] = excelize.Cell{StyleID: headerStyle, Value: h}
+	}
+	if err := sw.SetRow("A1", cells); err != nil {
+		return err
+	}
+	for r, row := range t.Rows {
+		for i, value := range row {
+			cells[i] = t.xlsxValue(value, t.types[i])
+		}
+		if err := sw.SetRow("A"+strconv.Itoa(r+2), cells); err != nil {
+			return err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	_, err = f.WriteTo(writer)
+	return err
+}
+
+// xlsxValue converts a cell to the value stored in a column of type colType:
+// nil for a null cell, a number, a boolean, or the text itself
+func (t *Table) xlsxValue(value string, colType ColumnType) interface{} {
+	if DetectType(value) == TypeNull {
+		return nil
+	}
+	switch colType {
+	case TypeInteger, TypeFloat:
+		if n, ok := t.numbers.Normalize(value); ok {
+			if n, ok := xlsxNumber(n); ok {
+				if f, err := strconv.ParseFloat(n, 64); err == nil {
+					return f
+				}
+			}
+		}
+	case TypeBoolean:
+		if b, ok := t.bools.parse(value); ok {
+			return b
+		}
+	}
+	return value
+}
+
+// xlsxNumber returns the plain number n as a spreadsheet number, and false
+// if storing it as one would change it: Inf and NaN, hexadecimal floats,
+// numbers with leading zeros such as ZIP codes, and numbers with more digits
+// than a float64 keeps
+func xlsxNumber(n string) (string, bool) {
+	n = strings.TrimPrefix(n, "+")
+	mantissa, _, _ := strings.Cut(strings.ToLower(n), "e")
+	mantissa = strings.TrimPrefix(mantissa, "-")
+	if strings.Trim(n, "0123456789.eE+-") != "" ||
+		(len(mantissa) > 1 && mantissa[0] == '0' && mantissa[1] != '.') {
+		return "", false
+	}
+	digits := 0
+	for _, c := range strings.TrimLeft(mantissa, "0.") {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	return n, digits <= xlsxMaxDigits
+}
@@ -0,0 +1,81 @@
+package pkg_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestTableBuilder(t *testing.T) {
+	input := "id,name\n1,Ann\n2,Bob\n3,Cy\n"
+	cfg := pkg.DefaultConfig()
+	cfg.ReuseRecord = true
+
+	reader, err := pkg.NewReader(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	b, err := pkg.NewTableFromReader(reader)
+	if err != nil {
+		t.Fatalf("NewTableFromReader() error = %v", err)
+	}
+	if !reflect.DeepEqual(b.Headers(), []string{"id", "name"}) {
+		t.Errorf("Headers() = %v", b.Headers())
+	}
+
+	// Iterate partway, then build the rest
+	row, ok, err := b.Next()
+	if err != nil || !ok || !reflect.DeepEqual(row, []string{"1", "Ann"}) {
+		t.Fatalf("Next() = %v, %v, %v, want [1 Ann], true, nil", row, ok, err)
+	}
+	table, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	want, _ := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if !table.Equal(want) {
+		t.Errorf("Build() = %v, want %v", table.Rows, want.Rows)
+	}
+	if _, ok, err := b.Next(); ok || err != nil {
+		t.Errorf("Next() after the end = %v, %v, want false, nil", ok, err)
+	}
+	if b.Err() != nil {
+		t.Errorf("Err() = %v, want nil", b.Err())
+	}
+}
+
+func TestTableBuilderError(t *testing.T) {
+	input := "id,name\n1,Ann\n2,Bob,extra\n3,Cy\n"
+	reader, err := pkg.NewReader(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	b, err := pkg.NewTableFromReader(reader)
+	if err != nil {
+		t.Fatalf("NewTableFromReader() error = %v", err)
+	}
+
+	var rows int
+	for {
+		_, ok, err := b.Next()
+		if !ok {
+			if err == nil {
+				t.Fatal("Next() reached the end without reporting the bad row")
+			}
+			break
+		}
+		rows++
+	}
+	if rows != 1 {
+		t.Errorf("read %d rows before the error, want 1", rows)
+	}
+	if !errors.Is(b.Err(), pkg.ErrFieldCount) {
+		t.Errorf("Err() = %v, want ErrFieldCount", b.Err())
+	}
+	if _, err := b.Build(); !errors.Is(err, pkg.ErrFieldCount) {
+		t.Errorf("Build() error = %v, want ErrFieldCount", err)
+	}
+}
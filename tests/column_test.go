@@ -0,0 +1,163 @@
+package pkg_test
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestTypedColumns(t *testing.T) {
+	input := `id,price,active,joined
+1,9.99,true,2024-01-15
+2,n/a,FALSE,2024-02-01
+3,12,yes,not a date
+x,,True,02/20/2024
+`
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	floats, bad, err := table.GetColumnFloats("price")
+	if err != nil {
+		t.Fatalf("GetColumnFloats() error = %v", err)
+	}
+	if !reflect.DeepEqual(floats, []float64{9.99, 12}) || !reflect.DeepEqual(bad, []int{1, 3}) {
+		t.Errorf("GetColumnFloats() = %v, %v, want [9.99 12], [1 3]", floats, bad)
+	}
+
+	ints, bad, err := table.GetColumnInts("id")
+	if err != nil {
+		t.Fatalf("GetColumnInts() error = %v", err)
+	}
+	if !reflect.DeepEqual(ints, []int64{1, 2, 3}) || !reflect.DeepEqual(bad, []int{3}) {
+		t.Errorf("GetColumnInts() = %v, %v, want [1 2 3], [3]", ints, bad)
+	}
+
+	bools, bad, err := table.GetColumnBools("active")
+	if err != nil {
+		t.Fatalf("GetColumnBools() error = %v", err)
+	}
+	if !reflect.DeepEqual(bools, []bool{true, false, true}) || !reflect.DeepEqual(bad, []int{2}) {
+		t.Errorf("GetColumnBools() = %v, %v, want [true false true], [2]", bools, bad)
+	}
+
+	times, bad, err := table.GetColumnTimes("joined", "")
+	if err != nil {
+		t.Fatalf("GetColumnTimes() error = %v", err)
+	}
+	wantTimes := []time.Time{
+		time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(times, wantTimes) || !reflect.DeepEqual(bad, []int{2}) {
+		t.Errorf("GetColumnTimes() = %v, %v, want %v, [2]", times, bad, wantTimes)
+	}
+
+	if _, bad, _ := table.GetColumnTimes("joined", "2006-01-02"); !reflect.DeepEqual(bad, []int{2, 3}) {
+		t.Errorf("GetColumnTimes() with layout bad = %v, want [2 3]", bad)
+	}
+	if _, _, err := table.GetColumnFloats("missing"); err == nil {
+		t.Error("GetColumnFloats() on a missing column should fail")
+	}
+}
+
+func TestCoerceTypes(t *testing.T) {
+	headers := []string{"qty", "price", "name"}
+	table := pkg.NewTable(headers)
+	for i := 0; i < 99; i++ {
+		price := strconv.Itoa(i) + ".5"
+		if i == 10 {
+			price = "1,5"
+		}
+		if err := table.AddRow([]string{strconv.Itoa(i), price, "item" + strconv.Itoa(i)}); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+	if err := table.AddRow([]string{"garbage", "", "last"}); err != nil {
+		t.Fatalf("AddRow() error = %v", err)
+	}
+	for _, h := range headers {
+		if ct, _ := table.GetColumnType(h); ct != pkg.TypeString {
+			t.Fatalf("column %s detected as %v before coercion, want string", h, ct)
+		}
+	}
+
+	invalid := table.CoerceTypes(pkg.CoerceOptions{BlankInvalid: true})
+
+	want := map[string]pkg.ColumnType{"qty": pkg.TypeInteger, "price": pkg.TypeFloat, "name": pkg.TypeString}
+	for h, wantType := range want {
+		if ct, _ := table.GetColumnType(h); ct != wantType {
+			t.Errorf("column %s coerced to %v, want %v", h, ct, wantType)
+		}
+	}
+	wantInvalid := map[string][]int{"qty": {99}, "price": {10}}
+	if !reflect.DeepEqual(invalid, wantInvalid) {
+		t.Errorf("CoerceTypes() = %v, want %v", invalid, wantInvalid)
+	}
+	if table.Rows[99][0] != "" || table.Rows[10][1] != "" {
+		t.Errorf("invalid cells not blanked: %q, %q", table.Rows[99][0], table.Rows[10][1])
+	}
+
+	// A stricter threshold leaves a column with too many stray values alone
+	strict := pkg.NewTable([]string{"n"})
+	for _, v := range []string{"1", "2", "3", "x"} {
+		if err := strict.AddRow([]string{v}); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+	if got := strict.CoerceTypes(pkg.CoerceOptions{MinFraction: 0.8}); len(got) != 0 {
+		t.Errorf("CoerceTypes() = %v, want no coerced columns", got)
+	}
+	if strict.Rows[3][0] != "x" {
+		t.Errorf("uncoerced value changed to %q", strict.Rows[3][0])
+	}
+
+	// Blanking a filtered table leaves the rows it shares with its source alone
+	source := pkg.NewTable([]string{"n"})
+	for _, v := range []string{"1", "2", "3", "4", "x"} {
+		if err := source.AddRow([]string{v}); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+	filtered := source.Filter(func([]string) bool { return true })
+	filtered.CoerceTypes(pkg.CoerceOptions{MinFraction: 0.8, BlankInvalid: true})
+	if filtered.Rows[4][0] != "" {
+		t.Errorf("filtered cell = %q, want blanked", filtered.Rows[4][0])
+	}
+	if source.Rows[4][0] != "x" {
+		t.Errorf("source cell changed to %q by coercing a filtered table", source.Rows[4][0])
+	}
+	if ct, _ := source.GetColumnType("n"); ct != pkg.TypeString {
+		t.Errorf("source column type = %v, want string", ct)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	table, err := pkg.ReadTable(strings.NewReader("name,age,score,active\nAnn,30,1.5,true\nBob,,2,false\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	want := []pkg.Column{
+		{Name: "name", Type: pkg.TypeString, Values: []string{"Ann", "Bob"}},
+		{Name: "age", Type: pkg.TypeInteger, Values: []string{"30", ""}},
+		{Name: "score", Type: pkg.TypeFloat, Values: []string{"1.5", "2"}},
+		{Name: "active", Type: pkg.TypeBoolean, Values: []string{"true", "false"}},
+	}
+	got := table.Columns()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Columns() = %+v, want %+v", got, want)
+	}
+
+	// The values are copies
+	got[0].Values[0] = "Zed"
+	if table.Rows[0][0] != "Ann" {
+		t.Error("modifying Columns() values changed the table")
+	}
+}
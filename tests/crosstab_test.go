@@ -0,0 +1,91 @@
+package pkg_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestCrossTab(t *testing.T) {
+	input := `dept,level
+IT,senior
+HR,junior
+IT,junior
+IT,senior
+Sales,
+HR,junior
+`
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	counts, err := table.CrossTab("dept", "level")
+	if err != nil {
+		t.Fatalf("CrossTab() error = %v", err)
+	}
+	wantHeaders := []string{"dept", "senior", "junior", "(null)"}
+	wantRows := [][]string{
+		{"IT", "2", "1", "0"},
+		{"HR", "0", "2", "0"},
+		{"Sales", "0", "0", "1"},
+	}
+	if !reflect.DeepEqual(counts.Headers, wantHeaders) || !reflect.DeepEqual(counts.Rows, wantRows) {
+		t.Errorf("CrossTab() = %v %v, want %v %v", counts.Headers, counts.Rows, wantHeaders, wantRows)
+	}
+
+	totals, err := table.CrossTabWithOptions("dept", "level", pkg.CrossTabOptions{Totals: true})
+	if err != nil {
+		t.Fatalf("CrossTabWithOptions() error = %v", err)
+	}
+	wantHeaders = append(wantHeaders, "Total")
+	wantRows = [][]string{
+		{"IT", "2", "1", "0", "3"},
+		{"HR", "0", "2", "0", "2"},
+		{"Sales", "0", "0", "1", "1"},
+		{"Total", "2", "3", "1", "6"},
+	}
+	if !reflect.DeepEqual(totals.Headers, wantHeaders) || !reflect.DeepEqual(totals.Rows, wantRows) {
+		t.Errorf("CrossTabWithOptions() = %v %v, want %v %v", totals.Headers, totals.Rows, wantHeaders, wantRows)
+	}
+
+	if _, err := table.CrossTab("dept", "missing"); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+}
+
+func TestCrossTabCollisions(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		totals bool
+	}{
+		{"column value is the row column's name", "x,y\na,x\nb,z\n", false},
+		{"row value is Total", "x,y\nTotal,a\nb,a\n", true},
+		{"column value is Total", "x,y\na,Total\nb,c\n", true},
+		{"row column is named Total", "Total,y\na,b\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := pkg.ReadTable(strings.NewReader(tt.input), pkg.DefaultConfig())
+			if err != nil {
+				t.Fatalf("ReadTable() error = %v", err)
+			}
+			rowCol := table.Headers[0]
+			if _, err := table.CrossTabWithOptions(rowCol, "y", pkg.CrossTabOptions{Totals: tt.totals}); err == nil {
+				t.Error("CrossTabWithOptions() error = nil, want a name clash error")
+			}
+		})
+	}
+
+	// Without totals a Total value is an ordinary category
+	table, err := pkg.ReadTable(strings.NewReader("x,y\nTotal,Total\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if _, err := table.CrossTab("x", "y"); err != nil {
+		t.Errorf("CrossTab() error = %v", err)
+	}
+}
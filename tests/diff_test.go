@@ -0,0 +1,101 @@
+package pkg_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestDiff(t *testing.T) {
+	oldTable, err := pkg.ReadTable(strings.NewReader("id,name,salary\n1,John,1000\n2,Jane,2000\n3,Bob,1500\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	// Columns reordered, Jane's salary changed, Bob removed and Amy added
+	newTable, err := pkg.ReadTable(strings.NewReader("name,id,salary\nJohn,1,1000\nJane,2,2500\nAmy,4,1800\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	got, err := oldTable.Diff(newTable, "id")
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	want := []pkg.RowDiff{
+		{Kind: pkg.RowChanged, Key: "2", Old: []string{"2", "Jane", "2000"}, New: []string{"Jane", "2", "2500"}, Columns: []string{"salary"}},
+		{Kind: pkg.RowRemoved, Key: "3", Old: []string{"3", "Bob", "1500"}},
+		{Kind: pkg.RowAdded, Key: "4", New: []string{"Amy", "4", "1800"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %+v, want %+v", got, want)
+	}
+
+	if diffs, err := oldTable.Diff(oldTable.Copy(), "id"); err != nil || len(diffs) != 0 {
+		t.Errorf("Diff() of a copy = %v, %v, want no differences", diffs, err)
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "name"})
+	for _, row := range [][]string{{"1", "a"}, {"1", "b"}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+	other := pkg.NewTable([]string{"id", "name"})
+
+	if _, err := table.Diff(other, "missing"); err == nil {
+		t.Error("Diff() with a missing key column should fail")
+	}
+	if _, err := table.Diff(other, "id"); err == nil || !strings.Contains(err.Error(), "duplicate key") {
+		t.Errorf("Diff() error = %v, want duplicate key error", err)
+	}
+}
+
+func TestDiffKeys(t *testing.T) {
+	oldTable, err := pkg.ReadTable(strings.NewReader("date,store,sales\n2024-01-01,1,10\n2024-01-01,2,20\n2024-01-02,1,30\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	// Store 2's sales changed, the second day removed and a third day added
+	newTable, err := pkg.ReadTable(strings.NewReader("date,store,sales,region\n2024-01-01,1,10,north\n2024-01-01,2,25,south\n2024-01-03,1,40,north\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	got, err := oldTable.DiffKeys(newTable, []string{"date", "store"})
+	if err != nil {
+		t.Fatalf("DiffKeys() error = %v", err)
+	}
+	want := []pkg.RowDiff{
+		{Kind: pkg.RowChanged, Key: "2024-01-01,2", Old: []string{"2024-01-01", "2", "20"},
+			New: []string{"2024-01-01", "2", "25", "south"}, Columns: []string{"sales"}},
+		{Kind: pkg.RowRemoved, Key: "2024-01-02,1", Old: []string{"2024-01-02", "1", "30"}},
+		{Kind: pkg.RowAdded, Key: "2024-01-03,1", New: []string{"2024-01-03", "1", "40", "north"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffKeys() = %+v, want %+v", got, want)
+	}
+
+	// The date alone is not unique
+	if _, err := oldTable.DiffKeys(newTable, []string{"date"}); err == nil || !strings.Contains(err.Error(), "duplicate key") {
+		t.Errorf("DiffKeys() error = %v, want duplicate key error", err)
+	}
+	if _, err := oldTable.DiffKeys(newTable, nil); err == nil {
+		t.Error("DiffKeys() without key columns should fail")
+	}
+	if _, err := oldTable.DiffKeys(newTable, []string{"date", "region"}); err == nil {
+		t.Error("DiffKeys() with a key column missing from one table should fail")
+	}
+
+	added, removed := oldTable.DiffHeaders(newTable)
+	if !reflect.DeepEqual(added, []string{"region"}) || removed != nil {
+		t.Errorf("DiffHeaders() = %v, %v, want [region], []", added, removed)
+	}
+	added, removed = newTable.DiffHeaders(oldTable)
+	if added != nil || !reflect.DeepEqual(removed, []string{"region"}) {
+		t.Errorf("DiffHeaders() reversed = %v, %v, want [], [region]", added, removed)
+	}
+}
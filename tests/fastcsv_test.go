@@ -1,9 +1,18 @@
 package pkg_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/ooyeku/csv_parser/pkg"
 )
@@ -82,6 +91,65 @@ func TestReadRecord(t *testing.T) {
 				{"1,1", `2"2`, "3"},
 			},
 		},
+		{
+			name:  "quoted field with embedded LF",
+			input: "a,\"line1\nline2\",c\n1,2,3",
+			cfg:   pkg.DefaultConfig(),
+			want: [][]string{
+				{"a", "line1\nline2", "c"},
+				{"1", "2", "3"},
+			},
+		},
+		{
+			name:  "quoted field with embedded CR",
+			input: "a,\"line1\rline2\",c\r1,2,3",
+			cfg:   pkg.DefaultConfig(),
+			want: [][]string{
+				{"a", "line1\rline2", "c"},
+				{"1", "2", "3"},
+			},
+		},
+		{
+			name:  "quoted field with embedded CRLF",
+			input: "a,\"line1\r\nline2\r\n\",c\r\n1,2,3\r\n",
+			cfg:   pkg.DefaultConfig(),
+			want: [][]string{
+				{"a", "line1\r\nline2\r\n", "c"},
+				{"1", "2", "3"},
+			},
+		},
+		{
+			name:  "trailing empty field at EOF",
+			input: "a,b,\n1,2,",
+			cfg:   pkg.DefaultConfig(),
+			want: [][]string{
+				{"a", "b", ""},
+				{"1", "2", ""},
+			},
+		},
+		{
+			name:  "lone empty quoted field at EOF",
+			input: `""`,
+			cfg:   pkg.DefaultConfig(),
+			want: [][]string{
+				{""},
+			},
+		},
+		{
+			name:  "empty quoted last field at EOF",
+			input: "a,\"\"\n1,\"\"",
+			cfg:   pkg.DefaultConfig(),
+			want: [][]string{
+				{"a", ""},
+				{"1", ""},
+			},
+		},
+		{
+			name:  "empty input",
+			input: "",
+			cfg:   pkg.DefaultConfig(),
+			want:  nil,
+		},
 		{
 			name:  "custom delimiter",
 			input: "a;b;c\n1;2;3",
@@ -107,6 +175,71 @@ func TestReadRecord(t *testing.T) {
 				{"1", "2", "3"},
 			},
 		},
+		{
+			name:  "comment character mid-field is data",
+			input: "# header\na,b#c,d",
+			cfg: pkg.Config{
+				Delimiter: ',',
+				Quote:     '"',
+				Comment:   '#',
+			},
+			want: [][]string{
+				{"a", "b#c", "d"},
+			},
+		},
+		{
+			name:  "comment at line start only ignores field-start comment",
+			input: "a,#b\n1,2",
+			cfg: pkg.Config{
+				Delimiter: ',',
+				Quote:     '"',
+				Comment:   '#',
+			},
+			want: [][]string{
+				{"a", "#b"},
+				{"1", "2"},
+			},
+		},
+		{
+			name:  "inline comment ends record",
+			input: "a,b,# note, more\n1,2#3,3",
+			cfg: pkg.Config{
+				Delimiter:           ',',
+				Quote:               '"',
+				Comment:             '#',
+				AllowInlineComments: true,
+			},
+			want: [][]string{
+				{"a", "b"},
+				{"1", "2#3", "3"},
+			},
+		},
+		{
+			name:  "comment as final line without newline",
+			input: "a,b\n1,2\n# trailing comment",
+			cfg: pkg.Config{
+				Delimiter: ',',
+				Quote:     '"',
+				Comment:   '#',
+			},
+			want: [][]string{
+				{"a", "b"},
+				{"1", "2"},
+			},
+		},
+		{
+			name:  "CRLF terminated comments",
+			input: "# first\r\na,b\r\n# second\r\n1,2\r\n",
+			cfg: pkg.Config{
+				Delimiter: ',',
+				Quote:     '"',
+				Comment:   '#',
+			},
+			want: [][]string{
+				{"a", "b"},
+				{"1", "2"},
+			},
+		},
 		{
 			name:  "with null values",
 			input: `a,\N,c`,
@@ -132,6 +265,44 @@ func TestReadRecord(t *testing.T) {
 				{"1", "2 ", "3"},
 			},
 		},
+		{
+			name:  "trim trailing whitespace",
+			input: "a, b ,c\n1, 2 ,3",
+			cfg: pkg.Config{
+				Delimiter:    ',',
+				Quote:        '"',
+				TrimTrailing: true,
+			},
+			want: [][]string{
+				{"a", " b", "c"},
+				{"1", " 2", "3"},
+			},
+		},
+		{
+			name:  "trim space on both sides",
+			input: "a, b ,c\n1,\t2 , 3",
+			cfg: pkg.Config{
+				Delimiter: ',',
+				Quote:     '"',
+				TrimSpace: true,
+			},
+			want: [][]string{
+				{"a", "b", "c"},
+				{"1", "2", "3"},
+			},
+		},
+		{
+			name:  "trim space preserves quoted whitespace",
+			input: `" a ", "b " ,c`,
+			cfg: pkg.Config{
+				Delimiter: ',',
+				Quote:     '"',
+				TrimSpace: true,
+			},
+			want: [][]string{
+				{" a ", "b ", "c"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -196,44 +367,1145 @@ func TestPosition(t *testing.T) {
 	}
 }
 
-func BenchmarkReadRecord(b *testing.B) {
-	input := strings.Repeat("field1,field2,field3,field4,field5\n", 1000)
-	b.ResetTimer()
+func TestQuotedNewlineRowCount(t *testing.T) {
+	input := "id,text\n1,\"first\r\nsecond\nthird\"\n2,plain\n"
+	reader, err := pkg.NewReader(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
 
-	for i := 0; i < b.N; i++ {
-		b.StopTimer()
-		reader, _ := pkg.NewReader(strings.NewReader(input), pkg.DefaultConfig())
-		b.StartTimer()
+	wantLines := []int64{1, 2, 5}
+	for i, want := range wantLines {
+		if _, err := reader.ReadRecord(); err != nil {
+			t.Fatalf("ReadRecord() error = %v", err)
+		}
+		if got := reader.CurrentRow(); got != int64(i+1) {
+			t.Errorf("CurrentRow() = %d, want %d", got, i+1)
+		}
+		if got := reader.CurrentLine(); got != want {
+			t.Errorf("CurrentLine() = %d, want %d", got, want)
+		}
+	}
+	if _, err := reader.ReadRecord(); err != io.EOF {
+		t.Errorf("ReadRecord() error = %v, want io.EOF", err)
+	}
+}
 
-		for {
-			_, err := reader.ReadRecord()
-			if err == io.EOF {
-				break
+func TestQuotedNewlineRoundTrip(t *testing.T) {
+	want := [][]string{
+		{"id", "text"},
+		{"1", "line1\nline2"},
+		{"2", "line1\rline2"},
+		{"3", "line1\r\nline2\r\n"},
+		{"4", "\"quoted\"\nand, comma"},
+	}
+
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	if err := w.WriteAll(want); err != nil {
+		t.Fatalf("csv.Writer error = %v", err)
+	}
+
+	reader, err := pkg.NewReader(strings.NewReader(sb.String()), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	var got [][]string
+	for {
+		record, err := reader.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadRecord() error = %v", err)
+		}
+		got = append(got, record)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %q, want %q", got, want)
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	input := "a,b,c\n1,2,3\n4,5,6\n7,8,9\n10,11,\"unterminated\n"
+	reader, err := pkg.NewReader(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	for {
+		_, err = reader.ReadRecord()
+		if err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, pkg.ErrUnterminatedQuote) {
+		t.Fatalf("ReadRecord() error = %v, want ErrUnterminatedQuote", err)
+	}
+	if got := reader.CurrentRow(); got != 5 {
+		t.Errorf("CurrentRow() = %d, want 5", got)
+	}
+	if got := reader.CurrentColumn(); got != 3 {
+		t.Errorf("CurrentColumn() = %d, want 3", got)
+	}
+	if !strings.Contains(err.Error(), "row 5, column 3") {
+		t.Errorf("error %q should contain the row and column", err)
+	}
+	rowStart := int64(strings.Index(input, "10,"))
+	if got := reader.BytesRead(); got <= rowStart || got > int64(len(input)) {
+		t.Errorf("BytesRead() = %d, want offset within row 5 (%d..%d]", got, rowStart, len(input))
+	}
+}
+
+func TestFieldCountErrorPosition(t *testing.T) {
+	input := "a,b,c\n# comment\n1,2,3\n4,5\n"
+	cfg := pkg.DefaultConfig()
+	cfg.Comment = '#'
+	_, err := pkg.ReadTable(strings.NewReader(input), cfg)
+	if !errors.Is(err, pkg.ErrFieldCount) {
+		t.Fatalf("ReadTable() error = %v, want ErrFieldCount", err)
+	}
+	if !strings.Contains(err.Error(), "row 3, column 2 (line 4,") {
+		t.Errorf("error %q should report row 3, column 2 on line 4", err)
+	}
+}
+
+func TestReuseRecord(t *testing.T) {
+	input := "a,b,c\n1,2,3\n"
+
+	cfg := pkg.DefaultConfig()
+	cfg.ReuseRecord = true
+	reader, err := pkg.NewReader(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	first, err := reader.ReadRecord()
+	if err != nil {
+		t.Fatalf("ReadRecord() error = %v", err)
+	}
+	second, err := reader.ReadRecord()
+	if err != nil {
+		t.Fatalf("ReadRecord() error = %v", err)
+	}
+	if &first[0] != &second[0] {
+		t.Error("ReuseRecord: records should share the same backing array")
+	}
+	if first[0] != "1" {
+		t.Errorf("ReuseRecord: first record overwritten = %v, want %v", first, second)
+	}
+
+	reader, err = pkg.NewReader(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	first, _ = reader.ReadRecord()
+	second, _ = reader.ReadRecord()
+	if &first[0] == &second[0] {
+		t.Error("without ReuseRecord each record should be a fresh slice")
+	}
+	if first[0] != "a" || second[0] != "1" {
+		t.Errorf("records = %v, %v, want [a b c], [1 2 3]", first, second)
+	}
+}
+
+func TestRaggedRecords(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		trim     bool
+		pad      bool
+		wantRows [][]string
+		wantErr  bool
+	}{
+		{
+			name:    "trailing comma fails by default",
+			input:   "a,b,c\n1,2,3,\n",
+			wantErr: true,
+		},
+		{
+			name:     "trailing comma dropped",
+			input:    "a,b,c\n1,2,3,\n4,5,\n",
+			trim:     true,
+			wantRows: [][]string{{"1", "2", "3"}, {"4", "5", ""}},
+		},
+		{
+			name:     "trailing comma on header and rows",
+			input:    "a,b,c,\n1,2,3,\n",
+			trim:     true,
+			wantRows: [][]string{{"1", "2", "3"}},
+		},
+		{
+			name:    "trailing comma not dropped with padding only",
+			input:   "a,b,c\n1,2,3,\n",
+			pad:     true,
+			wantErr: true,
+		},
+		{
+			name:    "short row fails by default",
+			input:   "a,b,c\n1,2\n",
+			wantErr: true,
+		},
+		{
+			name:     "short row padded",
+			input:    "a,b,c\n1,2\n4,5,6\n7\n",
+			pad:      true,
+			wantRows: [][]string{{"1", "2", ""}, {"4", "5", "6"}, {"7", "", ""}},
+		},
+		{
+			name:    "short row not padded with trimming only",
+			input:   "a,b,c\n1,2\n",
+			trim:    true,
+			wantErr: true,
+		},
+		{
+			name:     "both options",
+			input:    "a,b,c\n1,2,3,\n4\n",
+			trim:     true,
+			pad:      true,
+			wantRows: [][]string{{"1", "2", "3"}, {"4", "", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := pkg.DefaultConfig()
+			cfg.TrimTrailingEmptyField = tt.trim
+			cfg.PadShortRecords = tt.pad
+
+			table, err := pkg.ReadTable(strings.NewReader(tt.input), cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadTable() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if err != nil {
-				b.Fatal(err)
+			if tt.wantErr {
+				if !errors.Is(err, pkg.ErrFieldCount) {
+					t.Errorf("ReadTable() error = %v, want ErrFieldCount", err)
+				}
+				return
 			}
-		}
+			if len(table.Headers) != 3 {
+				t.Errorf("ReadTable() headers = %v, want 3 columns", table.Headers)
+			}
+			if !reflect.DeepEqual(table.Rows, tt.wantRows) {
+				t.Errorf("ReadTable() rows = %q, want %q", table.Rows, tt.wantRows)
+			}
+		})
 	}
 }
 
-func BenchmarkReadRecordWithQuotes(b *testing.B) {
-	input := strings.Repeat(`"field,1","field,2","field,3","field,4","field,5"`+"\n", 1000)
-	b.ResetTimer()
+func TestOnRagged(t *testing.T) {
+	// One short row and one long row
+	input := "a,b,c\n1,2,3\n4,5\n6,7,8,9\n10,11,12\n"
 
-	for i := 0; i < b.N; i++ {
-		b.StopTimer()
-		reader, _ := pkg.NewReader(strings.NewReader(input), pkg.DefaultConfig())
-		b.StartTimer()
+	tests := []struct {
+		name       string
+		policy     pkg.RaggedPolicy
+		wantRows   [][]string
+		wantRagged int64
+		wantErr    bool
+	}{
+		{name: "error", policy: pkg.RaggedError, wantErr: true},
+		{name: "pad", policy: pkg.RaggedPad, wantErr: true}, // the long row still fails
+		{
+			name:       "truncate",
+			policy:     pkg.RaggedTruncate,
+			wantRows:   [][]string{{"1", "2", "3"}, {"4", "5", ""}, {"6", "7", "8"}, {"10", "11", "12"}},
+			wantRagged: 2,
+		},
+		{
+			name:       "skip",
+			policy:     pkg.RaggedSkip,
+			wantRows:   [][]string{{"1", "2", "3"}, {"10", "11", "12"}},
+			wantRagged: 2,
+		},
+	}
 
-		for {
-			_, err := reader.ReadRecord()
-			if err == io.EOF {
-				break
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := pkg.DefaultConfig()
+			cfg.OnRagged = tt.policy
+			selected, err := pkg.ReadSelection(strings.NewReader(input), cfg, pkg.SelectOptions{Tail: 10})
+			if tt.wantErr {
+				if !errors.Is(err, pkg.ErrFieldCount) {
+					t.Errorf("ReadSelection() error = %v, want ErrFieldCount", err)
+				}
+			} else if err != nil {
+				t.Errorf("ReadSelection() error = %v", err)
+			} else if !reflect.DeepEqual(selected.Rows, tt.wantRows) {
+				t.Errorf("ReadSelection() rows = %v, want %v", selected.Rows, tt.wantRows)
 			}
+
+			reader, err := pkg.NewReader(strings.NewReader(input), cfg)
 			if err != nil {
-				b.Fatal(err)
+				t.Fatalf("NewReader() error = %v", err)
 			}
-		}
+			table, err := reader.ToTable()
+			if tt.wantErr {
+				if !errors.Is(err, pkg.ErrFieldCount) {
+					t.Errorf("ToTable() error = %v, want ErrFieldCount", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToTable() error = %v", err)
+			}
+			if !reflect.DeepEqual(table.Rows, tt.wantRows) {
+				t.Errorf("ToTable() rows = %v, want %v", table.Rows, tt.wantRows)
+			}
+			if got := reader.RaggedRows(); got != tt.wantRagged {
+				t.Errorf("RaggedRows() = %d, want %d", got, tt.wantRagged)
+			}
+		})
+	}
+
+	// Padding alone handles a file whose only ragged row is short
+	cfg := pkg.DefaultConfig()
+	cfg.OnRagged = pkg.RaggedPad
+	table, err := pkg.ReadTable(strings.NewReader("a,b,c\n1,2\n"), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if !reflect.DeepEqual(table.Rows, [][]string{{"1", "2", ""}}) {
+		t.Errorf("ReadTable() rows = %v, want [[1 2 ]]", table.Rows)
+	}
+
+	// The streaming readers follow the same policy
+	cfg.OnRagged = pkg.RaggedSkip
+	sample, err := pkg.ReservoirSample(strings.NewReader(input), cfg, 10, 1)
+	if err != nil {
+		t.Fatalf("ReservoirSample() error = %v", err)
+	}
+	if len(sample.Rows) != 2 {
+		t.Errorf("ReservoirSample() kept %d rows, want 2", len(sample.Rows))
+	}
+}
+
+func TestSkipRowsAndHeaderRows(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		skipRows    int
+		headerRows  int
+		wantHeaders []string
+		wantRows    [][]string
+	}{
+		{
+			name:        "preamble line",
+			input:       "Sales report, \"Q1\nid,amount\n1,10\n2,20\n",
+			skipRows:    1,
+			wantHeaders: []string{"id", "amount"},
+			wantRows:    [][]string{{"1", "10"}, {"2", "20"}},
+		},
+		{
+			name:        "two-line header",
+			input:       "id,price,weight\n,USD,kg\n1,9.5,2\n",
+			headerRows:  2,
+			wantHeaders: []string{"id", "price_USD", "weight_kg"},
+			wantRows:    [][]string{{"1", "9.5", "2"}},
+		},
+		{
+			name:        "both",
+			input:       "exported 2024-01-31\r\nname,temp\r\n,C\r\nOslo,-3\r\n",
+			skipRows:    1,
+			headerRows:  2,
+			wantHeaders: []string{"name", "temp_C"},
+			wantRows:    [][]string{{"Oslo", "-3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := pkg.DefaultConfig()
+			cfg.SkipRows = tt.skipRows
+			cfg.HeaderRows = tt.headerRows
+			table, err := pkg.ReadTable(strings.NewReader(tt.input), cfg)
+			if err != nil {
+				t.Fatalf("ReadTable() error = %v", err)
+			}
+			if !reflect.DeepEqual(table.Headers, tt.wantHeaders) {
+				t.Errorf("headers = %q, want %q", table.Headers, tt.wantHeaders)
+			}
+			if !reflect.DeepEqual(table.Rows, tt.wantRows) {
+				t.Errorf("rows = %q, want %q", table.Rows, tt.wantRows)
+			}
+		})
+	}
+
+	// Column types ignore the unit row
+	cfg := pkg.DefaultConfig()
+	cfg.HeaderRows = 2
+	table, err := pkg.ReadTable(strings.NewReader("id,price\n,USD\n1,9.5\n"), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if typ, _ := table.GetColumnType("price_USD"); typ != pkg.TypeFloat {
+		t.Errorf("price_USD type = %v, want float", typ)
+	}
+}
+
+func TestBufferSize(t *testing.T) {
+	for _, tt := range []struct {
+		size, want int
+	}{
+		{0, 64 * 1024},
+		{1024, 1024},
+		{1, 16}, // raised to the minimum
+	} {
+		cfg := pkg.DefaultConfig()
+		cfg.BufferSize = tt.size
+		reader, err := pkg.NewReader(strings.NewReader("a\n"), cfg)
+		if err != nil {
+			t.Fatalf("NewReader() error = %v", err)
+		}
+		if got := reader.BufferSize(); got != tt.want {
+			t.Errorf("BufferSize %d: BufferSize() = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+
+	// A tiny buffer parses quotes, doubled quotes, line breaks in quotes and
+	// fields longer than the buffer like the default one
+	input := "id,text,note\r\n" +
+		"1,\"say \"\"hi\"\"\",\"" + strings.Repeat("x", 100) + "\"\r\n" +
+		"2,\"line one\r\nline two\",\"\"\r\n" +
+		"3,plain," + strings.Repeat("y", 50) + "\n"
+	want, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	cfg := pkg.DefaultConfig()
+	cfg.BufferSize = 16
+	got, err := pkg.ReadTable(iotest.OneByteReader(strings.NewReader(input)), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() with a 16-byte buffer error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Headers, want.Headers) || !reflect.DeepEqual(got.Rows, want.Rows) {
+		t.Errorf("16-byte buffer read %q, want %q", got.Rows, want.Rows)
+	}
+	if len(got.Rows) != 3 || got.Rows[1][1] != "line one\r\nline two" || got.Rows[0][1] != `say "hi"` {
+		t.Errorf("rows = %q, want the quoted fields unescaped", got.Rows)
+	}
+}
+
+func BenchmarkReadRecord(b *testing.B) {
+	input := strings.Repeat("field1,field2,field3,field4,field5\n", 1000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		reader, _ := pkg.NewReader(strings.NewReader(input), pkg.DefaultConfig())
+		b.StartTimer()
+
+		for {
+			_, err := reader.ReadRecord()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkReadRecordWithQuotes(b *testing.B) {
+	input := strings.Repeat(`"field,1","field,2","field,3","field,4","field,5"`+"\n", 1000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		reader, _ := pkg.NewReader(strings.NewReader(input), pkg.DefaultConfig())
+		b.StartTimer()
+
+		for {
+			_, err := reader.ReadRecord()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestReadTableWithProgress(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("id,name,value\n")
+	for i := 0; i < 50000; i++ {
+		fmt.Fprintf(&sb, "%d,name %d,%d\n", i, i, i*7)
+	}
+	input := sb.String()
+
+	type call struct{ bytes, rows int64 }
+	var calls []call
+	table, err := pkg.ReadTableWithProgress(strings.NewReader(input), pkg.DefaultConfig(), func(bytesRead, rowsRead int64) {
+		calls = append(calls, call{bytesRead, rowsRead})
+	})
+	if err != nil {
+		t.Fatalf("ReadTableWithProgress() error = %v", err)
+	}
+	if len(table.Rows) != 50000 {
+		t.Fatalf("got %d rows, want 50000", len(table.Rows))
+	}
+
+	// Often enough to drive a progress bar, rarely enough to stay cheap
+	if len(calls) < 5 || len(calls) > 100 {
+		t.Errorf("progress called %d times, want between 5 and 100", len(calls))
+	}
+	for i := 1; i < len(calls); i++ {
+		if calls[i].bytes <= calls[i-1].bytes || calls[i].rows <= calls[i-1].rows {
+			t.Fatalf("progress call %d = %+v, not increasing from %+v", i, calls[i], calls[i-1])
+		}
+	}
+	last := calls[len(calls)-1]
+	if last.bytes != int64(len(input)) || last.rows != 50000 {
+		t.Errorf("final progress = %+v, want {bytes:%d rows:50000}", last, len(input))
+	}
+}
+
+// countingReader records how many bytes have been read from it
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestReadTableN(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("id,name\n")
+	for i := 0; i < 100000; i++ {
+		fmt.Fprintf(&sb, "%d,name %d\n", i, i)
+	}
+	input := sb.String()
+
+	tests := []struct {
+		name     string
+		input    string
+		maxRows  int
+		wantRows int
+	}{
+		{"longer file", input, 5, 5},
+		{"shorter file", "id,name\n1,a\n2,b\n", 5, 2},
+		{"zero rows", input, 0, 0},
+		{"negative reads all", "id,name\n1,a\n2,b\n", -1, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &countingReader{r: strings.NewReader(tt.input)}
+			table, err := pkg.ReadTableN(cr, pkg.DefaultConfig(), tt.maxRows)
+			if err != nil {
+				t.Fatalf("ReadTableN() error = %v", err)
+			}
+			if len(table.Rows) != tt.wantRows {
+				t.Errorf("got %d rows, want %d", len(table.Rows), tt.wantRows)
+			}
+			if !reflect.DeepEqual(table.Headers, []string{"id", "name"}) {
+				t.Errorf("headers = %v, want [id name]", table.Headers)
+			}
+			if tt.input == input && cr.n >= len(input) {
+				t.Errorf("read all %d bytes, want ReadTableN to stop early", cr.n)
+			}
+		})
+	}
+
+	t.Run("reader resumes after limit", func(t *testing.T) {
+		reader, err := pkg.NewReader(strings.NewReader("id,name\n1,a\n2,b\n3,c\n"), pkg.DefaultConfig())
+		if err != nil {
+			t.Fatalf("NewReader() error = %v", err)
+		}
+		table, err := reader.ToTableN(2)
+		if err != nil {
+			t.Fatalf("ToTableN() error = %v", err)
+		}
+		if len(table.Rows) != 2 {
+			t.Fatalf("got %d rows, want 2", len(table.Rows))
+		}
+		record, err := reader.ReadRecord()
+		if err != nil || !reflect.DeepEqual(record, []string{"3", "c"}) {
+			t.Errorf("next record = %v, %v, want [3 c]", record, err)
+		}
+	})
+}
+
+func TestDuplicateHeaders(t *testing.T) {
+	input := "id,value,value,value_2,value\n1,a,b,c,d\n"
+
+	tests := []struct {
+		name        string
+		policy      pkg.DuplicateHeaderPolicy
+		wantHeaders []string
+		wantErr     bool
+	}{
+		{"allow", pkg.DuplicateHeadersAllow, []string{"id", "value", "value", "value_2", "value"}, false},
+		{"error", pkg.DuplicateHeadersError, nil, true},
+		{"rename", pkg.DuplicateHeadersRename, []string{"id", "value", "value_3", "value_2", "value_4"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := pkg.DefaultConfig()
+			cfg.DuplicateHeaders = tt.policy
+			table, err := pkg.ReadTable(strings.NewReader(input), cfg)
+			if tt.wantErr {
+				if !errors.Is(err, pkg.ErrDuplicateHeader) {
+					t.Fatalf("ReadTable() error = %v, want ErrDuplicateHeader", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadTable() error = %v", err)
+			}
+			if !reflect.DeepEqual(table.Headers, tt.wantHeaders) {
+				t.Errorf("headers = %v, want %v", table.Headers, tt.wantHeaders)
+			}
+			for i, h := range table.Headers {
+				col, err := table.GetColumn(h)
+				if err != nil {
+					t.Fatalf("GetColumn(%q) error = %v", h, err)
+				}
+				if tt.policy == pkg.DuplicateHeadersRename && col[0] != table.Rows[0][i] {
+					t.Errorf("GetColumn(%q) = %v, want [%s]", h, col, table.Rows[0][i])
+				}
+			}
+		})
+	}
+}
+
+func TestHasDuplicateHeaders(t *testing.T) {
+	table := pkg.NewTable([]string{"b", "a", "b", "a", "b", "c"})
+	if got, want := table.HasDuplicateHeaders(), []string{"b", "a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HasDuplicateHeaders() = %v, want %v", got, want)
+	}
+	if got := pkg.NewTable([]string{"a", "b"}).HasDuplicateHeaders(); got != nil {
+		t.Errorf("HasDuplicateHeaders() = %v, want nil", got)
+	}
+}
+
+func TestNoHeader(t *testing.T) {
+	input := "1,alice,3.5\n2,bob,4\n3,carol,\n"
+	cfg := pkg.DefaultConfig()
+	cfg.NoHeader = true
+
+	table, err := pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if want := []string{"col1", "col2", "col3"}; !reflect.DeepEqual(table.Headers, want) {
+		t.Errorf("headers = %v, want %v", table.Headers, want)
+	}
+	if len(table.Rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(table.Rows))
+	}
+	col, err := table.GetColumn("col2")
+	if err != nil || !reflect.DeepEqual(col, []string{"alice", "bob", "carol"}) {
+		t.Errorf("GetColumn(col2) = %v, %v, want [alice bob carol]", col, err)
+	}
+	if typ, _ := table.GetColumnType("col1"); typ != pkg.TypeInteger {
+		t.Errorf("col1 type = %v, want TypeInteger", typ)
+	}
+	if typ, _ := table.GetColumnType("col3"); typ != pkg.TypeFloat {
+		t.Errorf("col3 type = %v, want TypeFloat", typ)
+	}
+
+	head, err := pkg.ReadTableN(strings.NewReader(input), cfg, 1)
+	if err != nil {
+		t.Fatalf("ReadTableN() error = %v", err)
+	}
+	if !reflect.DeepEqual(head.Rows, [][]string{{"1", "alice", "3.5"}}) {
+		t.Errorf("ReadTableN() rows = %v, want the first record", head.Rows)
+	}
+}
+
+func TestStrictRFC4180(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantLenient [][]string
+		wantErr     error
+		wantPos     string
+	}{
+		{
+			name:        "quote inside unquoted field",
+			input:       "a,b\n1,5\"10\n",
+			wantLenient: [][]string{{"a", "b"}, {"1", "5\"10"}},
+			wantErr:     pkg.ErrBareQuote,
+			wantPos:     "row 2, column 2",
+		},
+		{
+			name:        "data after closing quote",
+			input:       "a,b\n\"x\"y,2\n",
+			wantLenient: [][]string{{"a", "b"}, {"xy", "2"}},
+			wantErr:     pkg.ErrTrailingQuote,
+			wantPos:     "row 2, column 1",
+		},
+		{
+			name:        "space before opening quote",
+			input:       "a,b\n1, \"2\"\n",
+			wantLenient: [][]string{{"a", "b"}, {"1", " \"2\""}},
+			wantErr:     pkg.ErrBareQuote,
+			wantPos:     "row 2, column 2",
+		},
+		{
+			name:        "inconsistent field count",
+			input:       "a,b\n1,2\n3\n",
+			wantLenient: [][]string{{"a", "b"}, {"1", "2"}, {"3"}},
+			wantErr:     pkg.ErrFieldCount,
+			wantPos:     "row 3, column 1",
+		},
+	}
+
+	readAll := func(input string, strict bool) ([][]string, error) {
+		cfg := pkg.DefaultConfig()
+		cfg.StrictRFC4180 = strict
+		reader, err := pkg.NewReader(strings.NewReader(input), cfg)
+		if err != nil {
+			return nil, err
+		}
+		var records [][]string
+		for {
+			record, err := reader.ReadRecord()
+			if err == io.EOF {
+				return records, nil
+			}
+			if err != nil {
+				return records, err
+			}
+			records = append(records, record)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readAll(tt.input, false)
+			if err != nil {
+				t.Fatalf("lenient ReadRecord() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.wantLenient) {
+				t.Errorf("lenient records = %q, want %q", got, tt.wantLenient)
+			}
+
+			_, err = readAll(tt.input, true)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("strict ReadRecord() error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantPos) {
+				t.Errorf("strict error %q does not report %q", err, tt.wantPos)
+			}
+		})
+	}
+
+	t.Run("valid input", func(t *testing.T) {
+		input := "a,b\r\n\"x, \"\"y\"\"\",\"multi\nline\"\r\n,\"\"\r\n"
+		got, err := readAll(input, true)
+		if err != nil {
+			t.Fatalf("strict ReadRecord() error = %v", err)
+		}
+		want := [][]string{{"a", "b"}, {"x, \"y\"", "multi\nline"}, {"", ""}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("strict records = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestGzipInput(t *testing.T) {
+	plain := "name,city\nJohn,\"New York\"\nJane,Boston\n"
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write([]byte(plain)); err != nil {
+		t.Fatalf("gzip write error = %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip close error = %v", err)
+	}
+
+	want, err := pkg.ReadTable(strings.NewReader(plain), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable(plain) error = %v", err)
+	}
+
+	got, err := pkg.ReadTable(bytes.NewReader(compressed.Bytes()), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable(gzip) error = %v", err)
+	}
+	if !got.Equal(want) {
+		t.Errorf("ReadTable(gzip) = %v, want %v", got.Rows, want.Rows)
+	}
+
+	data := compressed.Bytes()
+	got, err = pkg.ReadTableParallel(bytes.NewReader(data), int64(len(data)), pkg.DefaultConfig(), 4)
+	if err != nil {
+		t.Fatalf("ReadTableParallel(gzip) error = %v", err)
+	}
+	if !got.Equal(want) {
+		t.Errorf("ReadTableParallel(gzip) = %v, want %v", got.Rows, want.Rows)
+	}
+
+	// OpenMaybeCompressed sniffs the header, so the extension does not matter
+	dir := t.TempDir()
+	for name, content := range map[string][]byte{
+		"data.csv.gz": data,
+		"data.bin":    data,
+		"data.csv":    []byte(plain),
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		rc, err := pkg.OpenMaybeCompressed(path)
+		if err != nil {
+			t.Fatalf("OpenMaybeCompressed(%s) error = %v", name, err)
+		}
+		cfg := pkg.DefaultConfig()
+		cfg.AutoDecompress = false
+		got, err := pkg.ReadTable(rc, cfg)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("ReadTable(%s) error = %v", name, err)
+		}
+		if !got.Equal(want) {
+			t.Errorf("ReadTable(%s) = %v, want %v", name, got.Rows, want.Rows)
+		}
+	}
+}
+
+func TestLineEndingStats(t *testing.T) {
+	// The quoted field's "\r\n" counts too, as does the lone "\r" at EOF
+	input := "a,b\n1,\"x\r\ny\"\r\n2,z\r3,w\n4,v\r"
+	reader, err := pkg.NewReader(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	records := 0
+	for {
+		_, err := reader.ReadRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ReadRecord() error = %v", err)
+		}
+		records++
+	}
+	if records != 5 {
+		t.Errorf("read %d records, want 5", records)
+	}
+
+	lf, crlf, cr := reader.LineEndingStats()
+	if lf != 2 || crlf != 2 || cr != 2 {
+		t.Errorf("LineEndingStats() = %d, %d, %d, want 2, 2, 2", lf, crlf, cr)
+	}
+	if !pkg.MixedLineEndings(lf, crlf, cr) {
+		t.Error("MixedLineEndings() = false, want true")
+	}
+	if pkg.MixedLineEndings(3, 0, 0) {
+		t.Error("MixedLineEndings(3, 0, 0) = true, want false")
+	}
+}
+
+func TestWhitespaceDelimited(t *testing.T) {
+	input := "  name    city\t\tage  \n" +
+		"John   Boston 30\n" +
+		"\n" +
+		"Jane \"New York\"\t\t25\r\n" +
+		"   \n" +
+		"Bob \"\" 41"
+	cfg := pkg.DefaultConfig()
+	cfg.WhitespaceDelimited = true
+	table, err := pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if got := strings.Join(table.Headers, "|"); got != "name|city|age" {
+		t.Errorf("headers = %s, want name|city|age", got)
+	}
+	want := [][]string{{"John", "Boston", "30"}, {"Jane", "New York", "25"}, {"Bob", "", "41"}}
+	if !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf("rows = %q, want %q", table.Rows, want)
+	}
+}
+
+func TestFixedWidths(t *testing.T) {
+	input := "NAME      CITY        AGE\n" +
+		"John      Boston      30\n" +
+		"# a comment\n" +
+		"\n" +
+		"Jane Doe  New York    25   trailing\r\n" +
+		"Bob       Chicago"
+	cfg := pkg.DefaultConfig()
+	cfg.FixedWidths = []int{10, 12, 3}
+	cfg.Comment = '#'
+	table, err := pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if got := strings.Join(table.Headers, "|"); got != "NAME|CITY|AGE" {
+		t.Errorf("headers = %s, want NAME|CITY|AGE", got)
+	}
+	want := [][]string{{"John", "Boston", "30"}, {"Jane Doe", "New York", "25"}, {"Bob", "Chicago", ""}}
+	if !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf("rows = %q, want %q", table.Rows, want)
+	}
+
+	cfg.FixedWidths = []int{4, 0}
+	if _, err := pkg.NewReader(strings.NewReader(input), cfg); err == nil {
+		t.Error("NewReader() with a zero width: expected an error")
+	}
+	cfg.FixedWidths = []int{4}
+	cfg.WhitespaceDelimited = true
+	if _, err := pkg.NewReader(strings.NewReader(input), cfg); err == nil {
+		t.Error("NewReader() with both modes: expected an error")
+	}
+}
+
+func TestRawLine(t *testing.T) {
+	input := "id,name,note\r\n" +
+		"# skipped\n" +
+		"1,\"Smith, John\",  \"said \"\"hi\"\"\"\n" +
+		"2,\"multi\nline\",x"
+	cfg := pkg.DefaultConfig()
+	cfg.Comment = '#'
+	cfg.TrimSpace = true
+	cfg.KeepRawLine = true
+	reader, err := pkg.NewReader(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	want := []struct {
+		fields []string
+		raw    string
+	}{
+		{[]string{"id", "name", "note"}, "id,name,note"},
+		{[]string{"1", "Smith, John", `said "hi"`}, `1,"Smith, John",  "said ""hi"""`},
+		{[]string{"2", "multi\nline", "x"}, "2,\"multi\nline\",x"},
+	}
+	for i, w := range want {
+		record, err := reader.ReadRecord()
+		if err != nil {
+			t.Fatalf("ReadRecord() %d error = %v", i, err)
+		}
+		if !reflect.DeepEqual(record, w.fields) {
+			t.Errorf("record %d = %q, want %q", i, record, w.fields)
+		}
+		if got := reader.RawLine(); got != w.raw {
+			t.Errorf("RawLine() %d = %q, want %q", i, got, w.raw)
+		}
+	}
+
+	// Without the option nothing is kept
+	reader, err = pkg.NewReader(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if _, err := reader.ReadRecord(); err != nil {
+		t.Fatalf("ReadRecord() error = %v", err)
+	}
+	if got := reader.RawLine(); got != "" {
+		t.Errorf("RawLine() without KeepRawLine = %q, want empty", got)
+	}
+}
+
+func TestTSV(t *testing.T) {
+	input := "name\tcity\tnote\nJohn\tNew York\tlikes, commas\nJane\tBoston\t\"tab\there\"\n"
+	want := [][]string{{"John", "New York", "likes, commas"}, {"Jane", "Boston", "tab\there"}}
+
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.TSVConfig())
+	if err != nil {
+		t.Fatalf("ReadTable(TSVConfig) error = %v", err)
+	}
+	if !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf("TSVConfig rows = %q, want %q", table.Rows, want)
+	}
+
+	// A delimiter typed as a backslash escape reads the same way
+	delim, err := pkg.ParseDelimiter(`\t`)
+	if err != nil {
+		t.Fatalf("ParseDelimiter() error = %v", err)
+	}
+	cfg := pkg.DefaultConfig()
+	cfg.Delimiter = delim
+	table, err = pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable(\\t) error = %v", err)
+	}
+	if !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf(`\t delimiter rows = %q, want %q`, table.Rows, want)
+	}
+}
+
+func TestParseDelimiter(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    rune
+		wantErr bool
+	}{
+		{",", ',', false},
+		{";", ';', false},
+		{"\t", '\t', false},
+		{`\t`, '\t', false},
+		{"tab", '\t', false},
+		{`\\`, '\\', false},
+		{"|", '|', false},
+		{"", 0, true},
+		{"ab", 0, true},
+		{";;", 0, true},
+		{"§", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := pkg.ParseDelimiter(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDelimiter(%q) = %q, %v, want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseQuote(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    rune
+		wantErr bool
+	}{
+		{`"`, '"', false},
+		{"'", '\'', false},
+		{"", 0, false}, // quoting disabled
+		{`""`, 0, true},
+		{"«", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := pkg.ParseQuote(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseQuote(%q) = %q, %v, want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestQuotingDisabled(t *testing.T) {
+	cfg := pkg.DefaultConfig()
+	cfg.Quote = 0
+	input := "name,note\nJohn,\"a, b\"\nJane,\"\"\x00\n"
+	reader, err := pkg.NewReader(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	want := [][]string{{"name", "note"}, {"John", `"a`, ` b"`}, {"Jane", "\"\"\x00"}}
+	for i, w := range want {
+		record, err := reader.ReadRecord()
+		if err != nil {
+			t.Fatalf("ReadRecord() %d error = %v", i, err)
+		}
+		if !reflect.DeepEqual(record, w) {
+			t.Errorf("record %d = %q, want %q", i, record, w)
+		}
+	}
+}
+
+func TestRawFields(t *testing.T) {
+	input := "id,name,note\n" +
+		"1,\"  spaced  \",NULL\n" +
+		"2,  \"said \"\"hi\"\"\" , \"a,b\"\n" +
+		"3,\"multi\nline\",\n"
+	cfg := pkg.DefaultConfig()
+	cfg.TrimSpace = true
+	cfg.Null = "NULL"
+	cfg.RawFields = true
+	reader, err := pkg.NewReader(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	want := [][]string{
+		{"id", "name", "note"},
+		{"1", `"  spaced  "`, "NULL"},
+		// Records split as without RawFields, keeping the skipped whitespace
+		{"2", `  "said ""hi""" `, ` "a,b"`},
+		{"3", "\"multi\nline\"", ""},
+	}
+	for i, w := range want {
+		record, err := reader.ReadRecord()
+		if err != nil {
+			t.Fatalf("ReadRecord() %d error = %v", i, err)
+		}
+		if !reflect.DeepEqual(record, w) {
+			t.Errorf("record %d = %q, want %q", i, record, w)
+		}
+	}
+	if _, err := reader.ReadRecord(); err != io.EOF {
+		t.Errorf("ReadRecord() at end error = %v, want io.EOF", err)
+	}
+}
+
+func TestReaderFieldCounters(t *testing.T) {
+	// A short and a long row, with a comment that is not a record
+	path := filepath.Join(t.TempDir(), "ragged.csv")
+	input := "a,b,c\n1,2,3\n# note\n4,5\n6,7,8,9\n10,11,12\n"
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	cfg := pkg.DefaultConfig()
+	cfg.Comment = '#'
+	cfg.ReuseRecord = true
+	reader, err := pkg.NewReader(file, cfg)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if lo, hi := reader.FieldCountRange(); lo != 0 || hi != 0 || reader.RecordsRead() != 0 {
+		t.Errorf("counters before reading = %d, %d, %d records, want zeros", lo, hi, reader.RecordsRead())
+	}
+	for {
+		if _, err := reader.ReadRecord(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("ReadRecord() error = %v", err)
+		}
+	}
+
+	if got := reader.RecordsRead(); got != 5 {
+		t.Errorf("RecordsRead() = %d, want 5", got)
+	}
+	if got := reader.FieldsRead(); got != 15 {
+		t.Errorf("FieldsRead() = %d, want 15", got)
+	}
+	if lo, hi := reader.FieldCountRange(); lo != 2 || hi != 4 {
+		t.Errorf("FieldCountRange() = %d, %d, want 2, 4", lo, hi)
+	}
+
+	// A rectangular file has the same minimum and maximum
+	reader, err = pkg.NewReader(strings.NewReader("a,b\n1,2\n3,4\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if _, err := reader.ToTable(); err != nil {
+		t.Fatalf("ToTable() error = %v", err)
+	}
+	if lo, hi := reader.FieldCountRange(); lo != 2 || hi != 2 || reader.RecordsRead() != 3 {
+		t.Errorf("FieldCountRange() = %d, %d with %d records, want 2, 2 with 3", lo, hi, reader.RecordsRead())
+	}
+
+	// A header combined from several rows counts as one record
+	cfg = pkg.DefaultConfig()
+	cfg.HeaderRows = 3
+	reader, err = pkg.NewReader(strings.NewReader("id,price\n,USD\n,net\n1,9.5\n2,3\n"), cfg)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if _, err := reader.ToTable(); err != nil {
+		t.Fatalf("ToTable() error = %v", err)
+	}
+	if got := reader.RecordsRead(); got != 3 {
+		t.Errorf("RecordsRead() with HeaderRows = %d, want 3", got)
+	}
+	if got := reader.FieldsRead(); got != 6 {
+		t.Errorf("FieldsRead() with HeaderRows = %d, want 6", got)
 	}
 }
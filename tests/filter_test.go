@@ -0,0 +1,224 @@
+package pkg_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestFilterExpr(t *testing.T) {
+	table := pkg.NewTable([]string{"name", "age", "department"})
+	rows := [][]string{
+		{"John", "25", "IT"},
+		{"Jane", "35", "IT"},
+		{"Bob", "45", "HR"},
+		{"Amy Lee", "9", "Sales Ops"},
+	}
+	for _, row := range rows {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		expr      string
+		wantNames []string
+		wantErr   bool
+	}{
+		{"numeric comparison", "age > 30", []string{"Jane", "Bob"}, false},
+		{"numeric not lexical", "age < 10", []string{"Amy Lee"}, false},
+		{"compound and", `age > 30 AND department = "IT"`, []string{"Jane"}, false},
+		{"or binds looser than and", `department = HR or age <= 25 and department == 'IT'`, []string{"John", "Bob"}, false},
+		{"parentheses", `(department = HR OR department = IT) AND age >= 35`, []string{"Jane", "Bob"}, false},
+		{"quoted value with spaces", `department = "Sales Ops"`, []string{"Amy Lee"}, false},
+		{"string ordering", `name < "C"`, []string{"Bob", "Amy Lee"}, false},
+		{"not equal", "department != IT", []string{"Bob", "Amy Lee"}, false},
+		{"unknown column", "salary > 10", nil, true},
+		{"non-numeric ordering", "age > old", nil, true},
+		{"missing value", "age >", nil, true},
+		{"unterminated quote", `name = "John`, nil, true},
+		{"trailing tokens", "age > 30 age", nil, true},
+		{"unbalanced parenthesis", "(age > 30", nil, true},
+		{"contains", "name contains o", []string{"John", "Bob"}, false},
+		{"startswith", `department STARTSWITH "Sales"`, []string{"Amy Lee"}, false},
+		{"endswith", "name endswith e", []string{"Jane", "Amy Lee"}, false},
+		{"regex", `name matches "^J[a-z]+n$"`, []string{"John"}, false},
+		{"regex with and", `name matches "^J" AND age > 30`, []string{"Jane"}, false},
+		{"invalid regex", `name matches "("`, nil, true},
+		{"unknown word operator", "name like J", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.FilterExpr(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FilterExpr(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var names []string
+			for _, row := range got.Rows {
+				names = append(names, row[0])
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("FilterExpr(%q) = %v, want %v", tt.expr, names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestFilterColumn(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "joined", "email"})
+	rows := [][]string{
+		{"1", "2023-01-15", "ann@example.com"},
+		{"2", "2023-06-30", "bob@test.org"},
+		{"3", "2024-02-01", "cy@example.com"},
+		{"4", "not a date", "dee@example.org"},
+	}
+	for _, row := range rows {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		column  string
+		op      string
+		value   string
+		wantIDs []string
+		wantErr bool
+	}{
+		{"date after", "joined", ">", "2023-03-01", []string{"2", "3"}, false},
+		{"date before other layout", "joined", "<", "06/30/2023", []string{"1"}, false},
+		{"date on or before", "joined", "<=", "2023-06-30", []string{"1", "2"}, false},
+		{"contains", "email", "contains", "example", []string{"1", "3", "4"}, false},
+		{"regex", "email", "matches", `^[a-c][a-z]*@example\.com$`, []string{"1", "3"}, false},
+		{"numeric", "id", ">=", "3", []string{"3", "4"}, false},
+		{"unknown operator", "email", "~", "x", nil, true},
+		{"unknown column", "name", "=", "x", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.FilterColumn(tt.column, tt.op, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FilterColumn() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var ids []string
+			for _, row := range got.Rows {
+				ids = append(ids, row[0])
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("FilterColumn(%s %s %s) = %v, want %v", tt.column, tt.op, tt.value, ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestPredicateCombinators(t *testing.T) {
+	table := pkg.NewTable([]string{"name", "salary", "dept"})
+	rows := [][]string{
+		{"Ann", "1200", "IT"},
+		{"Bob", "900", "IT"},
+		{"Cy", "5000", "HR"},
+		{"Dee", "300", "Sales"},
+	}
+	for _, row := range rows {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		pred      func([]string) bool
+		wantNames []string
+	}{
+		{"and", pkg.And(pkg.ColEquals(table, "dept", "IT"), pkg.ColGreater(table, "salary", "1000")), []string{"Ann"}},
+		{"numeric not lexical", pkg.ColGreater(table, "salary", "1000"), []string{"Ann", "Cy"}},
+		{"or", pkg.Or(pkg.ColEquals(table, "dept", "HR"), pkg.ColLess(table, "salary", "500")), []string{"Cy", "Dee"}},
+		{"and of or and not", pkg.And(
+			pkg.Or(pkg.ColEquals(table, "dept", "IT"), pkg.ColEquals(table, "dept", "HR")),
+			pkg.Not(pkg.ColEquals(table, "name", "Bob")),
+		), []string{"Ann", "Cy"}},
+		{"empty and", pkg.And(), []string{"Ann", "Bob", "Cy", "Dee"}},
+		{"empty or", pkg.Or(), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, row := range table.Filter(tt.pred).Rows {
+				names = append(names, row[0])
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("Filter() = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+
+	// Bad input matches nothing rather than panicking, and ColCompare reports it
+	if got := table.Filter(pkg.ColEquals(table, "missing", "x")).Rows; len(got) != 0 {
+		t.Errorf("ColEquals() with an unknown column matched %v", got)
+	}
+	if got := table.Filter(pkg.ColGreater(table, "salary", "abc")).Rows; len(got) != 0 {
+		t.Errorf("ColGreater() with a non-numeric value matched %v", got)
+	}
+	for _, tt := range [][3]string{{"missing", "=", "x"}, {"salary", ">", "abc"}, {"salary", "~", "1"}} {
+		if _, err := pkg.ColCompare(table, tt[0], tt[1], tt[2]); err == nil {
+			t.Errorf("ColCompare(%q %s %q) error = nil, want error", tt[0], tt[1], tt[2])
+		}
+	}
+	pred, err := pkg.ColCompare(table, "salary", ">=", "1200")
+	if err != nil {
+		t.Fatalf("ColCompare() error = %v", err)
+	}
+	if got := len(table.Filter(pred).Rows); got != 2 {
+		t.Errorf("ColCompare(salary >= 1200) matched %d rows, want 2", got)
+	}
+}
+
+func TestFilterNumberFormat(t *testing.T) {
+	input := "item,amt\na,\"1,234\"\nb,\"$12\"\nc,\"2,000.50\"\n"
+	cfg := pkg.DefaultConfig()
+	cfg.NumberFormat = pkg.NumberFormat{ThousandsSeparator: ',', CurrencySymbols: []string{"$"}}
+	table, err := pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	items := func(filtered *pkg.Table) []string {
+		var got []string
+		for _, row := range filtered.Rows {
+			got = append(got, row[0])
+		}
+		return got
+	}
+
+	filtered, err := table.FilterColumn("amt", ">", "1,000")
+	if err != nil {
+		t.Fatalf("FilterColumn() error = %v", err)
+	}
+	if got := items(filtered); !reflect.DeepEqual(got, []string{"a", "c"}) {
+		t.Errorf("FilterColumn(amt > 1,000) = %v, want [a c]", got)
+	}
+
+	filtered, err = table.FilterExpr("amt = 1234")
+	if err != nil {
+		t.Fatalf("FilterExpr() error = %v", err)
+	}
+	if got := items(filtered); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("FilterExpr(amt = 1234) = %v, want [a]", got)
+	}
+
+	if got := items(table.Filter(pkg.ColLess(table, "amt", "$100"))); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("ColLess(amt, $100) = %v, want [b]", got)
+	}
+}
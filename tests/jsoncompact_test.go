@@ -0,0 +1,57 @@
+package pkg_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestExportToJSONCompact(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "price", "active", "name", "note"})
+	for _, row := range [][]string{
+		{"1", "1.50", "true", `Widget "XL"`, ""},
+		{"2", "20", "false", "Gadget", "fragile"},
+	} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := table.ExportToJSONCompact(&buf); err != nil {
+		t.Fatalf("ExportToJSONCompact() error = %v", err)
+	}
+	want := `{"columns":["id","price","active","name","note"],` +
+		`"types":["integer","float","boolean","string","string"],` +
+		`"rows":[[1,1.50,true,"Widget \"XL\"",null],[2,20,false,"Gadget","fragile"]]}`
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Errorf("ExportToJSONCompact() = %s, want %s", got, want)
+	}
+
+	back, err := pkg.ReadTableFromJSONCompact(&buf)
+	if err != nil {
+		t.Fatalf("ReadTableFromJSONCompact() error = %v", err)
+	}
+	if !table.Equal(back) {
+		t.Errorf("round trip = %v (%v), want %v (%v)", back.Rows, back.GetTypes(), table.Rows, table.GetTypes())
+	}
+}
+
+func TestReadTableFromJSONCompactErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"invalid JSON", `{"columns":`},
+		{"no columns", `{"columns":[],"rows":[]}`},
+		{"short row", `{"columns":["a","b"],"rows":[[1]]}`},
+		{"nested value", `{"columns":["a"],"rows":[[[1]]]}`},
+	}
+	for _, tt := range tests {
+		if _, err := pkg.ReadTableFromJSONCompact(strings.NewReader(tt.input)); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package pkg_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestExportToJSONL(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "price", "active", "name", "note"})
+	for _, row := range [][]string{
+		{"1", "1.5", "true", `Widget "XL" <b>`, ""},
+		{"2", "20", "false", "Gadget", ""},
+		{"3", "0.25", "true", "Line\nbreak", ""},
+	} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := table.ExportToJSONL(&buf); err != nil {
+		t.Fatalf("ExportToJSONL() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(table.Rows) {
+		t.Fatalf("got %d lines, want %d: %q", len(lines), len(table.Rows), buf.String())
+	}
+	for i, line := range lines {
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			t.Errorf("line %d is not valid JSON: %v: %s", i+1, err, line)
+		}
+	}
+	if want := `{"id":1,"price":1.5,"active":true,"name":"Widget \"XL\" <b>","note":null}`; lines[0] != want {
+		t.Errorf("line 1 = %s, want %s", lines[0], want)
+	}
+
+	back, err := pkg.ReadTableFromJSONL(&buf)
+	if err != nil {
+		t.Fatalf("ReadTableFromJSONL() error = %v", err)
+	}
+	if !table.Equal(back) {
+		t.Errorf("round trip = %v (%v), want %v (%v)", back.Rows, back.GetTypes(), table.Rows, table.GetTypes())
+	}
+}
+
+func TestReadTableFromJSONL(t *testing.T) {
+	input := `{"a":1,"b":"x"}
+
+{"b":"y","c":{"nested":[1,2]}}
+{"a":3e2,"c":null}
+`
+	table, err := pkg.ReadTableFromJSONL(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadTableFromJSONL() error = %v", err)
+	}
+	if got := strings.Join(table.Headers, ","); got != "a,b,c" {
+		t.Errorf("headers = %s, want a,b,c", got)
+	}
+	want := [][]string{{"1", "x", ""}, {"", "y", `{"nested":[1,2]}`}, {"3e2", "", ""}}
+	for i, row := range want {
+		if strings.Join(table.Rows[i], "|") != strings.Join(row, "|") {
+			t.Errorf("row %d = %q, want %q", i, table.Rows[i], row)
+		}
+	}
+
+	for _, bad := range []string{"", `{"a":1`, `[1,2]`, `{"a":1} 5`} {
+		if _, err := pkg.ReadTableFromJSONL(strings.NewReader(bad)); err == nil {
+			t.Errorf("ReadTableFromJSONL(%q): expected an error", bad)
+		}
+	}
+}
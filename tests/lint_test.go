@@ -0,0 +1,85 @@
+package pkg_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestLintCSV(t *testing.T) {
+	input := "\xEF\xBB\xBFid,name,score\r\n" +
+		"1,Alice ,90\r\n" +
+		"2,Bob,85\n" +
+		"3,Carol\n" +
+		"4, Dave,n/a\n"
+
+	warnings := pkg.LintCSV(strings.NewReader(input), pkg.DefaultConfig())
+
+	wantFindings := []struct {
+		row    int
+		column string
+		substr string
+	}{
+		{-1, "", "byte order mark"},
+		{3, "", "has 2 fields, header has 3"},
+		{-1, "name", "2 values have leading or trailing whitespace, first in row 1"},
+		{-1, "score", `first text value "n/a" in row 4`},
+		{-1, "", "mixed line endings: 2 CRLF, 3 LF"},
+	}
+	for _, want := range wantFindings {
+		found := false
+		for _, w := range warnings {
+			if w.Row == want.row && w.Column == want.column && strings.Contains(w.Message, want.substr) {
+				found = true
+				if w.Severity != pkg.SeverityWarning {
+					t.Errorf("%s: severity = %v, want warning", w, w.Severity)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("missing finding row=%d column=%q %q in %v", want.row, want.column, want.substr, warnings)
+		}
+	}
+	if len(warnings) != len(wantFindings) {
+		t.Errorf("got %d findings, want %d: %v", len(warnings), len(wantFindings), warnings)
+	}
+	for _, w := range warnings {
+		if w.Column == "id" {
+			t.Errorf("unexpected finding for clean column: %s", w)
+		}
+	}
+}
+
+func TestLintCSVClean(t *testing.T) {
+	warnings := pkg.LintCSV(strings.NewReader("a,b\n1,x\n2,y\n"), pkg.DefaultConfig())
+	if len(warnings) != 0 {
+		t.Errorf("LintCSV() = %v, want no findings", warnings)
+	}
+}
+
+func TestLintCSVParseError(t *testing.T) {
+	warnings := pkg.LintCSV(strings.NewReader("a,b\n1,\"unterminated\n"), pkg.DefaultConfig())
+	if len(warnings) == 0 {
+		t.Fatal("LintCSV() reported nothing for an unterminated quote")
+	}
+	last := warnings[len(warnings)-1]
+	if last.Severity != pkg.SeverityError || !strings.Contains(last.Message, "unterminated") {
+		t.Errorf("last finding = %s, want an unterminated quote error", last)
+	}
+}
+
+func TestLintCSVN(t *testing.T) {
+	// A ragged row inside the sample, an unterminated quote after it
+	input := "a,b\n1,x\n2\n3,z\n4,\"unterminated\n"
+
+	warnings := pkg.LintCSVN(strings.NewReader(input), pkg.DefaultConfig(), 3)
+	if len(warnings) != 1 || warnings[0].Row != 2 || !strings.Contains(warnings[0].Message, "has 1 fields") {
+		t.Errorf("LintCSVN(3) = %v, want only the ragged row 2", warnings)
+	}
+
+	warnings = pkg.LintCSVN(strings.NewReader(input), pkg.DefaultConfig(), -1)
+	if last := warnings[len(warnings)-1]; last.Severity != pkg.SeverityError {
+		t.Errorf("LintCSVN(-1) last finding = %s, want the parse error", last)
+	}
+}
@@ -0,0 +1,56 @@
+package pkg_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestReadTables(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		return path
+	}
+	jan := write("jan.csv", "month,sales\njan,10\njan,12\n")
+	feb := write("feb.csv", "month,sales\nfeb,7\n")
+	mar := write("mar.csv", "month,sales\nmar,9\nmar,11\nmar,3\n")
+
+	table, err := pkg.ReadTables([]string{jan, feb, mar}, pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTables() error = %v", err)
+	}
+	if !reflect.DeepEqual(table.Headers, []string{"month", "sales"}) {
+		t.Errorf("headers = %v, want [month sales]", table.Headers)
+	}
+	if len(table.Rows) != 6 {
+		t.Fatalf("rows = %d, want 6", len(table.Rows))
+	}
+	if table.Rows[2][0] != "feb" || table.Rows[5][1] != "3" {
+		t.Errorf("rows = %v, want the files' rows in order", table.Rows)
+	}
+	if typ, _ := table.GetColumnType("sales"); typ != pkg.TypeInteger {
+		t.Errorf("sales type = %v, want integer", typ)
+	}
+
+	// A file with other headers is rejected, naming it
+	other := write("other.csv", "month,revenue\napr,1\n")
+	_, err = pkg.ReadTables([]string{jan, other}, pkg.DefaultConfig())
+	if err == nil || !strings.Contains(err.Error(), "other.csv") {
+		t.Errorf("ReadTables() with mismatched headers error = %v, want one naming other.csv", err)
+	}
+
+	if _, err := pkg.ReadTables([]string{jan, filepath.Join(dir, "missing.csv")}, pkg.DefaultConfig()); err == nil {
+		t.Error("ReadTables() with a missing file error = nil, want error")
+	}
+	if _, err := pkg.ReadTables(nil, pkg.DefaultConfig()); err == nil {
+		t.Error("ReadTables(nil) error = nil, want error")
+	}
+}
@@ -0,0 +1,260 @@
+package pkg_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestNumberFormatDetectType(t *testing.T) {
+	us := pkg.NumberFormat{ThousandsSeparator: ',', CurrencySymbols: []string{"$"}}
+	eu := pkg.NumberFormat{ThousandsSeparator: '.', DecimalSeparator: ',', CurrencySymbols: []string{"€"}}
+	pct := pkg.NumberFormat{Percent: true}
+	frac := pkg.NumberFormat{Percent: true, PercentAsFraction: true}
+	acct := pkg.NumberFormat{ThousandsSeparator: ',', CurrencySymbols: []string{"$"}, ParenNegative: true}
+
+	tests := []struct {
+		name string
+		nf   pkg.NumberFormat
+		val  string
+		want pkg.ColumnType
+		norm string
+	}{
+		{"grouped integer", us, "1,234", pkg.TypeInteger, "1234"},
+		{"grouped float", us, "1,234.56", pkg.TypeFloat, "1234.56"},
+		{"currency prefix", us, "$1,234.56", pkg.TypeFloat, "1234.56"},
+		{"sign before currency", us, "-$1,234,567", pkg.TypeInteger, "-1234567"},
+		{"sign after currency", us, "$-12", pkg.TypeInteger, "-12"},
+		{"plain number", us, "42.5", pkg.TypeFloat, "42.5"},
+		{"incomplete group", us, "1,23", pkg.TypeString, ""},
+		{"list of numbers", us, "1,2,3", pkg.TypeString, ""},
+		{"currency only", us, "$", pkg.TypeString, ""},
+		{"null", us, "", pkg.TypeNull, ""},
+		{"boolean", us, "true", pkg.TypeBoolean, ""},
+		{"european", eu, "1.234,56 €", pkg.TypeFloat, "1234.56"},
+		{"european decimal only", eu, "0,5", pkg.TypeFloat, "0.5"},
+		{"strict default", pkg.NumberFormat{}, "1,234", pkg.TypeString, ""},
+		{"leading plus", pkg.NumberFormat{}, "+5", pkg.TypeInteger, "+5"},
+		{"percent", pct, "45%", pkg.TypeInteger, "45"},
+		{"percent with space", pct, "12.5 %", pkg.TypeFloat, "12.5"},
+		{"percent only", pct, "%", pkg.TypeString, ""},
+		{"percent off", pkg.NumberFormat{}, "45%", pkg.TypeString, ""},
+		{"percent as fraction", frac, "45%", pkg.TypeFloat, "0.45"},
+		{"small percent as fraction", frac, "-2.5%", pkg.TypeFloat, "-0.025"},
+		{"large percent as fraction", frac, "1250%", pkg.TypeFloat, "12.50"},
+		{"fraction without percent", frac, "45", pkg.TypeInteger, "45"},
+		{"parenthesized negative", acct, "(1,234)", pkg.TypeInteger, "-1234"},
+		{"parenthesized currency", acct, "($12.50)", pkg.TypeFloat, "-12.50"},
+		{"parenthesized and signed", acct, "(-5)", pkg.TypeString, ""},
+		{"parentheses off", us, "(5)", pkg.TypeString, ""},
+		{"parenthesized percent", pkg.NumberFormat{Percent: true, ParenNegative: true}, "(5%)", pkg.TypeInteger, "-5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.nf.DetectType(tt.val); got != tt.want {
+				t.Errorf("DetectType(%q) = %v, want %v", tt.val, got, tt.want)
+			}
+			if tt.norm == "" {
+				return
+			}
+			if got, ok := tt.nf.Normalize(tt.val); !ok || got != tt.norm {
+				t.Errorf("Normalize(%q) = %q, %v, want %q", tt.val, got, ok, tt.norm)
+			}
+		})
+	}
+}
+
+func TestNumberFormatStatistics(t *testing.T) {
+	input := "region,revenue\n" +
+		"north,\"$1,234.50\"\n" +
+		"north,\"$10,000\"\n" +
+		"south,\"-$34.50\"\n"
+	cfg := pkg.DefaultConfig()
+	cfg.NumberFormat = pkg.NumberFormat{ThousandsSeparator: ',', CurrencySymbols: []string{"$"}}
+
+	table, err := pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if colType, _ := table.GetColumnType("revenue"); colType != pkg.TypeFloat {
+		t.Errorf("revenue type = %v, want float", colType)
+	}
+	// Cells keep their original text
+	if got := table.Rows[0][1]; got != "$1,234.50" {
+		t.Errorf("cell = %q, want it unchanged", got)
+	}
+
+	want := [][]string{{"north", "11234.5"}, {"south", "-34.5"}}
+	got, err := table.GroupBy([]string{"region"}, map[string]string{"revenue": "sum"})
+	if err != nil {
+		t.Fatalf("GroupBy() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Rows, want) {
+		t.Errorf("GroupBy() rows = %v, want %v", got.Rows, want)
+	}
+	streamed, err := pkg.StreamGroupBy(strings.NewReader(input), cfg,
+		[]string{"region"}, []pkg.AggSpec{{Column: "revenue", Func: "sum"}})
+	if err != nil {
+		t.Fatalf("StreamGroupBy() error = %v", err)
+	}
+	if !reflect.DeepEqual(streamed.Rows, want) {
+		t.Errorf("StreamGroupBy() rows = %v, want %v", streamed.Rows, want)
+	}
+
+	summary, err := table.Summarize("revenue")
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if count, nonNumeric := summary.Rows[0][1], summary.Rows[0][2]; count != "3" || nonNumeric != "0" {
+		t.Errorf("Summarize() count = %s, non_numeric = %s, want 3 and 0", count, nonNumeric)
+	}
+
+	// Without a number format the column is text, until one is set
+	plain, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if colType, _ := plain.GetColumnType("revenue"); colType != pkg.TypeString {
+		t.Errorf("revenue type without a number format = %v, want string", colType)
+	}
+	plain.SetNumberFormat(cfg.NumberFormat)
+	if colType, _ := plain.GetColumnType("revenue"); colType != pkg.TypeFloat {
+		t.Errorf("revenue type after SetNumberFormat = %v, want float", colType)
+	}
+}
+
+func TestNumberFormatPercentAndParens(t *testing.T) {
+	input := "item,margin,balance\n" +
+		"a,45%,\"1,000\"\n" +
+		"b,12.5%,\"(1,234)\"\n" +
+		"c,2.5%,(66)\n"
+	cfg := pkg.DefaultConfig()
+	cfg.NumberFormat = pkg.NumberFormat{ThousandsSeparator: ',', Percent: true, PercentAsFraction: true, ParenNegative: true}
+
+	table, err := pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	for _, col := range []string{"margin", "balance"} {
+		colType, _ := table.GetColumnType(col)
+		if colType != pkg.TypeFloat && colType != pkg.TypeInteger {
+			t.Errorf("%s type = %v, want numeric", col, colType)
+		}
+	}
+
+	summary, err := table.Summarize("margin", "balance")
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	// count, non_numeric, mean, stddev, min and max
+	want := map[string][]string{
+		"margin":  {"3", "0", "0.20", "0.22", "0.03", "0.45"},
+		"balance": {"3", "0", "-100.00", "1117.39", "-1234.00", "1000.00"},
+	}
+	for _, row := range summary.Rows {
+		got := []string{row[1], row[2], row[3], row[4], row[5], row[6]}
+		if w := want[row[0]]; !reflect.DeepEqual(got, w) {
+			t.Errorf("Summarize() %s = %v, want %v", row[0], got, w)
+		}
+	}
+
+	// The same data is text with the default number format
+	plain, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	for _, col := range []string{"margin", "balance"} {
+		if colType, _ := plain.GetColumnType(col); colType != pkg.TypeString {
+			t.Errorf("%s type without a number format = %v, want string", col, colType)
+		}
+	}
+}
+
+func TestNumberFormatJSONExports(t *testing.T) {
+	input := "amt,price\n\"1,234\",$9.50\n+12,\"$1,000\"\n"
+	cfg := pkg.DefaultConfig()
+	cfg.NumberFormat = pkg.NumberFormat{ThousandsSeparator: ',', CurrencySymbols: []string{"$"}}
+	table, err := pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	// Every exporter stores the numbers as numbers
+	var buf bytes.Buffer
+	if err := table.ExportToJSON(&buf); err != nil {
+		t.Fatalf("ExportToJSON() error = %v", err)
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	want := []map[string]interface{}{{"amt": 1234.0, "price": 9.5}, {"amt": 12.0, "price": 1000.0}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("ExportToJSON() = %v, want %v", rows, want)
+	}
+
+	buf.Reset()
+	if err := table.ExportToJSONL(&buf); err != nil {
+		t.Fatalf("ExportToJSONL() error = %v", err)
+	}
+	if got, want := buf.String(), "{\"amt\":1234,\"price\":9.5}\n{\"amt\":12,\"price\":1000}\n"; got != want {
+		t.Errorf("ExportToJSONL() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := table.ExportToJSONCompact(&buf); err != nil {
+		t.Fatalf("ExportToJSONCompact() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `[[1234,9.50],[12,1000]]`) {
+		t.Errorf("ExportToJSONCompact() = %s, want numeric rows", buf.String())
+	}
+}
+
+func TestNumberFormatConversions(t *testing.T) {
+	input := "qty,price\n\"1,234\",$9.50\n7,\"$1,000\"\n"
+	cfg := pkg.DefaultConfig()
+	cfg.NumberFormat = pkg.NumberFormat{ThousandsSeparator: ',', CurrencySymbols: []string{"$"}}
+	table, err := pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	ints, bad, err := table.GetColumnInts("qty")
+	if err != nil || len(bad) != 0 || !reflect.DeepEqual(ints, []int64{1234, 7}) {
+		t.Errorf("GetColumnInts() = %v, %v, %v, want [1234 7]", ints, bad, err)
+	}
+
+	type item struct {
+		Qty   uint16
+		Price float32
+	}
+	var items []item
+	if err := table.ToStructs(&items); err != nil {
+		t.Fatalf("ToStructs() error = %v", err)
+	}
+	if want := []item{{1234, 9.5}, {7, 1000}}; !reflect.DeepEqual(items, want) {
+		t.Errorf("ToStructs() = %v, want %v", items, want)
+	}
+
+	opts := pkg.DefaultFormat()
+	opts.ColumnFormat = map[string]string{"qty": "%06d", "price": "%.1f"}
+	var buf bytes.Buffer
+	if _, err := table.WriteFormatted(&buf, opts); err != nil {
+		t.Fatalf("WriteFormatted() error = %v", err)
+	}
+	for _, want := range []string{"001234", "1000.0"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("WriteFormatted() = %s, want it to contain %q", buf.String(), want)
+		}
+	}
+
+	for _, w := range pkg.LintCSV(strings.NewReader(input), cfg) {
+		if strings.Contains(w.Message, "mixes value types") {
+			t.Errorf("LintCSV() warning %v for a column in the configured number format", w)
+		}
+	}
+}
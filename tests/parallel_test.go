@@ -0,0 +1,178 @@
+package pkg_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+// generateMultilineCSV builds a CSV large enough to be split into several
+// chunks, with quoted newlines scattered through every chunk
+func generateMultilineCSV(rows int) string {
+	var sb strings.Builder
+	sb.WriteString("id,note,value\n")
+	for i := 0; i < rows; i++ {
+		if i%7 == 0 {
+			fmt.Fprintf(&sb, "%d,\"line one\nline \"\"two\"\"\n\",%d\n", i, i*3)
+		} else {
+			fmt.Fprintf(&sb, "%d,plain note %d,%d\n", i, i, i*3)
+		}
+	}
+	return sb.String()
+}
+
+func TestReadTableParallel(t *testing.T) {
+	input := generateMultilineCSV(20000)
+
+	want, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	for _, workers := range []int{0, 1, 3, 8} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			got, err := pkg.ReadTableParallel(strings.NewReader(input), int64(len(input)), pkg.DefaultConfig(), workers)
+			if err != nil {
+				t.Fatalf("ReadTableParallel() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Headers, want.Headers) {
+				t.Errorf("ReadTableParallel() headers = %v, want %v", got.Headers, want.Headers)
+			}
+			if !reflect.DeepEqual(got.Rows, want.Rows) {
+				t.Errorf("ReadTableParallel() rows differ from serial ReadTable (%d vs %d rows)",
+					len(got.Rows), len(want.Rows))
+			}
+			if !reflect.DeepEqual(got.GetTypes(), want.GetTypes()) {
+				t.Errorf("ReadTableParallel() types = %v, want %v", got.GetTypes(), want.GetTypes())
+			}
+		})
+	}
+}
+
+func TestReadTableParallelComments(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("a,b\n")
+	for i := 0; i < 20000; i++ {
+		// A stray quote in a comment must not confuse the chunk splitter
+		fmt.Fprintf(&sb, "# comment with a \" quote\n%d,%d\n", i, i)
+	}
+	input := sb.String()
+
+	cfg := pkg.DefaultConfig()
+	cfg.Comment = '#'
+	got, err := pkg.ReadTableParallel(strings.NewReader(input), int64(len(input)), cfg, 4)
+	if err != nil {
+		t.Fatalf("ReadTableParallel() error = %v", err)
+	}
+	if len(got.Rows) != 20000 {
+		t.Errorf("ReadTableParallel() got %d rows, want 20000", len(got.Rows))
+	}
+}
+
+func TestReadTableParallelBareQuotes(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("id,size,note\n")
+	for i := 0; i < 20000; i++ {
+		// A quote inside an unquoted field is data and must not open a
+		// quoted section in the chunk splitter
+		if i == 0 {
+			fmt.Fprintf(&sb, "%d,5\"10,plain\n", i)
+		} else {
+			fmt.Fprintf(&sb, "%d,%d,\"multi\nline %d\"\n", i, i%12, i)
+		}
+	}
+	input := sb.String()
+
+	want, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	got, err := pkg.ReadTableParallel(strings.NewReader(input), int64(len(input)), pkg.DefaultConfig(), 4)
+	if err != nil {
+		t.Fatalf("ReadTableParallel() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Rows, want.Rows) {
+		t.Errorf("ReadTableParallel() rows differ from serial ReadTable (%d vs %d rows)",
+			len(got.Rows), len(want.Rows))
+	}
+	if want.Rows[0][1] != `5"10` {
+		t.Errorf("bare quote field = %q, want %q", want.Rows[0][1], `5"10`)
+	}
+}
+
+func TestReadTableParallelFieldCount(t *testing.T) {
+	input := generateMultilineCSV(20000) + "1,2\n"
+	_, want := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if want == nil || !strings.Contains(want.Error(), "wrong number of fields") {
+		t.Fatalf("ReadTable() error = %v, want field count error", want)
+	}
+	for _, workers := range []int{2, 4, 8} {
+		// The error has the same position as a serial read's
+		_, err := pkg.ReadTableParallel(strings.NewReader(input), int64(len(input)), pkg.DefaultConfig(), workers)
+		if err == nil || err.Error() != want.Error() {
+			t.Errorf("ReadTableParallel(%d workers) error = %v, want %v", workers, err, want)
+		}
+	}
+}
+
+func TestReadTableParallelStrict(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("a,b,c\n")
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&sb, "%d,x,y\n", i)
+	}
+	for i := 0; i < 30000; i++ {
+		fmt.Fprintf(&sb, "%d,x\n", i)
+	}
+	ragged := sb.String()
+	bareQuote := generateMultilineCSV(20000) + "1,5\"10,3\n"
+
+	cfg := pkg.DefaultConfig()
+	cfg.StrictRFC4180 = true
+	cfg.OnRagged = pkg.RaggedPad
+
+	for name, input := range map[string]string{"field count": ragged, "bare quote": bareQuote} {
+		t.Run(name, func(t *testing.T) {
+			_, want := pkg.ReadTable(strings.NewReader(input), cfg)
+			if want == nil {
+				t.Fatal("ReadTable() error = nil, want error")
+			}
+			for _, workers := range []int{2, 4, 8} {
+				_, err := pkg.ReadTableParallel(strings.NewReader(input), int64(len(input)), cfg, workers)
+				if err == nil || err.Error() != want.Error() {
+					t.Errorf("ReadTableParallel(%d workers) error = %v, want %v", workers, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadTableParallelWhitespace(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("id  note\n")
+	for i := 0; i < 50000; i++ {
+		// Only whitespace separates the quoted field from the one before it
+		fmt.Fprintf(&sb, "%d  \"line one\nline two\"\n", i)
+	}
+	input := sb.String()
+
+	cfg := pkg.DefaultConfig()
+	cfg.WhitespaceDelimited = true
+	want, err := pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	for _, workers := range []int{2, 4, 8} {
+		got, err := pkg.ReadTableParallel(strings.NewReader(input), int64(len(input)), cfg, workers)
+		if err != nil {
+			t.Fatalf("ReadTableParallel(%d workers) error = %v", workers, err)
+		}
+		if !reflect.DeepEqual(got.Rows, want.Rows) {
+			t.Errorf("ReadTableParallel(%d workers) rows differ from serial ReadTable (%d vs %d rows)",
+				workers, len(got.Rows), len(want.Rows))
+		}
+	}
+}
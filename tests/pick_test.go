@@ -0,0 +1,71 @@
+package pkg_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestGroupByPick(t *testing.T) {
+	input := "name,dept,salary\n" +
+		"Ann,IT,900\n" +
+		"Bob,HR,1500\n" +
+		"Cid,IT,1000\n" +
+		"Dee,HR,\n" +
+		"Eve,IT,1000\n" +
+		"Fay,HR,95\n"
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		pick pkg.PickSpec
+		want [][]string
+	}{
+		// Numeric comparison: 1000 beats 900, and Cid wins the tie with Eve
+		{"max salary", pkg.PickSpec{Func: "max", Column: "salary"},
+			[][]string{{"Cid", "IT", "1000"}, {"Bob", "HR", "1500"}}},
+		// The null salary is skipped
+		{"min salary", pkg.PickSpec{Func: "MIN", Column: "salary"},
+			[][]string{{"Ann", "IT", "900"}, {"Fay", "HR", "95"}}},
+		{"max name", pkg.PickSpec{Func: "max", Column: "name"},
+			[][]string{{"Eve", "IT", "1000"}, {"Fay", "HR", "95"}}},
+		{"first", pkg.PickSpec{Func: "first"},
+			[][]string{{"Ann", "IT", "900"}, {"Bob", "HR", "1500"}}},
+		{"last", pkg.PickSpec{Func: "last"},
+			[][]string{{"Eve", "IT", "1000"}, {"Fay", "HR", "95"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.GroupByPick([]string{"dept"}, tt.pick)
+			if err != nil {
+				t.Fatalf("GroupByPick() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Headers, table.Headers) {
+				t.Errorf("GroupByPick() headers = %v, want %v", got.Headers, table.Headers)
+			}
+			if !reflect.DeepEqual(got.Rows, tt.want) {
+				t.Errorf("GroupByPick() rows = %v, want %v", got.Rows, tt.want)
+			}
+		})
+	}
+
+	errTests := []struct {
+		name      string
+		groupCols []string
+		pick      pkg.PickSpec
+	}{
+		{"unknown group column", []string{"team"}, pkg.PickSpec{Func: "first"}},
+		{"unknown pick column", []string{"dept"}, pkg.PickSpec{Func: "max", Column: "age"}},
+		{"unknown pick", []string{"dept"}, pkg.PickSpec{Func: "median", Column: "salary"}},
+	}
+	for _, tt := range errTests {
+		if _, err := table.GroupByPick(tt.groupCols, tt.pick); err == nil {
+			t.Errorf("%s: GroupByPick() want an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package pkg_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestRunScript(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.csv")
+	output := filepath.Join(dir, "output.csv")
+	script := filepath.Join(dir, "script.txt")
+
+	data := "name,age\nJohn,25\nJane,30\nBob,35\n"
+	if err := os.WriteFile(input, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	commands := strings.Join([]string{
+		"# load and keep the older rows",
+		"load " + input,
+		"",
+		"filter age >= 30",
+		"save " + output,
+	}, "\n")
+	if err := os.WriteFile(script, []byte(commands), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pkg.NewREPL().RunScript(script); err != nil {
+		t.Fatalf("RunScript() error = %v", err)
+	}
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("reading output: %v", err)
+	}
+	want := "name,age\nJane,30\nBob,35\n"
+	if string(got) != want {
+		t.Errorf("RunScript() output = %q, want %q", got, want)
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.csv")
+	undone := filepath.Join(dir, "undone.csv")
+	redone := filepath.Join(dir, "redone.csv")
+	if err := os.WriteFile(input, []byte("name,age\nJohn,25\nJane,30\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runScriptOutput(t, "load "+input, "filter age >= 30", "delete name = Jane",
+		"undo", "undo", "save "+undone, "redo", "save "+redone)
+	for path, want := range map[string]string{
+		undone: "name,age\nJohn,25\nJane,30\n",
+		redone: "name,age\nJane,30\n",
+	} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading output: %v", err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(path), got, want)
+		}
+	}
+
+	for _, cmd := range []string{"undo", "redo"} {
+		script := filepath.Join(dir, "script.txt")
+		if err := os.WriteFile(script, []byte(cmd), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := pkg.NewREPL().RunScript(script); err == nil {
+			t.Errorf("%s with no history: want an error", cmd)
+		}
+	}
+}
+
+func TestRunScriptErrors(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.txt")
+	output := filepath.Join(dir, "output.csv")
+	input := filepath.Join(dir, "input.csv")
+	if err := os.WriteFile(input, []byte("a\n1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	commands := "load missing.csv\nload " + input + "\nsave " + output + "\n"
+	if err := os.WriteFile(script, []byte(commands), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stops on the first error by default
+	err := pkg.NewREPL().RunScript(script)
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("RunScript() error = %v, want error on line 1", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("RunScript() should not run commands after a failure")
+	}
+
+	// Runs the remaining commands when asked to continue
+	repl := pkg.NewREPL()
+	repl.ContinueOnError = true
+	if err := repl.RunScript(script); err == nil {
+		t.Error("RunScript() should still report the failed command")
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("RunScript() should continue after a failure: %v", err)
+	}
+}
+
+func TestOutliers(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.csv")
+	script := filepath.Join(dir, "script.txt")
+
+	var sb strings.Builder
+	sb.WriteString("id,latency,version\n")
+	for i := 1; i <= 30; i++ {
+		latency := fmt.Sprintf("%d", 100+i%5)
+		if i == 17 {
+			latency = "900" // planted outlier
+		}
+		fmt.Fprintf(&sb, "%d,%s,2\n", i, latency)
+	}
+	sb.WriteString("31,,2\n")
+	if err := os.WriteFile(input, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte("load "+input+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := pkg.NewREPL()
+	if err := r.RunScript(script); err != nil {
+		t.Fatalf("RunScript() error = %v", err)
+	}
+
+	got, err := r.Outliers("latency", 3)
+	if err != nil {
+		t.Fatalf("Outliers() error = %v", err)
+	}
+	if want := []string{"id", "latency", "version", "z_score"}; !reflect.DeepEqual(got.Headers, want) {
+		t.Errorf("Outliers() headers = %v, want %v", got.Headers, want)
+	}
+	if len(got.Rows) != 1 || got.Rows[0][0] != "17" {
+		t.Fatalf("Outliers() rows = %v, want only id 17", got.Rows)
+	}
+	if z, err := strconv.ParseFloat(got.Rows[0][3], 64); err != nil || z <= 3 {
+		t.Errorf("z_score = %q, want a value above 3", got.Rows[0][3])
+	}
+
+	// A constant column has no outliers rather than dividing by zero
+	got, err = r.Outliers("version", 3)
+	if err != nil {
+		t.Fatalf("Outliers() error = %v", err)
+	}
+	if len(got.Rows) != 0 {
+		t.Errorf("Outliers() on a non-varying column = %v, want none", got.Rows)
+	}
+
+	if _, err := r.Outliers("missing", 3); err == nil {
+		t.Error("Outliers() on a missing column should fail")
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.csv")
+	script := filepath.Join(dir, "script.txt")
+
+	// y grows with x but not linearly; z is constant; rank ties in w
+	var sb strings.Builder
+	sb.WriteString("x,y,z,w\n")
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&sb, "%d,%d,7,%d\n", i, i*i*i*i, (i+1)/2)
+	}
+	if err := os.WriteFile(input, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte("load "+input+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := pkg.NewREPL()
+	if err := r.RunScript(script); err != nil {
+		t.Fatalf("RunScript() error = %v", err)
+	}
+
+	cell := func(m *pkg.Table, row, col int) float64 {
+		v, err := strconv.ParseFloat(m.Rows[row][col+1], 64)
+		if err != nil {
+			t.Fatalf("correlation %q is not a number", m.Rows[row][col+1])
+		}
+		return v
+	}
+
+	pearson, err := r.Correlate([]string{"x", "y"}, pkg.CorrelationPearson)
+	if err != nil {
+		t.Fatalf("Correlate(pearson) error = %v", err)
+	}
+	spearman, err := r.Correlate([]string{"x", "y"}, pkg.CorrelationSpearman)
+	if err != nil {
+		t.Fatalf("Correlate(spearman) error = %v", err)
+	}
+	if got := cell(spearman, 0, 1); got != 1 {
+		t.Errorf("Spearman(x, y) = %v, want 1", got)
+	}
+	if got := cell(pearson, 0, 1); got >= 0.95 {
+		t.Errorf("Pearson(x, y) = %v, want it below Spearman", got)
+	}
+	if got := cell(pearson, 1, 1); got != 1 {
+		t.Errorf("Pearson(y, y) = %v, want 1", got)
+	}
+
+	// w repeats each value twice; ties get average ranks, so it falls just short of 1
+	ties, err := r.Correlate([]string{"x", "w"}, pkg.CorrelationSpearman)
+	if err != nil {
+		t.Fatalf("Correlate(spearman) error = %v", err)
+	}
+	if got := cell(ties, 0, 1); got < 0.95 || got >= 1 {
+		t.Errorf("Spearman(x, w) = %v, want just under 1", got)
+	}
+
+	// Without columns every numeric column is used; a constant column is undefined
+	all, err := r.Correlate(nil, pkg.CorrelationPearson)
+	if err != nil {
+		t.Fatalf("Correlate() error = %v", err)
+	}
+	if !reflect.DeepEqual(all.Headers, []string{"column", "x", "y", "z", "w"}) {
+		t.Errorf("Correlate() headers = %v", all.Headers)
+	}
+	if all.Rows[0][3] != "NaN" {
+		t.Errorf("correlation with a constant column = %q, want NaN", all.Rows[0][3])
+	}
+
+	if _, err := r.Correlate([]string{"x", "y"}, "kendall"); err == nil {
+		t.Error("expected an error for an unknown method")
+	}
+}
+
+func TestDateAnalysis(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.csv")
+	script := filepath.Join(dir, "script.txt")
+
+	data := `id,iso,us,stamp,text
+1,2024-01-15,01/15/2024,2024-01-15T08:30:00Z,hello
+2,2024-03-01,03/01/2024,2024-03-01T12:00:00Z,world
+3,,12/31/2023,2023-12-31T23:59:59Z,again
+4,2024-02-10,not a date,2024-02-10T00:00:00Z,
+`
+	if err := os.WriteFile(input, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, []byte("load "+input+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := pkg.NewREPL()
+	if err := r.RunScript(script); err != nil {
+		t.Fatalf("RunScript() error = %v", err)
+	}
+
+	tests := []struct {
+		column, layout string
+		want           [][]string
+	}{
+		{"iso", "", [][]string{
+			{"format", "2006-01-02"}, {"valid", "3"}, {"invalid", "0"},
+			{"earliest", "2024-01-15"}, {"latest", "2024-03-01"}, {"span_days", "46.0"}}},
+		{"us", "", [][]string{
+			{"format", "01/02/2006"}, {"valid", "3"}, {"invalid", "1"},
+			{"earliest", "12/31/2023"}, {"latest", "03/01/2024"}, {"span_days", "61.0"}}},
+		{"stamp", "", [][]string{
+			{"format", time.RFC3339Nano}, {"valid", "4"}, {"invalid", "0"},
+			{"earliest", "2023-12-31T23:59:59Z"}, {"latest", "2024-03-01T12:00:00Z"}, {"span_days", "60.5"}}},
+		{"us", "01/02/2006", [][]string{
+			{"format", "01/02/2006"}, {"valid", "3"}, {"invalid", "1"},
+			{"earliest", "12/31/2023"}, {"latest", "03/01/2024"}, {"span_days", "61.0"}}},
+	}
+	for _, tt := range tests {
+		got, err := r.DateAnalysis(tt.column, tt.layout)
+		if err != nil {
+			t.Errorf("DateAnalysis(%s, %q) error = %v", tt.column, tt.layout, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Rows, tt.want) {
+			t.Errorf("DateAnalysis(%s, %q) = %v, want %v", tt.column, tt.layout, got.Rows, tt.want)
+		}
+	}
+
+	if _, err := r.DateAnalysis("text", ""); err == nil {
+		t.Error("expected an error for a column without dates")
+	}
+}
+
+// runScriptOutput runs commands as a REPL script and returns what it printed
+func runScriptOutput(t *testing.T, commands ...string) string {
+	t.Helper()
+	script := filepath.Join(t.TempDir(), "script.txt")
+	if err := os.WriteFile(script, []byte(strings.Join(commands, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+	os.Stdout = w
+	runErr := pkg.NewREPL().RunScript(script)
+	os.Stdout = stdout
+	w.Close()
+	printed, _ := io.ReadAll(r)
+	if runErr != nil {
+		t.Fatalf("RunScript() error = %v", runErr)
+	}
+	return string(printed)
+}
+
+func TestFormatAndSet(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "input.csv")
+	if err := os.WriteFile(input, []byte("name,city\nAnn,Amsterdam and surroundings\nBob,Oslo\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each format switch re-previews the table in the new style
+	out := runScriptOutput(t, "load "+input, "format fancy", "format plain")
+	fancy, plain, ok := strings.Cut(out, "\n+")
+	if !ok {
+		t.Fatalf("format plain did not print an ASCII table:\n%s", out)
+	}
+	if !strings.Contains(fancy, "╔") || !strings.Contains(fancy, "║") {
+		t.Errorf("format fancy did not print double borders:\n%s", fancy)
+	}
+	if strings.ContainsAny(plain, "╔║╭│") || strings.Contains(plain, pkg.Cyan) {
+		t.Errorf("format plain printed box drawing or colors:\n%s", plain)
+	}
+
+	// Options set before a format switch are kept
+	out = runScriptOutput(t, "load "+input, "set maxwidth 8", "set numbered on", "format ascii", "preview")
+	tables := strings.Split(strings.TrimSpace(out), "\n\n")
+	last := tables[len(tables)-1]
+	if strings.Contains(last, "Amsterdam and surroundings") {
+		t.Errorf("set maxwidth 8 did not limit the column width:\n%s", out)
+	}
+	if !strings.Contains(last, "#") {
+		t.Errorf("set numbered on did not number rows:\n%s", out)
+	}
+
+	r := pkg.NewREPL()
+	for _, cmd := range []string{"format neon", "set maxwidth wide", "set numbered maybe", "set colour on", "set maxwidth"} {
+		script := filepath.Join(t.TempDir(), "script.txt")
+		if err := os.WriteFile(script, []byte(cmd), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.RunScript(script); err == nil {
+			t.Errorf("%q should fail", cmd)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package pkg_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestRollingAgg(t *testing.T) {
+	input := "date,price\n2024-01-01,10\n2024-01-02,20\n2024-01-03,30\n2024-01-04,9\n2024-01-05,40\n"
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		agg      string
+		opts     pkg.RollingOptions
+		wantName string
+		want     []string
+	}{
+		{"moving average", "avg", pkg.RollingOptions{}, "price_avg_3", []string{"", "", "20", "19.666666666666668", "26.333333333333332"}},
+		{"partial windows", "avg", pkg.RollingOptions{Partial: true}, "price_avg_3", []string{"10", "15", "20", "19.666666666666668", "26.333333333333332"}},
+		{"sum", "sum", pkg.RollingOptions{Name: "total"}, "total", []string{"", "", "60", "59", "79"}},
+		{"numeric minimum", "minimum", pkg.RollingOptions{}, "price_minimum_3", []string{"", "", "10", "9", "9"}},
+		{"numeric maximum", "MAXIMUM", pkg.RollingOptions{Partial: true}, "price_maximum_3", []string{"10", "20", "30", "30", "40"}},
+		{"min spelling", "min", pkg.RollingOptions{}, "price_min_3", []string{"", "", "10", "9", "9"}},
+		{"max spelling", "max", pkg.RollingOptions{}, "price_max_3", []string{"", "", "30", "30", "40"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.RollingAggWithOptions("price", 3, tt.agg, tt.opts)
+			if err != nil {
+				t.Fatalf("RollingAggWithOptions() error = %v", err)
+			}
+			col, err := got.GetColumn(tt.wantName)
+			if err != nil {
+				t.Fatalf("GetColumn(%q) error = %v (headers %v)", tt.wantName, err, got.Headers)
+			}
+			if !reflect.DeepEqual(col, tt.want) {
+				t.Errorf("%s = %q, want %q", tt.wantName, col, tt.want)
+			}
+		})
+	}
+
+	if len(table.Headers) != 2 {
+		t.Errorf("RollingAgg() modified the original table: %v", table.Headers)
+	}
+}
+
+func TestRollingAggNulls(t *testing.T) {
+	table, err := pkg.ReadTable(strings.NewReader("day,temp\n1,10\n2,\n3,20\n4,\n5,\n6,30\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	got, err := table.RollingAgg("temp", 2, "avg")
+	if err != nil {
+		t.Fatalf("RollingAgg() error = %v", err)
+	}
+	// Nulls are left out of a window, which is empty if it holds only nulls
+	col, _ := got.GetColumn("temp_avg_2")
+	if want := []string{"", "10", "20", "20", "", "30"}; !reflect.DeepEqual(col, want) {
+		t.Errorf("temp_avg_2 = %q, want %q", col, want)
+	}
+}
+
+func TestRollingAggErrors(t *testing.T) {
+	table, err := pkg.ReadTable(strings.NewReader("id,value\n1,5\n2,oops\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	if _, err := table.RollingAgg("value", 2, "avg"); err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("RollingAgg() error = %v, want invalid number in row 2", err)
+	}
+	if _, err := table.RollingAgg("id", 0, "avg"); err == nil {
+		t.Error("RollingAgg() with a zero window should fail")
+	}
+	if _, err := table.RollingAgg("id", 2, "median"); err == nil {
+		t.Error("RollingAgg() with an unknown aggregation should fail")
+	}
+	if _, err := table.RollingAgg("missing", 2, "avg"); err == nil {
+		t.Error("RollingAgg() on a missing column should fail")
+	}
+	if _, err := table.RollingAggWithOptions("id", 2, "sum", pkg.RollingOptions{Name: "value"}); err == nil {
+		t.Error("RollingAgg() onto an existing column name should fail")
+	}
+}
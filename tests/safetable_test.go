@@ -0,0 +1,99 @@
+package pkg_test
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+// TestSafeTableConcurrent is meant to be run with -race
+func TestSafeTableConcurrent(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "name"})
+	safe := pkg.NewSafeTable(table)
+
+	const writes = 200
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < writes; i++ {
+			if err := safe.AddRow([]string{strconv.Itoa(i), strings.Repeat("x", i%17)}); err != nil {
+				t.Errorf("AddRow() error = %v", err)
+				return
+			}
+		}
+	}()
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				out := safe.Format(pkg.FormatOptions{Style: pkg.DefaultStyle})
+				if !strings.Contains(out, "name") {
+					t.Errorf("Format() lost the header: %q", out)
+					return
+				}
+				col, err := safe.GetColumn("id")
+				if err != nil {
+					t.Errorf("GetColumn() error = %v", err)
+					return
+				}
+				if n := safe.Len(); n < len(col) {
+					t.Errorf("Len() = %d after reading %d values", n, len(col))
+					return
+				}
+				safe.Read(func(tbl *pkg.Table) { _ = tbl.ColumnWidths() })
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := safe.Len(); n != writes {
+		t.Errorf("Len() = %d, want %d", n, writes)
+	}
+	err := safe.Write(func(tbl *pkg.Table) error {
+		tbl.DeleteWhere(func(row []string) bool { return row[1] == "" })
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if n := safe.Len(); n != writes-writes/17-1 {
+		t.Errorf("Len() after delete = %d, want %d", n, writes-writes/17-1)
+	}
+}
+
+// A plain Table may be formatted from several goroutines at once
+func TestTableConcurrentFormat(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "name"})
+	for i := 0; i < 100; i++ {
+		if err := table.AddRow([]string{strconv.Itoa(i), "name" + strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := table.Format(pkg.FormatOptions{Style: pkg.DefaultStyle})
+	table.Rows = append(table.Rows, []string{"100", "name100"}) // invalidate the width cache
+
+	var wg sync.WaitGroup
+	results := make([]string, 4)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = table.Format(pkg.FormatOptions{Style: pkg.DefaultStyle})
+		}()
+	}
+	wg.Wait()
+	for i, got := range results[1:] {
+		if got != results[0] {
+			t.Errorf("render %d differs from render 0", i+1)
+		}
+	}
+	if results[0] == want {
+		t.Error("Format() did not include the appended row")
+	}
+}
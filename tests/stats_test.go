@@ -0,0 +1,145 @@
+package pkg_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestSummarize(t *testing.T) {
+	input := `price,version,inf,label,mixed
+1,2,1,a,1
+2,2,Inf,b,x
+3,2,,c,3
+4,2,5,d,
+`
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	summary, err := table.Summarize()
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	want := [][]string{
+		{"price", "4", "0", "2.50", "1.29", "1.00", "4.00"},
+		// A constant column has no spread
+		{"version", "4", "0", "2.00", "0.00", "2.00", "2.00"},
+		// Infinite inputs give an infinite mean and an undefined spread
+		{"inf", "3", "0", "Inf", "NaN", "1.00", "Inf"},
+		{"label", "0", "4", "", "", "", ""},
+		{"mixed", "2", "1", "2.00", "1.41", "1.00", "3.00"},
+	}
+	if !reflect.DeepEqual(summary.Rows, want) {
+		t.Errorf("Summarize() = %v, want %v", summary.Rows, want)
+	}
+
+	if _, err := table.Summarize("missing"); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+}
+
+func TestGroupByNonFinite(t *testing.T) {
+	table, err := pkg.ReadTable(strings.NewReader("g,v\na,1\na,Inf\nb,-Inf\nb,Inf\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	result, err := table.GroupBy([]string{"g"}, map[string]string{"v": "avg"})
+	if err != nil {
+		t.Fatalf("GroupBy() error = %v", err)
+	}
+	want := [][]string{{"a", "Inf"}, {"b", "NaN"}}
+	if !reflect.DeepEqual(result.Rows, want) {
+		t.Errorf("GroupBy() = %v, want %v", result.Rows, want)
+	}
+}
+
+func TestProfileTable(t *testing.T) {
+	input := "name,age,city,active\nAnn,30,NY,true\nBob,,NY,false\nCid,25,LA,true\nDee,41,SF,\nEve,9,NY,true\n"
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	profile := pkg.ProfileTable(table)
+	if len(profile.Rows) != len(table.Headers) {
+		t.Fatalf("got %d profile rows, want %d", len(profile.Rows), len(table.Headers))
+	}
+	want := map[string]map[string]string{
+		"name": {"type": "string", "nulls": "0", "null_pct": "0.00", "unique": "5",
+			"min": "Ann", "max": "Eve", "mean": "", "top_values": "Ann (1), Bob (1), Cid (1)"},
+		"age": {"type": "integer", "nulls": "1", "null_pct": "20.00", "unique": "4",
+			"min": "9", "max": "41", "mean": "26.25", "median": "27.50", "stddev": "13.30", "top_values": ""},
+		"city":   {"type": "string", "unique": "3", "min": "LA", "max": "SF", "top_values": "NY (3), LA (1), SF (1)"},
+		"active": {"type": "boolean", "nulls": "1", "unique": "2", "top_values": "true (3), false (1)"},
+	}
+	for i, row := range profile.Rows {
+		metrics, ok := want[row[0]]
+		if !ok || row[0] != table.Headers[i] {
+			t.Errorf("profile row %d is for %q, want %q", i, row[0], table.Headers[i])
+			continue
+		}
+		for metric, value := range metrics {
+			idx, _ := profile.ColumnIndex(metric)
+			if row[idx] != value {
+				t.Errorf("%s %s = %q, want %q", row[0], metric, row[idx], value)
+			}
+		}
+	}
+}
+
+func TestColumnAggregates(t *testing.T) {
+	input := "name,score,bonus\nAnn,10,1.5\nBob,,2.5\nCy,20,\nDee,30,NULL\n"
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		fn   func(string) (float64, error)
+		col  string
+		want float64
+	}{
+		{"sum", table.SumColumn, "score", 60},
+		{"mean skips nulls", table.MeanColumn, "score", 20},
+		{"min", table.MinColumn, "score", 10},
+		{"max", table.MaxColumn, "score", 30},
+		{"float sum", table.SumColumn, "bonus", 4},
+		{"float mean", table.MeanColumn, "bonus", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn(tt.col)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if n, err := table.CountNonNull("score"); err != nil || n != 3 {
+		t.Errorf("CountNonNull(score) = %d, %v, want 3", n, err)
+	}
+	if n, err := table.CountNonNull("name"); err != nil || n != 4 {
+		t.Errorf("CountNonNull(name) = %d, %v, want 4", n, err)
+	}
+
+	// Non-numeric and missing columns are errors
+	for _, col := range []string{"name", "missing"} {
+		if _, err := table.SumColumn(col); err == nil {
+			t.Errorf("SumColumn(%q) error = nil, want error", col)
+		}
+		if _, err := table.MeanColumn(col); err == nil {
+			t.Errorf("MeanColumn(%q) error = nil, want error", col)
+		}
+	}
+	if _, err := table.CountNonNull("missing"); err == nil {
+		t.Error("CountNonNull(missing) error = nil, want error")
+	}
+}
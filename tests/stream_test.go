@@ -0,0 +1,493 @@
+package pkg_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+const salesCSV = `region,product,units,price
+north,apple,10,1.5
+south,pear,4,2.25
+north,pear,7,2
+north,apple,3,1.75
+south,apple,8,1.5
+east,plum,1,3
+`
+
+func TestStreamGroupBy(t *testing.T) {
+	table, err := pkg.ReadTable(strings.NewReader(salesCSV), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		groupCols []string
+		aggs      map[string]string
+	}{
+		{"sum and count", []string{"region"}, map[string]string{"units": "sum", pkg.CountAll: "count"}},
+		{"avg", []string{"region"}, map[string]string{"price": "avg"}},
+		{"minimum", []string{"product"}, map[string]string{"units": "minimum"}},
+		{"maximum", []string{"region", "product"}, map[string]string{"price": "maximum"}},
+		{"stddev", []string{"region"}, map[string]string{"units": "stddev"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := table.GroupBy(tt.groupCols, tt.aggs)
+			if err != nil {
+				t.Fatalf("GroupBy() error = %v", err)
+			}
+
+			// GroupBy orders aggregation columns by name
+			var cols []string
+			for col := range tt.aggs {
+				cols = append(cols, col)
+			}
+			if len(cols) > 1 && cols[0] > cols[1] {
+				cols[0], cols[1] = cols[1], cols[0]
+			}
+			var specs []pkg.AggSpec
+			for _, col := range cols {
+				specs = append(specs, pkg.AggSpec{Column: col, Func: tt.aggs[col]})
+			}
+
+			got, err := pkg.StreamGroupBy(strings.NewReader(salesCSV), pkg.DefaultConfig(), tt.groupCols, specs)
+			if err != nil {
+				t.Fatalf("StreamGroupBy() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Headers, want.Headers) {
+				t.Errorf("headers = %v, want %v", got.Headers, want.Headers)
+			}
+			if !rowsApproxEqual(got.Rows, want.Rows) {
+				t.Errorf("rows = %v, want %v", got.Rows, want.Rows)
+			}
+		})
+	}
+}
+
+func TestStreamGroupByConfig(t *testing.T) {
+	// pass uses numbers as boolean words; booleans compare as strings, so its
+	// minimum differs from the numeric one
+	const data = `store,amount,open,pass
+a,"1,200.5",yes,10
+b,950,no,9
+a,"2,000",no,9
+b,"1,050.25",no,10
+`
+	cfg := pkg.DefaultConfig()
+	cfg.NumberFormat = pkg.NumberFormat{ThousandsSeparator: ','}
+	cfg.BoolTrue = []string{"yes", "10"}
+	cfg.BoolFalse = []string{"no", "9"}
+
+	table, err := pkg.ReadTable(strings.NewReader(data), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	want, err := table.GroupBy([]string{"store"}, map[string]string{"amount": "sum", "open": "maximum", "pass": "minimum"})
+	if err != nil {
+		t.Fatalf("GroupBy() error = %v", err)
+	}
+
+	got, err := pkg.StreamGroupBy(strings.NewReader(data), cfg, []string{"store"}, []pkg.AggSpec{
+		{Column: "amount", Func: "sum"},
+		{Column: "open", Func: "maximum"},
+		{Column: "pass", Func: "minimum"},
+	})
+	if err != nil {
+		t.Fatalf("StreamGroupBy() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Headers, want.Headers) {
+		t.Errorf("headers = %v, want %v", got.Headers, want.Headers)
+	}
+	if !reflect.DeepEqual(got.Rows, want.Rows) {
+		t.Errorf("rows = %v, want %v", got.Rows, want.Rows)
+	}
+	for i, h := range want.Headers {
+		gotType, _ := got.GetColumnType(h)
+		wantType, _ := want.GetColumnType(h)
+		if gotType != wantType {
+			t.Errorf("type of column %d %q = %v, want %v", i, h, gotType, wantType)
+		}
+	}
+}
+
+// rowsApproxEqual compares rows, allowing numeric values to differ by
+// rounding error since streaming computes variance incrementally
+func rowsApproxEqual(a, b [][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false
+		}
+		for j := range a[i] {
+			if a[i][j] == b[i][j] {
+				continue
+			}
+			x, errX := strconv.ParseFloat(a[i][j], 64)
+			y, errY := strconv.ParseFloat(b[i][j], 64)
+			if errX != nil || errY != nil || math.Abs(x-y) > 1e-9 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestStreamGroupByAlias(t *testing.T) {
+	got, err := pkg.StreamGroupBy(strings.NewReader(salesCSV), pkg.DefaultConfig(), []string{"region"}, []pkg.AggSpec{
+		{Column: "units", Func: "sum", As: "total_units"},
+		{Column: "units", Func: "maximum", As: "max_units"},
+		{Column: pkg.CountAll, Func: "count"},
+	})
+	if err != nil {
+		t.Fatalf("StreamGroupBy() error = %v", err)
+	}
+
+	wantHeaders := []string{"region", "total_units", "max_units", "count"}
+	if !reflect.DeepEqual(got.Headers, wantHeaders) {
+		t.Errorf("headers = %v, want %v", got.Headers, wantHeaders)
+	}
+	wantRows := [][]string{
+		{"north", "20", "10", "3"},
+		{"south", "12", "8", "2"},
+		{"east", "1", "1", "1"},
+	}
+	if !reflect.DeepEqual(got.Rows, wantRows) {
+		t.Errorf("rows = %v, want %v", got.Rows, wantRows)
+	}
+}
+
+func TestStreamGroupByErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		groupCols []string
+		aggs      []pkg.AggSpec
+		wantErr   string
+	}{
+		{"missing group column", salesCSV, []string{"city"}, []pkg.AggSpec{{Column: "units", Func: "sum"}}, `group column "city" not found`},
+		{"missing aggregation column", salesCSV, []string{"region"}, []pkg.AggSpec{{Column: "cost", Func: "sum"}}, `aggregation column "cost" not found`},
+		{"unknown aggregation", salesCSV, []string{"region"}, []pkg.AggSpec{{Column: "units", Func: "median"}}, `unknown aggregation "median"`},
+		{"sum of wildcard", salesCSV, []string{"region"}, []pkg.AggSpec{{Column: pkg.CountAll, Func: "sum"}}, "only count"},
+		{"invalid number", "region,units\nnorth,ten\n", []string{"region"}, []pkg.AggSpec{{Column: "units", Func: "sum"}}, `invalid number "ten" for sum`},
+		{"field count", "region,units\nnorth,1,2\n", []string{"region"}, []pkg.AggSpec{{Column: "units", Func: "sum"}}, "wrong number of fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := pkg.StreamGroupBy(strings.NewReader(tt.input), pkg.DefaultConfig(), tt.groupCols, tt.aggs)
+			if err == nil {
+				t.Fatalf("StreamGroupBy() error = nil, want %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("StreamGroupBy() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReservoirSample(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("id,value\n")
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&sb, "%d,%d\n", i, i*i)
+	}
+	input := sb.String()
+
+	sample, err := pkg.ReservoirSample(strings.NewReader(input), pkg.DefaultConfig(), 10, 42)
+	if err != nil {
+		t.Fatalf("ReservoirSample() error = %v", err)
+	}
+	if len(sample.Rows) != 10 || !reflect.DeepEqual(sample.Headers, []string{"id", "value"}) {
+		t.Fatalf("ReservoirSample() = %v with %d rows, want 10 rows", sample.Headers, len(sample.Rows))
+	}
+	prev := -1
+	for _, row := range sample.Rows {
+		id, _ := strconv.Atoi(row[0])
+		if id <= prev {
+			t.Errorf("sample rows out of input order: %v", sample.Rows)
+			break
+		}
+		if row[1] != strconv.Itoa(id*id) {
+			t.Errorf("sampled row %v does not match the input", row)
+		}
+		prev = id
+	}
+
+	// The same seed gives the same sample, a different one does not
+	again, err := pkg.ReservoirSample(strings.NewReader(input), pkg.DefaultConfig(), 10, 42)
+	if err != nil {
+		t.Fatalf("ReservoirSample() error = %v", err)
+	}
+	if !sample.Equal(again) {
+		t.Errorf("same seed gave %v, then %v", sample.Rows, again.Rows)
+	}
+	other, err := pkg.ReservoirSample(strings.NewReader(input), pkg.DefaultConfig(), 10, 7)
+	if err != nil {
+		t.Fatalf("ReservoirSample() error = %v", err)
+	}
+	if sample.Equal(other) {
+		t.Errorf("different seeds gave the same sample %v", sample.Rows)
+	}
+
+	// Inputs shorter than k are returned whole
+	short := "id\n1\n2\n3\n"
+	all, err := pkg.ReservoirSample(strings.NewReader(short), pkg.DefaultConfig(), 10, 1)
+	if err != nil {
+		t.Fatalf("ReservoirSample() error = %v", err)
+	}
+	want, _ := pkg.ReadTable(strings.NewReader(short), pkg.DefaultConfig())
+	if !all.Equal(want) {
+		t.Errorf("short input sample = %v, want %v", all.Rows, want.Rows)
+	}
+
+	if _, err := pkg.ReservoirSample(strings.NewReader(short), pkg.DefaultConfig(), -1, 1); err == nil {
+		t.Error("expected an error for a negative sample size")
+	}
+}
+
+func TestStreamSelect(t *testing.T) {
+	input := "id,name,age\n1,a,10\n2,b,20\n3,c,30\n4,d,40\n5,e,50\n"
+
+	tests := []struct {
+		name string
+		opts pkg.SelectOptions
+		want [][]string
+	}{
+		{"all", pkg.SelectOptions{}, [][]string{
+			{"id", "name", "age"}, {"1", "a", "10"}, {"2", "b", "20"}, {"3", "c", "30"}, {"4", "d", "40"}, {"5", "e", "50"}}},
+		{"head", pkg.SelectOptions{Head: 2}, [][]string{
+			{"id", "name", "age"}, {"1", "a", "10"}, {"2", "b", "20"}}},
+		{"tail", pkg.SelectOptions{Tail: 2}, [][]string{
+			{"id", "name", "age"}, {"4", "d", "40"}, {"5", "e", "50"}}},
+		{"tail longer than input", pkg.SelectOptions{Tail: 10}, [][]string{
+			{"id", "name", "age"}, {"1", "a", "10"}, {"2", "b", "20"}, {"3", "c", "30"}, {"4", "d", "40"}, {"5", "e", "50"}}},
+		{"columns", pkg.SelectOptions{Columns: []string{"age", "id"}}, [][]string{
+			{"age", "id"}, {"10", "1"}, {"20", "2"}, {"30", "3"}, {"40", "4"}, {"50", "5"}}},
+		{"head and tail", pkg.SelectOptions{Head: 4, Tail: 3}, [][]string{
+			{"id", "name", "age"}, {"2", "b", "20"}, {"3", "c", "30"}, {"4", "d", "40"}}},
+		{"head and columns", pkg.SelectOptions{Head: 1, Columns: []string{"name"}}, [][]string{
+			{"name"}, {"a"}}},
+		{"tail and columns", pkg.SelectOptions{Tail: 1, Columns: []string{"name", "age"}}, [][]string{
+			{"name", "age"}, {"e", "50"}}},
+		{"head, tail and columns", pkg.SelectOptions{Head: 3, Tail: 2, Columns: []string{"id"}}, [][]string{
+			{"id"}, {"2"}, {"3"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			err := pkg.StreamSelect(strings.NewReader(input), pkg.DefaultConfig(), tt.opts, func(record []string) error {
+				got = append(got, append([]string(nil), record...))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("StreamSelect() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StreamSelect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Head stops reading early, so a malformed record after it is never seen
+	bad := "id\n1\n2\n\"unterminated\n"
+	err := pkg.StreamSelect(strings.NewReader(bad), pkg.DefaultConfig(), pkg.SelectOptions{Head: 2},
+		func([]string) error { return nil })
+	if err != nil {
+		t.Errorf("StreamSelect() with head read past the selected rows: %v", err)
+	}
+
+	noop := func([]string) error { return nil }
+	if err := pkg.StreamSelect(strings.NewReader(input), pkg.DefaultConfig(), pkg.SelectOptions{Columns: []string{"missing"}}, noop); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+	if err := pkg.StreamSelect(strings.NewReader(input), pkg.DefaultConfig(), pkg.SelectOptions{Tail: -1}, noop); err == nil {
+		t.Error("expected an error for a negative tail")
+	}
+}
+
+func TestReadSelectionJSON(t *testing.T) {
+	input := "id,name,score\n1,Ann,9.5\n2,Bob,7\n"
+
+	selected, err := pkg.ReadSelection(strings.NewReader(input), pkg.DefaultConfig(), pkg.SelectOptions{})
+	if err != nil {
+		t.Fatalf("ReadSelection() error = %v", err)
+	}
+	var got bytes.Buffer
+	if err := selected.ExportToJSON(&got); err != nil {
+		t.Fatalf("ExportToJSON() error = %v", err)
+	}
+	if !json.Valid(got.Bytes()) {
+		t.Fatalf("output is not valid JSON: %s", got.String())
+	}
+
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	var want bytes.Buffer
+	if err := table.ExportToJSON(&want); err != nil {
+		t.Fatalf("ExportToJSON() error = %v", err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("selection JSON = %s, want %s", got.String(), want.String())
+	}
+
+	// Selected columns keep their order and rows are copied out of the stream
+	selected, err = pkg.ReadSelection(strings.NewReader(input), pkg.DefaultConfig(),
+		pkg.SelectOptions{Columns: []string{"name", "id"}})
+	if err != nil {
+		t.Fatalf("ReadSelection() error = %v", err)
+	}
+	if want := [][]string{{"Ann", "1"}, {"Bob", "2"}}; !reflect.DeepEqual(selected.Rows, want) {
+		t.Errorf("ReadSelection() rows = %v, want %v", selected.Rows, want)
+	}
+}
+
+func TestTransformStream(t *testing.T) {
+	input := "name,city,age\nJohn,Boston,30\nJane,\"New York\",17\nBob,Chicago,45\n"
+
+	var out bytes.Buffer
+	var headers [][]string
+	err := pkg.TransformStream(strings.NewReader(input), &out, pkg.DefaultConfig(), func(header, row []string) ([]string, bool) {
+		headers = append(headers, header)
+		if age, _ := strconv.Atoi(row[2]); age < 18 {
+			return nil, false
+		}
+		row[1] = strings.ToUpper(row[1]) + ", US"
+		return row, true
+	})
+	if err != nil {
+		t.Fatalf("TransformStream() error = %v", err)
+	}
+	want := "name,city,age\nJohn,\"BOSTON, US\",30\nBob,\"CHICAGO, US\",45\n"
+	if out.String() != want {
+		t.Errorf("TransformStream() wrote %q, want %q", out.String(), want)
+	}
+	if len(headers) != 3 {
+		t.Fatalf("transform called %d times, want 3", len(headers))
+	}
+	for _, h := range headers {
+		if strings.Join(h, ",") != "name,city,age" {
+			t.Errorf("transform got header %v", h)
+		}
+	}
+
+	// Without a header row nothing extra is written
+	cfg := pkg.DefaultConfig()
+	cfg.NoHeader = true
+	out.Reset()
+	err = pkg.TransformStream(strings.NewReader("a,1\nb,2\n"), &out, cfg, func(header, row []string) ([]string, bool) {
+		return []string{row[1], row[0]}, header[0] == "col1"
+	})
+	if err != nil {
+		t.Fatalf("TransformStream(no header) error = %v", err)
+	}
+	if want := "1,a\n2,b\n"; out.String() != want {
+		t.Errorf("TransformStream(no header) wrote %q, want %q", out.String(), want)
+	}
+}
+
+func TestTransformStreamRagged(t *testing.T) {
+	input := "a,b,c\n1\n2,3,4\n5,6,7,8\n"
+	last := func(header, row []string) ([]string, bool) {
+		return []string{row[0], row[len(header)-1]}, true
+	}
+
+	tests := []struct {
+		policy  pkg.RaggedPolicy
+		want    string
+		wantErr bool
+	}{
+		{policy: pkg.RaggedError, wantErr: true},
+		{policy: pkg.RaggedTruncate, want: "a,b,c\n1,\n2,4\n5,7\n"},
+		{policy: pkg.RaggedSkip, want: "a,b,c\n2,4\n"},
+	}
+	for _, tt := range tests {
+		cfg := pkg.DefaultConfig()
+		cfg.OnRagged = tt.policy
+		var out bytes.Buffer
+		err := pkg.TransformStream(strings.NewReader(input), &out, cfg, last)
+		if tt.wantErr {
+			if !errors.Is(err, pkg.ErrFieldCount) {
+				t.Errorf("TransformStream(policy %d) error = %v, want ErrFieldCount", tt.policy, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("TransformStream(policy %d) error = %v", tt.policy, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("TransformStream(policy %d) wrote %q, want %q", tt.policy, out.String(), tt.want)
+		}
+	}
+
+	// RaggedKeep copies the records as they are
+	cfg := pkg.DefaultConfig()
+	cfg.OnRagged = pkg.RaggedKeep
+	var out bytes.Buffer
+	if err := pkg.TranscodeStream(strings.NewReader(input), &out, cfg, cfg, nil); err != nil {
+		t.Fatalf("TranscodeStream(RaggedKeep) error = %v", err)
+	}
+	if out.String() != input {
+		t.Errorf("TranscodeStream(RaggedKeep) wrote %q, want %q", out.String(), input)
+	}
+}
+
+func TestTranscodeStream(t *testing.T) {
+	in := pkg.DefaultConfig()
+	in.Delimiter = ';'
+
+	var out bytes.Buffer
+	err := pkg.TranscodeStream(strings.NewReader("a;b;c\n1;x,y;\"q\"\"\"\n"), &out, in, pkg.DefaultConfig(), nil)
+	if err != nil {
+		t.Fatalf("TranscodeStream() error = %v", err)
+	}
+	// Fields containing the new delimiter are quoted
+	if want := "a,b,c\n1,\"x,y\",\"q\"\"\"\n"; out.String() != want {
+		t.Errorf("TranscodeStream() wrote %q, want %q", out.String(), want)
+	}
+
+	if err := pkg.TranscodeStream(strings.NewReader("a\n"), &out, in, pkg.Config{Delimiter: ',', Quote: ','}, nil); err == nil {
+		t.Error("TranscodeStream() with an invalid output config: want an error")
+	}
+}
+
+func TestCountRecords(t *testing.T) {
+	input := "id,note\n1,\"two\nlines\"\n2,\"three\r\nmore\nlines\"\n3,plain"
+
+	rows, fields, err := pkg.CountRecords(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("CountRecords() error = %v", err)
+	}
+	if rows != 3 || fields != 2 {
+		t.Errorf("CountRecords() = %d rows, %d fields, want 3 and 2", rows, fields)
+	}
+
+	cfg := pkg.DefaultConfig()
+	cfg.NoHeader = true
+	if rows, _, _ := pkg.CountRecords(strings.NewReader(input), cfg); rows != 4 {
+		t.Errorf("CountRecords(no header) = %d rows, want 4", rows)
+	}
+
+	if rows, fields, err := pkg.CountRecords(strings.NewReader(""), pkg.DefaultConfig()); rows != 0 || fields != 0 || err != nil {
+		t.Errorf("CountRecords(empty) = %d, %d, %v", rows, fields, err)
+	}
+
+	if _, _, err := pkg.CountRecords(strings.NewReader("a\n\"open\n"), pkg.DefaultConfig()); !errors.Is(err, pkg.ErrUnterminatedQuote) {
+		t.Errorf("CountRecords(unterminated) error = %v, want ErrUnterminatedQuote", err)
+	}
+}
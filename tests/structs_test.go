@@ -0,0 +1,154 @@
+package pkg_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+type employee struct {
+	ID      int       `csv:"id"`
+	Name    string    `csv:"full_name"`
+	Salary  float64   // matched case-insensitively to "salary"
+	Active  bool      `csv:"active"`
+	Hired   time.Time `csv:"hired"`
+	Manager *string   `csv:"manager"`
+	Notes   string    `csv:"-"`
+}
+
+func TestToStructs(t *testing.T) {
+	input := "id,full_name,salary,active,hired,manager,Notes\n" +
+		"1,John Doe,5000.5,true,2023-01-15,Jane,ignored\n" +
+		"2,Jane Roe,,FALSE,2020-06-01T09:30:00Z,,ignored\n"
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	var got []employee
+	if err := table.ToStructs(&got); err != nil {
+		t.Fatalf("ToStructs() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("ToStructs() got %d structs, want 2", len(got))
+	}
+
+	first := got[0]
+	if first.ID != 1 || first.Name != "John Doe" || first.Salary != 5000.5 || !first.Active {
+		t.Errorf("ToStructs() first = %+v", first)
+	}
+	if !first.Hired.Equal(time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ToStructs() hired = %v, want 2023-01-15", first.Hired)
+	}
+	if first.Manager == nil || *first.Manager != "Jane" {
+		t.Errorf("ToStructs() manager = %v, want Jane", first.Manager)
+	}
+	if first.Notes != "" {
+		t.Errorf("ToStructs() should skip fields tagged \"-\", got %q", first.Notes)
+	}
+
+	second := got[1]
+	if second.Salary != 0 || second.Active || second.Manager != nil {
+		t.Errorf("ToStructs() second = %+v, want zero salary, inactive, nil manager", second)
+	}
+	if second.Hired.Hour() != 9 {
+		t.Errorf("ToStructs() hired = %v, want 09:30", second.Hired)
+	}
+
+	// Slices of pointers are supported too
+	var ptrs []*employee
+	if err := table.ToStructs(&ptrs); err != nil {
+		t.Fatalf("ToStructs() error = %v", err)
+	}
+	if len(ptrs) != 2 || ptrs[1].Name != "Jane Roe" {
+		t.Errorf("ToStructs() pointers = %+v", ptrs)
+	}
+}
+
+func TestToStructsErrors(t *testing.T) {
+	table, err := pkg.ReadTable(strings.NewReader("id,full_name\n1,John\nabc,Jane\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	var got []employee
+	err = table.ToStructs(&got)
+	if err == nil {
+		t.Fatal("ToStructs() expected error for bad integer")
+	}
+	if !strings.Contains(err.Error(), "row 2") || !strings.Contains(err.Error(), `"id"`) {
+		t.Errorf("ToStructs() error = %v, want row and column", err)
+	}
+
+	if err := table.ToStructs(got); err == nil {
+		t.Error("ToStructs() should reject a non-pointer")
+	}
+	var ints []int
+	if err := table.ToStructs(&ints); err == nil {
+		t.Error("ToStructs() should reject a slice of non-structs")
+	}
+}
+
+type audit struct {
+	Created time.Time `csv:"created,layout=2006-01-02"`
+	Updated time.Time `csv:"updated"`
+}
+
+type account struct {
+	ID    int    `csv:"id"`
+	Owner string `csv:"owner"`
+	audit
+	Balance *float64 `csv:"balance"`
+	hidden  string
+}
+
+func TestFromStructs(t *testing.T) {
+	balance := 12.5
+	accounts := []account{
+		{
+			ID:      1,
+			Owner:   "John",
+			audit:   audit{Created: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+			Balance: &balance,
+			hidden:  "not exported",
+		},
+		{ID: 2, Owner: "Jane"},
+	}
+
+	table, err := pkg.FromStructs(accounts)
+	if err != nil {
+		t.Fatalf("FromStructs() error = %v", err)
+	}
+
+	wantHeaders := []string{"id", "owner", "created", "updated", "balance"}
+	if !reflect.DeepEqual(table.Headers, wantHeaders) {
+		t.Errorf("FromStructs() headers = %v, want %v", table.Headers, wantHeaders)
+	}
+	wantRows := [][]string{
+		{"1", "John", "2024-03-01", "", "12.5"},
+		{"2", "Jane", "", "", ""},
+	}
+	if !reflect.DeepEqual(table.Rows, wantRows) {
+		t.Errorf("FromStructs() rows = %q, want %q", table.Rows, wantRows)
+	}
+	if typ, _ := table.GetColumnType("id"); typ != pkg.TypeInteger {
+		t.Errorf("FromStructs() id type = %v, want TypeInteger", typ)
+	}
+
+	// Round trip back to structs; unexported fields are not carried over
+	var back []account
+	if err := table.ToStructs(&back); err != nil {
+		t.Fatalf("ToStructs() error = %v", err)
+	}
+	accounts[0].hidden = ""
+	if !reflect.DeepEqual(back, accounts) {
+		t.Errorf("round trip = %+v, want %+v", back, accounts)
+	}
+
+	if _, err := pkg.FromStructs(42); err == nil {
+		t.Error("FromStructs() should reject non-slices")
+	}
+}
@@ -1,7 +1,11 @@
 package pkg_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/ooyeku/csv_parser/pkg"
@@ -81,6 +85,54 @@ func TestAddRow(t *testing.T) {
 	}
 }
 
+func TestAppendRows(t *testing.T) {
+	headers := []string{"id", "score", "flag", "note", "empty"}
+	first := [][]string{{"1", "10", "true", "x", ""}}
+	rest := [][]string{
+		{"2", "2.5", "false", "", ""},
+		{"3", "", "yes", "12", ""},
+		{"4", "7", "maybe", "y", ""},
+	}
+
+	perRow := pkg.NewTable(headers)
+	for _, row := range append(append([][]string{}, first...), rest...) {
+		if err := perRow.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	bulk := pkg.NewTable(headers)
+	_ = bulk.ColumnWidths() // the width cache is extended too
+	for _, rows := range [][][]string{first, rest} {
+		if err := bulk.AppendRows(rows); err != nil {
+			t.Fatalf("AppendRows() error = %v", err)
+		}
+	}
+
+	if !reflect.DeepEqual(bulk.Rows, perRow.Rows) {
+		t.Errorf("AppendRows() rows = %v, want %v", bulk.Rows, perRow.Rows)
+	}
+	for _, h := range headers {
+		got, _ := bulk.GetColumnType(h)
+		want, _ := perRow.GetColumnType(h)
+		if got != want {
+			t.Errorf("column %q type = %v, want %v", h, got, want)
+		}
+	}
+	if got, want := bulk.ColumnWidths(), perRow.ColumnWidths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ColumnWidths() = %v, want %v", got, want)
+	}
+
+	// A bad row anywhere rejects the whole batch
+	err := bulk.AppendRows([][]string{{"5", "1", "true", "z", ""}, {"6"}})
+	if err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("AppendRows() error = %v, want a row 2 length error", err)
+	}
+	if len(bulk.Rows) != 4 {
+		t.Errorf("AppendRows() added rows despite an error: %d rows", len(bulk.Rows))
+	}
+}
+
 func TestDetectType(t *testing.T) {
 	tests := []struct {
 		name string
@@ -109,6 +161,27 @@ func TestDetectType(t *testing.T) {
 	}
 }
 
+func TestColumnTypeDetection(t *testing.T) {
+	table := pkg.NewTable([]string{"int", "float", "mixed", "bool", "text", "empty"})
+	rows := [][]string{
+		{"1", "1.5", "1", "true", "a", ""},
+		{"", "2", "2.5", "FALSE", "1", ""},
+		{"3", "", "3", "", "b", "null"},
+	}
+	for _, row := range rows {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	want := []pkg.ColumnType{
+		pkg.TypeInteger, pkg.TypeFloat, pkg.TypeFloat, pkg.TypeBoolean, pkg.TypeString, pkg.TypeNull,
+	}
+	if got := table.GetTypes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTypes() = %v, want %v", got, want)
+	}
+}
+
 func TestGetColumn(t *testing.T) {
 	table := pkg.NewTable([]string{"id", "name", "age"})
 	err := table.AddRow([]string{"1", "John", "25"})
@@ -253,6 +326,59 @@ func TestSort(t *testing.T) {
 	}
 }
 
+func TestSortStable(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "group"})
+	for _, row := range [][]string{
+		{"1", "b"}, {"2", "a"}, {"3", "b"}, {"4", "a"}, {"5", "b"}, {"6", "a"},
+	} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{"asc", []string{"2", "4", "6", "1", "3", "5"}},
+		{"desc", []string{"1", "3", "5", "2", "4", "6"}},
+	}
+	for _, tt := range tests {
+		sorted := table.Copy()
+		if err := sorted.Sort([]string{"group:" + tt.order}); err != nil {
+			t.Fatalf("Sort() error = %v", err)
+		}
+		ids, _ := sorted.GetColumn("id")
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("Sort(group:%s) ids = %v, want %v", tt.order, ids, tt.want)
+		}
+	}
+}
+
+func TestSortByFunc(t *testing.T) {
+	table := pkg.NewTable([]string{"item", "qty", "price"})
+	for _, row := range [][]string{
+		{"a", "3", "10"}, {"b", "1", "100"}, {"c", "20", "2"}, {"d", "2", "15"},
+	} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	// Sort by total value, qty * price, largest first; a and d tie at 30
+	total := func(row []string) float64 {
+		qty, _ := strconv.ParseFloat(row[1], 64)
+		price, _ := strconv.ParseFloat(row[2], 64)
+		return qty * price
+	}
+	table.SortByFunc(func(a, b []string) bool { return total(a) > total(b) })
+
+	items, _ := table.GetColumn("item")
+	if want := []string{"b", "c", "a", "d"}; !reflect.DeepEqual(items, want) {
+		t.Errorf("SortByFunc() items = %v, want %v", items, want)
+	}
+}
+
 func TestGroupBy(t *testing.T) {
 	table := pkg.NewTable([]string{"id", "dept", "salary"})
 	err := table.AddRow([]string{"1", "IT", "1000"})
@@ -284,6 +410,33 @@ func TestGroupBy(t *testing.T) {
 				return len(t.Rows) == 2 // Should have 2 departments
 			},
 		},
+		{
+			name:      "group by dept with wildcard count",
+			groupCols: []string{"dept"},
+			aggs:      map[string]string{"*": "count"},
+			wantErr:   false,
+			checkFn: func(t *pkg.Table) bool {
+				return reflect.DeepEqual(t.Headers, []string{"dept", "count"}) &&
+					reflect.DeepEqual(t.Rows, [][]string{{"IT", "2"}, {"HR", "1"}})
+			},
+		},
+		{
+			name:      "wildcard with other aggregations keeps columns aligned",
+			groupCols: []string{"dept"},
+			aggs:      map[string]string{"*": "count", "salary": "sum", "id": "count"},
+			wantErr:   false,
+			checkFn: func(t *pkg.Table) bool {
+				return reflect.DeepEqual(t.Headers, []string{"dept", "count", "id", "salary"}) &&
+					reflect.DeepEqual(t.Rows[0], []string{"IT", "2", "2", "3000"})
+			},
+		},
+		{
+			name:      "wildcard with numeric aggregation",
+			groupCols: []string{"dept"},
+			aggs:      map[string]string{"*": "sum"},
+			wantErr:   true,
+			checkFn:   func(t *pkg.Table) bool { return true },
+		},
 		{
 			name:      "invalid column",
 			groupCols: []string{"invalid"},
@@ -314,6 +467,30 @@ func TestGroupBy(t *testing.T) {
 	}
 }
 
+func TestGroupByCountCollision(t *testing.T) {
+	table := pkg.NewTable([]string{"count", "count_2"})
+	for _, row := range [][]string{{"1", "a"}, {"1", "b"}, {"2", "c"}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	// The row count is renamed rather than hiding the group key
+	result, err := table.GroupBy([]string{"count"}, map[string]string{pkg.CountAll: "count", "count_2": "first"})
+	if err != nil {
+		t.Fatalf("GroupBy() error = %v", err)
+	}
+	if want := []string{"count", "count_3", "count_2"}; !reflect.DeepEqual(result.Headers, want) {
+		t.Errorf("GroupBy() headers = %v, want %v", result.Headers, want)
+	}
+	if got, _ := result.GetColumn("count"); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("GetColumn(count) = %v, want the group keys [1 2]", got)
+	}
+	if got, _ := result.GetColumn("count_3"); !reflect.DeepEqual(got, []string{"2", "1"}) {
+		t.Errorf("GetColumn(count_3) = %v, want the row counts [2 1]", got)
+	}
+}
+
 func TestCopy(t *testing.T) {
 	original := pkg.NewTable([]string{"id", "name"})
 	err := original.AddRow([]string{"1", "John"})
@@ -347,6 +524,10 @@ func TestCopy(t *testing.T) {
 		t.Errorf("Copy() index = %v, want %v", table.GetIndex(), original.GetIndex())
 	}
 
+	if !table.Equal(original) {
+		t.Error("Copy() is not Equal to the original")
+	}
+
 	// Verify deep table by modifying original
 	err = original.AddRow([]string{"3", "Bob"})
 	if err != nil {
@@ -356,3 +537,936 @@ func TestCopy(t *testing.T) {
 		t.Error("Copy() did not create a deep table")
 	}
 }
+
+func TestTranspose(t *testing.T) {
+	tests := []struct {
+		name        string
+		headers     []string
+		rows        [][]string
+		wantHeaders []string
+		wantRows    [][]string
+	}{
+		{
+			name:        "first column becomes headers",
+			headers:     []string{"metric", "min", "max"},
+			rows:        [][]string{{"age", "18", "65"}, {"salary", "30000", "90000"}, {"tenure", "0", "40"}},
+			wantHeaders: []string{"metric", "age", "salary", "tenure"},
+			wantRows:    [][]string{{"min", "18", "30000", "0"}, {"max", "65", "90000", "40"}},
+		},
+		{
+			name:        "duplicate keys use generic labels",
+			headers:     []string{"dept", "count"},
+			rows:        [][]string{{"IT", "3"}, {"IT", "4"}},
+			wantHeaders: []string{"column", "row1", "row2"},
+			wantRows:    [][]string{{"dept", "IT", "IT"}, {"count", "3", "4"}},
+		},
+		{
+			name:        "no rows",
+			headers:     []string{"a", "b", "c"},
+			wantHeaders: []string{"a"},
+			wantRows:    [][]string{{"b"}, {"c"}},
+		},
+		{
+			name:        "empty table",
+			headers:     []string{},
+			wantHeaders: []string{},
+			wantRows:    [][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := pkg.NewTable(tt.headers)
+			for _, row := range tt.rows {
+				if err := table.AddRow(row); err != nil {
+					t.Fatalf("AddRow() error = %v", err)
+				}
+			}
+			got := table.Transpose()
+			if !reflect.DeepEqual(got.Headers, tt.wantHeaders) {
+				t.Errorf("Transpose() headers = %v, want %v", got.Headers, tt.wantHeaders)
+			}
+			if !reflect.DeepEqual(got.Rows, tt.wantRows) {
+				t.Errorf("Transpose() rows = %v, want %v", got.Rows, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestGroupByPrecision(t *testing.T) {
+	table := pkg.NewTable([]string{"dept", "salary"})
+	for _, row := range [][]string{{"IT", "1000"}, {"IT", "2000"}, {"HR", "1000"}, {"HR", "1000"}, {"HR", "3000"}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		agg       string
+		precision int
+		want      [][]string
+	}{
+		{"avg to two places", "avg", 2, [][]string{{"IT", "1500.00"}, {"HR", "1666.67"}}},
+		{"avg full precision", "avg", pkg.FullPrecision, [][]string{{"IT", "1500"}, {"HR", "1666.6666666666667"}}},
+		{"sum to one place", "sum", 1, [][]string{{"IT", "3000.0"}, {"HR", "5000.0"}}},
+		{"stddev to zero places", "stddev", 0, [][]string{{"IT", "707"}, {"HR", "1155"}}},
+		{"count is not rounded", "count", 2, [][]string{{"IT", "2"}, {"HR", "3"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.GroupByPrecision([]string{"dept"}, map[string]string{"salary": tt.agg}, tt.precision)
+			if err != nil {
+				t.Fatalf("GroupByPrecision() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Rows, tt.want) {
+				t.Errorf("GroupByPrecision() rows = %v, want %v", got.Rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupByNumericExtremes(t *testing.T) {
+	table := pkg.NewTable([]string{"dept", "salary", "code"})
+	for _, row := range [][]string{
+		{"IT", "1000", "b10"}, {"IT", "900", "b9"}, {"IT", "", "a1"},
+		{"HR", "95", "x"}, {"HR", "-2.5", "y"}, {"HR", "100", "z"},
+	} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	var csv bytes.Buffer
+	if err := table.WriteCSV(&csv, pkg.DefaultConfig()); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		col  string
+		agg  string
+		want [][]string
+	}{
+		{"numeric minimum skips nulls", "salary", "minimum", [][]string{{"IT", "900"}, {"HR", "-2.5"}}},
+		{"numeric maximum", "salary", "maximum", [][]string{{"IT", "1000"}, {"HR", "100"}}},
+		{"string minimum", "code", "minimum", [][]string{{"IT", "a1"}, {"HR", "x"}}},
+		{"string maximum", "code", "maximum", [][]string{{"IT", "b9"}, {"HR", "z"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := table.GroupBy([]string{"dept"}, map[string]string{tt.col: tt.agg})
+			if err != nil {
+				t.Fatalf("GroupBy() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Rows, tt.want) {
+				t.Errorf("GroupBy() rows = %v, want %v", got.Rows, tt.want)
+			}
+
+			streamed, err := pkg.StreamGroupBy(bytes.NewReader(csv.Bytes()), pkg.DefaultConfig(),
+				[]string{"dept"}, []pkg.AggSpec{{Column: tt.col, Func: tt.agg}})
+			if err != nil {
+				t.Fatalf("StreamGroupBy() error = %v", err)
+			}
+			if !reflect.DeepEqual(streamed.Rows, tt.want) {
+				t.Errorf("StreamGroupBy() rows = %v, want %v", streamed.Rows, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupByDistinctFirstLast(t *testing.T) {
+	input := "region,customer,day\n" +
+		"north,ann,mon\n" +
+		"south,bob,mon\n" +
+		"north,cid,tue\n" +
+		"north,ann,wed\n" +
+		"north,,thu\n" +
+		"south,bob,fri\n"
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	tests := []struct {
+		col, agg string
+		want     [][]string
+	}{
+		// The empty customer is not counted
+		{"customer", "count_distinct", [][]string{{"north", "2"}, {"south", "1"}}},
+		{"day", "first", [][]string{{"north", "mon"}, {"south", "mon"}}},
+		{"day", "last", [][]string{{"north", "thu"}, {"south", "fri"}}},
+		{"customer", "last", [][]string{{"north", ""}, {"south", "bob"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.agg+" "+tt.col, func(t *testing.T) {
+			got, err := table.GroupBy([]string{"region"}, map[string]string{tt.col: tt.agg})
+			if err != nil {
+				t.Fatalf("GroupBy() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Rows, tt.want) {
+				t.Errorf("GroupBy() rows = %v, want %v", got.Rows, tt.want)
+			}
+
+			streamed, err := pkg.StreamGroupBy(strings.NewReader(input), pkg.DefaultConfig(),
+				[]string{"region"}, []pkg.AggSpec{{Column: tt.col, Func: tt.agg}})
+			if err != nil {
+				t.Fatalf("StreamGroupBy() error = %v", err)
+			}
+			if !reflect.DeepEqual(streamed.Rows, tt.want) {
+				t.Errorf("StreamGroupBy() rows = %v, want %v", streamed.Rows, tt.want)
+			}
+		})
+	}
+
+	// first and last follow the row order
+	if err := table.Sort([]string{"day:asc"}); err != nil {
+		t.Fatalf("Sort() error = %v", err)
+	}
+	got, err := table.GroupBy([]string{"region"}, map[string]string{"day": "first"})
+	if err != nil {
+		t.Fatalf("GroupBy() error = %v", err)
+	}
+	if want := [][]string{{"south", "fri"}, {"north", "mon"}}; !reflect.DeepEqual(got.Rows, want) {
+		t.Errorf("GroupBy() after sorting rows = %v, want %v", got.Rows, want)
+	}
+}
+
+func TestColumnMetadata(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "name", "age"})
+	for _, row := range [][]string{{"1", "John", "30"}, {"2", "Jane", "25"}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	names := table.ColumnNames()
+	names[0] = "changed"
+	if table.Headers[0] != "id" {
+		t.Error("ColumnNames() should return a copy")
+	}
+
+	if idx, ok := table.ColumnIndex("age"); !ok || idx != 2 {
+		t.Errorf("ColumnIndex(age) = %d, %v, want 2, true", idx, ok)
+	}
+	if _, ok := table.ColumnIndex("salary"); ok {
+		t.Error("ColumnIndex(salary) found a missing column")
+	}
+
+	if err := table.RenameColumn("name", "first_name"); err != nil {
+		t.Fatalf("RenameColumn() error = %v", err)
+	}
+	if idx, ok := table.ColumnIndex("first_name"); !ok || idx != 1 {
+		t.Errorf("ColumnIndex(first_name) = %d, %v, want 1, true", idx, ok)
+	}
+	if _, ok := table.ColumnIndex("name"); ok {
+		t.Error("old name still resolves after RenameColumn()")
+	}
+	if err := table.RenameColumn("id", "age"); err == nil {
+		t.Error("RenameColumn() onto an existing name should fail")
+	}
+
+	// Renaming a filtered table leaves the table it came from alone
+	filtered := table.Filter(func([]string) bool { return true })
+	if err := filtered.RenameColumn("age", "years"); err != nil {
+		t.Fatalf("RenameColumn() error = %v", err)
+	}
+	if table.Headers[2] != "age" {
+		t.Errorf("source headers = %v after renaming a filtered copy, want age kept", table.Headers)
+	}
+	if _, ok := filtered.ColumnIndex("years"); !ok {
+		t.Error("ColumnIndex(years) not found after RenameColumn()")
+	}
+}
+
+func TestReorderColumns(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "name", "age"})
+	for _, row := range [][]string{{"1", "John", "30"}, {"2", "Jane", "25"}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	for _, order := range [][]string{
+		{"id", "name"},
+		{"id", "name", "salary"},
+		{"id", "id", "age"},
+	} {
+		if err := table.ReorderColumns(order); err == nil {
+			t.Errorf("ReorderColumns(%v) should fail", order)
+		}
+	}
+
+	if err := table.ReorderColumns([]string{"age", "id", "name"}); err != nil {
+		t.Fatalf("ReorderColumns() error = %v", err)
+	}
+	if want := []string{"age", "id", "name"}; !reflect.DeepEqual(table.Headers, want) {
+		t.Errorf("headers = %v, want %v", table.Headers, want)
+	}
+	if want := [][]string{{"30", "1", "John"}, {"25", "2", "Jane"}}; !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf("rows = %v, want %v", table.Rows, want)
+	}
+	if typ, _ := table.GetColumnType("name"); typ != pkg.TypeString {
+		t.Errorf("name type = %v, want TypeString", typ)
+	}
+	if col, _ := table.GetColumn("id"); !reflect.DeepEqual(col, []string{"1", "2"}) {
+		t.Errorf("GetColumn(id) = %v, want [1 2]", col)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	build := func(headers []string, rows ...[]string) *pkg.Table {
+		table := pkg.NewTable(headers)
+		for _, row := range rows {
+			if err := table.AddRow(row); err != nil {
+				t.Fatalf("AddRow() error = %v", err)
+			}
+		}
+		return table
+	}
+	base := build([]string{"id", "name"}, []string{"1", "John"}, []string{"2", "Jane"})
+
+	tests := []struct {
+		name  string
+		other *pkg.Table
+		want  bool
+	}{
+		{"copy", base.Copy(), true},
+		{"same content", build([]string{"id", "name"}, []string{"1", "John"}, []string{"2", "Jane"}), true},
+		{"different cell", build([]string{"id", "name"}, []string{"1", "John"}, []string{"2", "Jan"}), false},
+		{"different header", build([]string{"id", "first"}, []string{"1", "John"}, []string{"2", "Jane"}), false},
+		{"missing row", build([]string{"id", "name"}, []string{"1", "John"}), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.Equal(tt.other); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+			if got := base.EqualValues(tt.other); got != tt.want {
+				t.Errorf("EqualValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Tables differing only in their detected types are equal in value
+	input := "amount\n\"1,200\"\n950\n"
+	plain, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	formatted := plain.Copy()
+	formatted.SetNumberFormat(pkg.NumberFormat{ThousandsSeparator: ','})
+	if plain.Equal(formatted) {
+		t.Error("Equal() = true for tables with different column types, want false")
+	}
+	if !plain.EqualValues(formatted) {
+		t.Error("EqualValues() = false for tables with the same cells, want true")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	table := pkg.NewTable([]string{"name", "note"})
+	rows := [][]string{
+		{"plain", "no quoting"},
+		{"Smith; John", `said "hi"`},
+		{" padded ", "line one\nline two"},
+		{"", "empty name"},
+	}
+	for _, row := range rows {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	cfg := pkg.DefaultConfig()
+	cfg.Delimiter = ';'
+	var buf bytes.Buffer
+	if err := table.WriteCSV(&buf, cfg); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	want := "name;note\n" +
+		"plain;no quoting\n" +
+		`"Smith; John";"said ""hi"""` + "\n" +
+		"\" padded \";\"line one\nline two\"\n" +
+		";empty name\n"
+	if buf.String() != want {
+		t.Errorf("WriteCSV() = %q, want %q", buf.String(), want)
+	}
+
+	// The output reads back into the same table
+	back, err := pkg.ReadTable(&buf, cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if !table.Equal(back) {
+		t.Errorf("round trip = %v, want %v", back.Rows, table.Rows)
+	}
+}
+
+func TestExportToMarkdown(t *testing.T) {
+	table := pkg.NewTable([]string{"cmd", "desc"})
+	if err := table.AddRow([]string{"a|b", "first\nsecond"}); err != nil {
+		t.Fatalf("AddRow() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := table.ExportToMarkdown(&buf); err != nil {
+		t.Fatalf("ExportToMarkdown() error = %v", err)
+	}
+	want := "| cmd | desc |\n" +
+		"| --- | --- |\n" +
+		`| a\|b | first<br>second |` + "\n"
+	if buf.String() != want {
+		t.Errorf("ExportToMarkdown() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestExportToHTMLWithOptions(t *testing.T) {
+	table := pkg.NewTable([]string{"name", "bio"})
+	if err := table.AddRow([]string{"Eve", "<script>alert(1)</script>"}); err != nil {
+		t.Fatalf("AddRow() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	opts := pkg.HTMLOptions{Caption: "People", TableClass: "data wide", ID: "people", Minimal: true}
+	if err := table.ExportToHTMLWithOptions(&buf, opts); err != nil {
+		t.Fatalf("ExportToHTMLWithOptions() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<table id="people" class="data wide">`,
+		"<caption>People</caption>",
+		"<title>CSV Data</title>",
+		"<td>&lt;script&gt;alert(1)&lt;/script&gt;</td>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"<script>", "<style>"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("output contains %q:\n%s", unwanted, out)
+		}
+	}
+
+	// The defaults keep the stylesheet and add no caption or attributes
+	buf.Reset()
+	if err := table.ExportToHTML(&buf); err != nil {
+		t.Fatalf("ExportToHTML() error = %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "<style>") || !strings.Contains(out, "<table>") ||
+		strings.Contains(out, "<caption>") {
+		t.Errorf("ExportToHTML() = %s", out)
+	}
+}
+
+func TestExportToHTMLEscaping(t *testing.T) {
+	table := pkg.NewTable([]string{"a<b>"})
+	if err := table.AddRow([]string{"<b>Fish & Chips</b>"}); err != nil {
+		t.Fatalf("AddRow() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := table.ExportToHTML(&buf); err != nil {
+		t.Fatalf("ExportToHTML() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"<th>a&lt;b&gt;</th>", "<td>&lt;b&gt;Fish &amp; Chips&lt;/b&gt;</td>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<b>") {
+		t.Errorf("output contains an unescaped <b>:\n%s", out)
+	}
+}
+
+func TestWriteCSVLineTerminator(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "note"})
+	for _, row := range [][]string{{"1", "plain"}, {"2", "two\nlines"}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	cfg := pkg.DefaultConfig()
+	cfg.LineTerminator = "\r\n"
+	var buf bytes.Buffer
+	if err := table.WriteCSV(&buf, cfg); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	// Line breaks inside quoted fields are data and are left alone
+	want := "id,note\r\n1,plain\r\n2,\"two\nlines\"\r\n"
+	if buf.String() != want {
+		t.Errorf("WriteCSV() = %q, want %q", buf.String(), want)
+	}
+
+	back, err := pkg.ReadTable(&buf, pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if !table.Equal(back) {
+		t.Errorf("round trip = %q, want %q", back.Rows, table.Rows)
+	}
+
+	cfg.LineTerminator = "\r"
+	if err := table.WriteCSV(&buf, cfg); err == nil {
+		t.Error("expected an error for an invalid line terminator")
+	}
+}
+
+func TestHash(t *testing.T) {
+	build := func(rows ...[]string) *pkg.Table {
+		table := pkg.NewTable([]string{"id", "name"})
+		for _, row := range rows {
+			if err := table.AddRow(row); err != nil {
+				t.Fatalf("AddRow() error = %v", err)
+			}
+		}
+		return table
+	}
+
+	a := build([]string{"1", "Ann"}, []string{"2", "Bob"})
+	b := build([]string{"1", "Ann"}, []string{"2", "Bob"})
+	if a.Hash() != b.Hash() {
+		t.Error("identical tables hash differently")
+	}
+	if len(a.Hash()) != 64 {
+		t.Errorf("Hash() = %q, want 64 hex characters", a.Hash())
+	}
+
+	changed := build([]string{"1", "Ann"}, []string{"2", "Bobby"})
+	if a.Hash() == changed.Hash() {
+		t.Error("changing a cell did not change the hash")
+	}
+
+	// Field boundaries are part of the hash
+	shifted := build([]string{"1A", "nn"}, []string{"2", "Bob"})
+	if a.Hash() == shifted.Hash() {
+		t.Error("moving characters between cells did not change the hash")
+	}
+
+	reordered := build([]string{"2", "Bob"}, []string{"1", "Ann"})
+	if a.Hash() == reordered.Hash() {
+		t.Error("reordering rows did not change the ordered hash")
+	}
+	if a.ContentHash(true) != reordered.ContentHash(true) {
+		t.Error("reordered rows hash differently when order is ignored")
+	}
+	if a.ContentHash(true) == changed.ContentHash(true) {
+		t.Error("changing a cell did not change the unordered hash")
+	}
+	if !reflect.DeepEqual(reordered.Rows[0], []string{"2", "Bob"}) {
+		t.Error("ContentHash(true) reordered the table's rows")
+	}
+}
+
+func TestGetColumnAt(t *testing.T) {
+	cfg := pkg.DefaultConfig()
+	cfg.DuplicateHeaders = pkg.DuplicateHeadersAllow
+	table, err := pkg.ReadTable(strings.NewReader("id,value,value\n1,a,b\n2,c,d\n"), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	// Positions reach both columns that share a name
+	for idx, want := range [][]string{{"1", "2"}, {"a", "c"}, {"b", "d"}} {
+		col, err := table.GetColumnAt(idx)
+		if err != nil {
+			t.Fatalf("GetColumnAt(%d) error = %v", idx, err)
+		}
+		if !reflect.DeepEqual(col, want) {
+			t.Errorf("GetColumnAt(%d) = %v, want %v", idx, col, want)
+		}
+	}
+	if h, err := table.HeaderAt(2); err != nil || h != "value" {
+		t.Errorf("HeaderAt(2) = %q, %v, want \"value\"", h, err)
+	}
+
+	for _, idx := range []int{-1, 3} {
+		if _, err := table.GetColumnAt(idx); err == nil {
+			t.Errorf("GetColumnAt(%d) expected an error", idx)
+		}
+		if _, err := table.HeaderAt(idx); err == nil {
+			t.Errorf("HeaderAt(%d) expected an error", idx)
+		}
+	}
+}
+
+func TestRows2(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "name"})
+	rows := [][]string{{"1", "Ann"}, {"2", "Bob"}, {"3", "Cy"}}
+	for _, row := range rows {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	var seen []int
+	for i, row := range table.Rows2() {
+		want := map[string]string{"id": rows[i][0], "name": rows[i][1]}
+		if !reflect.DeepEqual(row, want) {
+			t.Errorf("row %d = %v, want %v", i, row, want)
+		}
+		row["name"] = "changed" // maps are copies
+		seen = append(seen, i)
+	}
+	if !reflect.DeepEqual(seen, []int{0, 1, 2}) {
+		t.Errorf("Rows2() yielded indices %v, want [0 1 2]", seen)
+	}
+	if table.Rows[0][1] != "Ann" {
+		t.Errorf("modifying a yielded map changed the table: %q", table.Rows[0][1])
+	}
+
+	// Breaking out of the loop stops the iteration
+	count := 0
+	for range table.Rows2() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("iterated %d times after break, want 1", count)
+	}
+}
+
+func TestDeleteWhere(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "score"})
+	for _, row := range [][]string{{"1", "90"}, {"2", "n/a"}, {"3", "75"}, {"4", "bad"}, {"5", "88"}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if typ, _ := table.GetColumnType("score"); typ != pkg.TypeString {
+		t.Fatalf("score type before delete = %v, want string", typ)
+	}
+
+	removed := table.DeleteWhere(func(row []string) bool {
+		return pkg.DetectType(row[1]) == pkg.TypeString
+	})
+	if removed != 2 {
+		t.Errorf("DeleteWhere() = %d, want 2", removed)
+	}
+	want := [][]string{{"1", "90"}, {"3", "75"}, {"5", "88"}}
+	if len(table.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %v", len(table.Rows), len(want), table.Rows)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(table.Rows[i], want[i]) {
+			t.Errorf("row %d = %v, want %v", i, table.Rows[i], want[i])
+		}
+	}
+	if typ, _ := table.GetColumnType("score"); typ != pkg.TypeInteger {
+		t.Errorf("score type after delete = %v, want integer", typ)
+	}
+
+	if removed := table.DeleteWhere(func([]string) bool { return false }); removed != 0 {
+		t.Errorf("DeleteWhere(none) = %d, want 0", removed)
+	}
+}
+
+func TestRecode(t *testing.T) {
+	tests := []struct {
+		name       string
+		defaultVal string
+		want       []string
+		wantType   pkg.ColumnType
+	}{
+		{"unknown values kept", "", []string{"Male", "Female", "X", ""}, pkg.TypeString},
+		{"unknown values replaced", "Other", []string{"Male", "Female", "Other", "Other"}, pkg.TypeString},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := pkg.NewTable([]string{"id", "sex"})
+			for _, row := range [][]string{{"1", "M"}, {"2", "F"}, {"3", "X"}, {"4", ""}} {
+				if err := table.AddRow(row); err != nil {
+					t.Fatalf("AddRow() error = %v", err)
+				}
+			}
+			if err := table.Recode("sex", map[string]string{"M": "Male", "F": "Female"}, tt.defaultVal); err != nil {
+				t.Fatalf("Recode() error = %v", err)
+			}
+			got, _ := table.GetColumn("sex")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Recode() column = %v, want %v", got, tt.want)
+			}
+			if colType, _ := table.GetColumnType("sex"); colType != tt.wantType {
+				t.Errorf("Recode() type = %v, want %v", colType, tt.wantType)
+			}
+		})
+	}
+
+	// Recoding to numbers changes the column type
+	table := pkg.NewTable([]string{"answer"})
+	if err := table.AppendRows([][]string{{"yes"}, {"no"}, {"yes"}}); err != nil {
+		t.Fatalf("AppendRows() error = %v", err)
+	}
+	if err := table.Recode("answer", map[string]string{"yes": "1", "no": "0"}, ""); err != nil {
+		t.Fatalf("Recode() error = %v", err)
+	}
+	if colType, _ := table.GetColumnType("answer"); colType != pkg.TypeInteger {
+		t.Errorf("Recode() type = %v, want integer", colType)
+	}
+
+	if err := table.Recode("missing", nil, ""); err == nil {
+		t.Error("Recode() on a missing column: want an error")
+	}
+
+	// Recoding a filtered table leaves the rows it shares with its source alone
+	source := pkg.NewTable([]string{"sex"})
+	if err := source.AppendRows([][]string{{"M"}, {"F"}}); err != nil {
+		t.Fatalf("AppendRows() error = %v", err)
+	}
+	filtered := source.Filter(func([]string) bool { return true })
+	if err := filtered.Recode("sex", map[string]string{"M": "Male"}, ""); err != nil {
+		t.Fatalf("Recode() error = %v", err)
+	}
+	if got, _ := source.GetColumn("sex"); !reflect.DeepEqual(got, []string{"M", "F"}) {
+		t.Errorf("source column after Recode() on a filtered copy = %v, want [M F]", got)
+	}
+}
+
+func TestIndexBy(t *testing.T) {
+	table, err := pkg.ReadTable(strings.NewReader("code,name\nUS,United States\nFR,France\nUS,USA\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	index, err := table.IndexBy("code")
+	if err != nil {
+		t.Fatalf("IndexBy() error = %v", err)
+	}
+	if len(index) != 2 {
+		t.Errorf("IndexBy() has %d keys, want 2", len(index))
+	}
+	if got := index["US"]; !reflect.DeepEqual(got, []string{"US", "United States"}) {
+		t.Errorf("index[US] = %v, want the first US row", got)
+	}
+	if got := index["FR"]; !reflect.DeepEqual(got, []string{"FR", "France"}) {
+		t.Errorf("index[FR] = %v, want [FR France]", got)
+	}
+	if _, ok := index["DE"]; ok {
+		t.Error("index[DE] found, want missing")
+	}
+
+	all, err := table.IndexAllBy("code")
+	if err != nil {
+		t.Fatalf("IndexAllBy() error = %v", err)
+	}
+	want := [][]string{{"US", "United States"}, {"US", "USA"}}
+	if !reflect.DeepEqual(all["US"], want) {
+		t.Errorf("IndexAllBy()[US] = %v, want %v", all["US"], want)
+	}
+
+	if _, err := table.IndexBy("missing"); err == nil {
+		t.Error("IndexBy(missing) error = nil, want error")
+	}
+	if _, err := table.IndexAllBy("missing"); err == nil {
+		t.Error("IndexAllBy(missing) error = nil, want error")
+	}
+}
+
+func TestBoolWords(t *testing.T) {
+	input := "name,subscribed,flag,count\nAnn,yes,1,1\nBob,No,0,0\nCy,,1,2\n"
+
+	// Without a vocabulary only true and false are booleans
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if typ, _ := table.GetColumnType("subscribed"); typ != pkg.TypeString {
+		t.Errorf("subscribed type = %v, want string without a vocabulary", typ)
+	}
+
+	cfg := pkg.DefaultConfig()
+	cfg.BoolTrue = []string{"yes"}
+	cfg.BoolFalse = []string{"no"}
+	table, err = pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	want := map[string]pkg.ColumnType{
+		"subscribed": pkg.TypeBoolean,
+		"flag":       pkg.TypeInteger, // 1 and 0 stay numbers unless listed
+	}
+	for col, wantType := range want {
+		if typ, _ := table.GetColumnType(col); typ != wantType {
+			t.Errorf("%s type = %v, want %v", col, typ, wantType)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := table.ExportToJSONL(&buf); err != nil {
+		t.Fatalf("ExportToJSONL() error = %v", err)
+	}
+	wantJSON := `{"name":"Ann","subscribed":true,"flag":1,"count":1}
+{"name":"Bob","subscribed":false,"flag":0,"count":0}
+{"name":"Cy","subscribed":"","flag":1,"count":2}
+`
+	if buf.String() != wantJSON {
+		t.Errorf("ExportToJSONL() = %s, want %s", buf.String(), wantJSON)
+	}
+
+	// Listing numbers opts in to reading them as booleans
+	table.SetBoolWords([]string{"yes", "1"}, []string{"no", "0"})
+	if typ, _ := table.GetColumnType("flag"); typ != pkg.TypeBoolean {
+		t.Errorf("flag type = %v, want boolean after SetBoolWords", typ)
+	}
+	if typ, _ := table.GetColumnType("count"); typ != pkg.TypeString {
+		t.Errorf("count type = %v, want string for a mix of words and numbers", typ)
+	}
+	vals, bad, err := table.GetColumnBools("subscribed")
+	if err != nil {
+		t.Fatalf("GetColumnBools() error = %v", err)
+	}
+	if !reflect.DeepEqual(vals, []bool{true, false}) || !reflect.DeepEqual(bad, []int{2}) {
+		t.Errorf("GetColumnBools() = %v, %v, want [true false], [2]", vals, bad)
+	}
+	type subscriber struct {
+		Name       string
+		Subscribed *bool
+	}
+	var subs []subscriber
+	if err := table.ToStructs(&subs); err != nil {
+		t.Fatalf("ToStructs() error = %v", err)
+	}
+	if len(subs) != 3 || subs[0].Subscribed == nil || !*subs[0].Subscribed ||
+		subs[1].Subscribed == nil || *subs[1].Subscribed || subs[2].Subscribed != nil {
+		t.Errorf("ToStructs() = %+v, want yes, no and null", subs)
+	}
+
+	// The vocabulary keeps LintCSV from reporting a mix of text and booleans
+	for _, w := range pkg.LintCSV(strings.NewReader("active\nyes\ntrue\nno\n"), cfg) {
+		if strings.Contains(w.Message, "mixes value types") {
+			t.Errorf("LintCSV() warning %v for boolean words", w)
+		}
+	}
+}
+
+func TestShapeAndMemoryEstimate(t *testing.T) {
+	build := func(n int) *pkg.Table {
+		table := pkg.NewTable([]string{"id", "name", "note"})
+		for i := 0; i < n; i++ {
+			if err := table.AddRow([]string{strconv.Itoa(i), "name", "a fairly long note"}); err != nil {
+				t.Fatalf("AddRow() error = %v", err)
+			}
+		}
+		return table
+	}
+
+	small, large := build(100), build(1000)
+	if rows, cols := large.Shape(); rows != 1000 || cols != 3 {
+		t.Errorf("Shape() = %d, %d, want 1000, 3", rows, cols)
+	}
+	if rows, cols := pkg.NewTable(nil).Shape(); rows != 0 || cols != 0 {
+		t.Errorf("empty Shape() = %d, %d, want 0, 0", rows, cols)
+	}
+
+	// The estimate covers at least the cell text and grows with the data
+	smallEst, largeEst := small.MemoryEstimate(), large.MemoryEstimate()
+	if smallEst <= 100*int64(len("name")+len("a fairly long note")) {
+		t.Errorf("MemoryEstimate() = %d, want more than the text of the cells", smallEst)
+	}
+	if ratio := float64(largeEst) / float64(smallEst); ratio < 9 || ratio > 11 {
+		t.Errorf("MemoryEstimate() grew %.1fx for 10x the rows, want about 10x", ratio)
+	}
+
+	for n, want := range map[int64]string{512: "512 B", 1536: "1.5 KiB", 3 << 30: "3.0 GiB"} {
+		if got := pkg.FormatBytes(n); got != want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestAddRowNumberColumn(t *testing.T) {
+	table, err := pkg.ReadTable(strings.NewReader("name,dept\nAnn,IT\nBob,HR\nCy,IT\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if err := table.AddRowNumberColumn("row", 1); err != nil {
+		t.Fatalf("AddRowNumberColumn() error = %v", err)
+	}
+	if !reflect.DeepEqual(table.Headers, []string{"row", "name", "dept"}) {
+		t.Errorf("headers = %v, want [row name dept]", table.Headers)
+	}
+	if col, _ := table.GetColumn("dept"); !reflect.DeepEqual(col, []string{"IT", "HR", "IT"}) {
+		t.Errorf("dept = %v after inserting a column, want [IT HR IT]", col)
+	}
+
+	var buf bytes.Buffer
+	if err := table.ExportToJSON(&buf); err != nil {
+		t.Fatalf("ExportToJSON() error = %v", err)
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	for i, row := range rows {
+		if row["row"] != float64(i+1) {
+			t.Errorf("row %d has row number %v, want %d", i, row["row"], i+1)
+		}
+	}
+
+	// Another start, and a name already taken
+	if err := table.AddRowNumberColumn("idx", 0); err != nil {
+		t.Fatalf("AddRowNumberColumn() error = %v", err)
+	}
+	if col, _ := table.GetColumn("idx"); !reflect.DeepEqual(col, []string{"0", "1", "2"}) {
+		t.Errorf("idx = %v, want [0 1 2]", col)
+	}
+	if err := table.AddRowNumberColumn("name", 1); err == nil {
+		t.Error("AddRowNumberColumn(name) error = nil, want error for an existing column")
+	}
+}
+
+func TestClip(t *testing.T) {
+	table, err := pkg.ReadTable(strings.NewReader("id,score\n1,-5\n2,40\n3,\n4,250\n5,100\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if err := table.Clip("score", 0, 100); err != nil {
+		t.Fatalf("Clip() error = %v", err)
+	}
+	col, _ := table.GetColumn("score")
+	if want := []string{"0", "40", "", "100", "100"}; !reflect.DeepEqual(col, want) {
+		t.Errorf("score = %q, want %q", col, want)
+	}
+	if typ, _ := table.GetColumnType("score"); typ != pkg.TypeInteger {
+		t.Errorf("score type = %v, want integer", typ)
+	}
+
+	// A fractional bound makes the column float
+	if err := table.Clip("score", 0.5, 99.5); err != nil {
+		t.Fatalf("Clip() error = %v", err)
+	}
+	col, _ = table.GetColumn("score")
+	if want := []string{"0.5", "40", "", "99.5", "99.5"}; !reflect.DeepEqual(col, want) {
+		t.Errorf("score = %q, want %q", col, want)
+	}
+	if typ, _ := table.GetColumnType("score"); typ != pkg.TypeFloat {
+		t.Errorf("score type = %v, want float", typ)
+	}
+
+	if err := table.Clip("missing", 0, 1); err == nil {
+		t.Error("Clip(missing) error = nil, want error")
+	}
+	if err := table.Clip("score", 10, 1); err == nil {
+		t.Error("Clip() with min > max error = nil, want error")
+	}
+	// Clipping a filtered table leaves the rows it shares with its source alone
+	source, err := pkg.ReadTable(strings.NewReader("sex,score\nF,200\nM,5\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	filtered := source.Filter(func([]string) bool { return true })
+	if err := filtered.Clip("score", 0, 10); err != nil {
+		t.Fatalf("Clip() error = %v", err)
+	}
+	if got, _ := source.GetColumn("score"); !reflect.DeepEqual(got, []string{"200", "5"}) {
+		t.Errorf("source column after Clip() on a filtered copy = %v, want [200 5]", got)
+	}
+}
@@ -1,8 +1,13 @@
 package pkg_test
 
 import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/ooyeku/csv_parser/pkg"
 )
@@ -249,3 +254,367 @@ func equalStringSlices(a, b []string) bool {
 	}
 	return true
 }
+
+func TestFormatFitToWidth(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "name", "description", "notes"})
+	rows := [][]string{
+		{"1", "Alice", strings.Repeat("a long description ", 6), strings.Repeat("note ", 12)},
+		{"2", "Bob", "short", strings.Repeat("x", 70)},
+	}
+	for _, row := range rows {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+	ansi := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+	for _, width := range []int{40, 60, 80} {
+		for _, wrap := range []bool{true, false} {
+			opts := pkg.DefaultFormat()
+			opts.FitToWidth = width
+			opts.WrapText = wrap
+			opts.NumberedRows = true
+			output := table.Format(opts)
+
+			for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+				if n := utf8.RuneCountInString(ansi.ReplaceAllString(line, "")); n > width {
+					t.Errorf("width %d, wrap %v: line is %d wide: %q", width, wrap, n, line)
+				}
+			}
+			// Narrow columns keep their full width
+			if !strings.Contains(output, "Alice") {
+				t.Errorf("width %d, wrap %v: narrow column was truncated:\n%s", width, wrap, output)
+			}
+		}
+	}
+
+	// A table that already fits is unchanged
+	small := pkg.NewTable([]string{"a", "b"})
+	if err := small.AddRow([]string{"1", "2"}); err != nil {
+		t.Fatalf("AddRow() error = %v", err)
+	}
+	opts := pkg.DefaultFormat()
+	want := small.Format(opts)
+	opts.FitToWidth = 80
+	if got := small.Format(opts); got != want {
+		t.Errorf("Format() with room to spare = %q, want %q", got, want)
+	}
+}
+
+func TestFormatAlternateRowBand(t *testing.T) {
+	table := pkg.NewTable([]string{"Name", "City"})
+	for _, row := range [][]string{{"John", "Boston"}, {"Jane", "Denver"}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	for _, wrap := range []bool{false, true} {
+		opts := pkg.DefaultFormat()
+		opts.WrapText = wrap
+		opts.AlternateColor = pkg.BgBlue
+		lines := strings.Split(table.Format(opts), "\n")
+
+		// Top border, header, separator, then the two data rows
+		band := opts.BorderColor + opts.Style.Vertical + pkg.Reset +
+			pkg.BgBlue + " Jane │ Denver │" + pkg.Reset
+		if lines[4] != band {
+			t.Errorf("wrap %v: alternate row = %q, want %q", wrap, lines[4], band)
+		}
+		if strings.Contains(lines[3], pkg.BgBlue) {
+			t.Errorf("wrap %v: first row should not be colored: %q", wrap, lines[3])
+		}
+	}
+}
+
+func TestFormatCellStyle(t *testing.T) {
+	table := pkg.NewTable([]string{"Item", "Delta"})
+	for _, row := range [][]string{{"a", "5"}, {"b", "-3"}, {"c", "-1"}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	opts := pkg.DefaultFormat()
+	opts.WrapText = false
+	opts.AlternateColor = pkg.BgBlue
+	var calls int
+	opts.CellStyle = func(row, col int, value string) string {
+		calls++
+		if col == 1 && strings.HasPrefix(value, "-") {
+			return pkg.Magenta
+		}
+		return ""
+	}
+	lines := strings.Split(table.Format(opts), "\n")
+
+	if calls != 6 {
+		t.Errorf("CellStyle called %d times, want 6", calls)
+	}
+	if strings.Contains(lines[3], pkg.Magenta) {
+		t.Errorf("positive cell should not be styled: %q", lines[3])
+	}
+	// Second row: the style is applied and the band is restored after it
+	if want := pkg.Magenta + "-3   " + pkg.Reset + pkg.BgBlue + " │"; !strings.Contains(lines[4], want) {
+		t.Errorf("styled alternate row = %q, want it to contain %q", lines[4], want)
+	}
+	if want := pkg.Magenta + "-1   " + pkg.Reset + " │"; !strings.Contains(lines[5], want) {
+		t.Errorf("styled row = %q, want it to contain %q", lines[5], want)
+	}
+
+	// Without a callback the output is unchanged
+	plain := pkg.DefaultFormat()
+	withNil := plain
+	withNil.CellStyle = func(row, col int, value string) string { return "" }
+	if table.Format(plain) != table.Format(withNil) {
+		t.Error("an empty CellStyle prefix should not change the output")
+	}
+}
+
+func TestFormatWrapsHeaders(t *testing.T) {
+	header := "average monthly revenue in euro" // 31 characters
+	table := pkg.NewTable([]string{"id", header})
+	if err := table.AddRow([]string{"1", "42"}); err != nil {
+		t.Fatalf("AddRow() error = %v", err)
+	}
+
+	opts := pkg.FormatOptions{
+		Style:          pkg.DefaultStyle,
+		MaxColumnWidth: 10,
+		WrapText:       true,
+	}
+	ansi := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	lines := strings.Split(ansi.ReplaceAllString(table.Format(opts), ""), "\n")
+
+	want := []string{
+		"+----+------------+",
+		"| id |  average   |",
+		"|    |  monthly   |",
+		"|    | revenue in |",
+		"|    |    euro    |",
+		"+----+------------+",
+		"| 1  | 42         |",
+	}
+	for i, w := range want {
+		if lines[i] != w {
+			t.Errorf("line %d = %q, want %q", i, lines[i], w)
+		}
+	}
+
+	// Without wrapping the header keeps its full width
+	opts.WrapText = false
+	if out := table.Format(opts); !strings.Contains(out, header) {
+		t.Errorf("unwrapped header should be shown in full:\n%s", out)
+	}
+}
+
+func TestFormatColumnFormat(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "price", "name"})
+	for _, row := range [][]string{{"42", "3.5", "Widget"}, {"n/a", "10", "Gadget"}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+
+	opts := pkg.FormatOptions{
+		Style: pkg.DefaultStyle,
+		ColumnFormat: map[string]string{
+			"id":    "%05d",
+			"price": "%.2f",
+			"name":  "%q",
+		},
+	}
+	ansi := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	lines := strings.Split(ansi.ReplaceAllString(table.Format(opts), ""), "\n")
+
+	if want := `| 00042 | 3.50  | "Widget" |`; lines[3] != want {
+		t.Errorf("formatted row = %q, want %q", lines[3], want)
+	}
+	// Cells the verb does not apply to are left alone
+	if want := `| n/a   | 10.00 | "Gadget" |`; lines[4] != want {
+		t.Errorf("formatted row = %q, want %q", lines[4], want)
+	}
+	if table.Rows[0][0] != "42" {
+		t.Errorf("Format changed the table data to %q", table.Rows[0][0])
+	}
+}
+
+func TestFormatAlignmentByName(t *testing.T) {
+	table := pkg.NewTable([]string{"name", "qty", "code"})
+	for _, row := range [][]string{{"Widget", "5", "A"}, {"Gadget", "12345", "BBBBB"}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatalf("AddRow() error = %v", err)
+		}
+	}
+	opts := pkg.FormatOptions{
+		Style:           pkg.DefaultStyle,
+		Alignment:       []string{"left", "left", "center"},
+		AlignmentByName: map[string]string{"qty": "right", "missing": "right"},
+	}
+
+	ansi := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	lines := strings.Split(ansi.ReplaceAllString(table.Format(opts), ""), "\n")
+	if want := "| Widget |     5 |   A   |"; lines[3] != want {
+		t.Errorf("formatted row = %q, want %q", lines[3], want)
+	}
+
+	// The named alignment follows the column when it moves; the positional
+	// ones stay with their positions
+	if err := table.ReorderColumns([]string{"qty", "name", "code"}); err != nil {
+		t.Fatal(err)
+	}
+	lines = strings.Split(ansi.ReplaceAllString(table.Format(opts), ""), "\n")
+	if want := "|     5 | Widget |   A   |"; lines[3] != want {
+		t.Errorf("formatted row after reordering = %q, want %q", lines[3], want)
+	}
+}
+
+func TestColumnWidths(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "description"})
+	for _, row := range [][]string{{"1", "short"}, {"12345", "a longer value"}} {
+		if err := table.AddRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(step string, want ...int) {
+		t.Helper()
+		got := table.ColumnWidths()
+		if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("%s: ColumnWidths() = %v, want %v", step, got, want)
+		}
+	}
+	check("initial", 5, 14)
+
+	// AddRow keeps the cache current, and formatting uses it
+	if err := table.AddRow([]string{"7", "the longest value of all"}); err != nil {
+		t.Fatal(err)
+	}
+	check("after AddRow", 5, 24)
+	if !strings.Contains(table.Format(pkg.FormatOptions{Style: pkg.DefaultStyle}), "| the longest value of all |") {
+		t.Error("Format() did not size the column to its widest cell")
+	}
+
+	table.DeleteWhere(func(row []string) bool { return row[0] == "7" })
+	check("after DeleteWhere", 5, 14)
+
+	// Rows appended directly change the row count, so the cache is rebuilt
+	table.Rows = append(table.Rows, []string{"1234567", "x"})
+	check("after appending to Rows", 7, 14)
+
+	if err := table.ReorderColumns([]string{"description", "id"}); err != nil {
+		t.Fatal(err)
+	}
+	check("after ReorderColumns", 14, 7)
+}
+
+func TestFormatANSIWidth(t *testing.T) {
+	ansi := regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	render := func(value string) string {
+		table := pkg.NewTable([]string{"status", "note"})
+		if err := table.AddRow([]string{value, "ok"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := table.AddRow([]string{"pending", "café"}); err != nil {
+			t.Fatal(err)
+		}
+		return ansi.ReplaceAllString(table.Format(pkg.FormatOptions{Style: pkg.DefaultStyle}), "")
+	}
+
+	plain := render("active")
+	colored := render(pkg.Green + "active" + pkg.Reset)
+	if colored != plain {
+		t.Errorf("colored cell rendered as\n%s\nwant\n%s", colored, plain)
+	}
+	if !strings.Contains(plain, "| café |") {
+		t.Errorf("multibyte cell misaligned:\n%s", plain)
+	}
+
+	if got := pkg.DisplayWidth(pkg.Bold + pkg.Magenta + "héllo" + pkg.Reset); got != 5 {
+		t.Errorf("DisplayWidth() = %d, want 5", got)
+	}
+	cell := pkg.FormatCell(pkg.Magenta+"abcdefghij"+pkg.Reset, 6, "left")
+	if got := ansi.ReplaceAllString(cell, ""); got != "abc..." {
+		t.Errorf("FormatCell() truncated to %q, want %q", got, "abc...")
+	}
+	if !strings.HasPrefix(cell, pkg.Magenta) || !strings.Contains(cell, pkg.Reset) {
+		t.Errorf("FormatCell() = %q, want the color kept and reset", cell)
+	}
+	if got := pkg.FormatCell(pkg.Magenta+"ab"+pkg.Reset, 4, "right"); got != "  "+pkg.Magenta+"ab"+pkg.Reset {
+		t.Errorf("FormatCell(right) = %q", got)
+	}
+}
+
+// failingWriter accepts limit bytes, then fails
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errors.New("disk full")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+func TestWriteFormatted(t *testing.T) {
+	table := pkg.NewTable([]string{"id", "description", "price"})
+	for i := 0; i < 2000; i++ {
+		desc := "short"
+		if i%3 == 0 {
+			desc = "a much longer description that has to be wrapped"
+		}
+		if err := table.AddRow([]string{strconv.Itoa(i), desc, strconv.Itoa(i * 7)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	numbered := pkg.DefaultFormat()
+	numbered.NumberedRows = true
+	numbered.MaxColumnWidth = 12
+	formatted := pkg.DefaultFormat()
+	formatted.ColumnFormat = map[string]string{"price": "%08.2f"}
+	formatted.WrapText = false
+	formatted.CellStyle = func(row, col int, value string) string {
+		if col == 0 && row%2 == 0 {
+			return pkg.Green
+		}
+		return ""
+	}
+	tests := []struct {
+		name  string
+		table *pkg.Table
+		opts  pkg.FormatOptions
+	}{
+		{"default", table, pkg.DefaultFormat()},
+		{"numbered and wrapped", table, numbered},
+		{"column format", table, formatted},
+		{"empty", pkg.NewTable(nil), pkg.DefaultFormat()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := tt.table.WriteFormatted(&buf, tt.opts)
+			if err != nil {
+				t.Fatalf("WriteFormatted() error = %v", err)
+			}
+			want := tt.table.Format(tt.opts)
+			if buf.String() != want {
+				t.Errorf("WriteFormatted() output differs from Format()")
+			}
+			if n != int64(len(want)) {
+				t.Errorf("WriteFormatted() = %d bytes, want %d", n, len(want))
+			}
+		})
+	}
+
+	n, err := table.WriteFormatted(&failingWriter{limit: 10000}, pkg.DefaultFormat())
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("WriteFormatted() error = %v, want disk full", err)
+	}
+	if n != 10000 {
+		t.Errorf("WriteFormatted() = %d bytes after a failed write, want 10000", n)
+	}
+}
@@ -0,0 +1,64 @@
+package pkg_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestUpsert(t *testing.T) {
+	base, err := pkg.ReadTable(strings.NewReader("region,id,name,salary\neu,1,John,1000\nus,1,Jane,2000\neu,2,Bob,1500\neu,2,Bob,1500\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	// Columns reordered; John and both Bob rows updated, Amy added, and a
+	// salary that is no longer an integer
+	delta, err := pkg.ReadTable(strings.NewReader("id,region,salary,name\n1,eu,1100,John\n2,eu,1600.5,Robert\n3,us,1800,Amy\n"), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	if err := base.Upsert(delta, []string{"region", "id"}); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	want := [][]string{
+		{"eu", "1", "John", "1100"},
+		{"us", "1", "Jane", "2000"},
+		{"eu", "2", "Robert", "1600.5"},
+		{"eu", "2", "Robert", "1600.5"},
+		{"us", "3", "Amy", "1800"},
+	}
+	if !reflect.DeepEqual(base.Rows, want) {
+		t.Errorf("rows = %q, want %q", base.Rows, want)
+	}
+	if typ, _ := base.GetColumnType("salary"); typ != pkg.TypeFloat {
+		t.Errorf("salary type = %v, want float", typ)
+	}
+
+	// Updated rows must not alias each other
+	base.Rows[2][2] = "Bobby"
+	if base.Rows[3][2] != "Robert" {
+		t.Error("rows updated from the same delta row share storage")
+	}
+}
+
+func TestUpsertErrors(t *testing.T) {
+	base := pkg.NewTable([]string{"id", "name"})
+	tests := []struct {
+		name    string
+		other   *pkg.Table
+		keyCols []string
+	}{
+		{"no key", pkg.NewTable([]string{"id", "name"}), nil},
+		{"missing key", pkg.NewTable([]string{"id", "name"}), []string{"code"}},
+		{"extra column", pkg.NewTable([]string{"id", "name", "age"}), []string{"id"}},
+		{"different column", pkg.NewTable([]string{"id", "title"}), []string{"id"}},
+	}
+	for _, tt := range tests {
+		if err := base.Upsert(tt.other, tt.keyCols); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
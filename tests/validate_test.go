@@ -0,0 +1,178 @@
+package pkg_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestValidate(t *testing.T) {
+	input := "id,name,score,notes\n1,John,9.5,\n2,,7,\n3,Bob,8,\n"
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	if errs := table.Validate(false); len(errs) != 0 {
+		t.Errorf("Validate(false) = %v, want no errors", errs)
+	}
+
+	// The empty name is reported, the entirely empty notes column is not
+	errs := table.Validate(true)
+	if len(errs) != 1 {
+		t.Fatalf("Validate(true) = %v, want 1 error", errs)
+	}
+	if errs[0].Row != 2 || errs[0].Column != "name" {
+		t.Errorf("Validate(true) error = %+v, want row 2, column name", errs[0])
+	}
+	if !strings.Contains(errs[0].Error(), "Row 2, Column name") {
+		t.Errorf("ValidationError.Error() = %q", errs[0].Error())
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	input := "id,age,email,status\n" +
+		"1,30,john@example.com,active\n" +
+		"2,130,jane@example.com,inactive\n" +
+		"3,,bob-at-example,active\n" +
+		"4,25,amy@example.com,banned\n" +
+		"x,40,tom@example.com,active\n"
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	schemaJSON := `[
+		{"name": "id", "type": "integer"},
+		{"name": "age", "type": "integer", "required": true, "min": 0, "max": 120},
+		{"name": "email", "pattern": "[^@]+@[^@]+"},
+		{"name": "status", "allowed_values": ["active", "inactive"]},
+		{"name": "country", "required": true}
+	]`
+	schema, err := pkg.LoadSchema(strings.NewReader(schemaJSON))
+	if err != nil {
+		t.Fatalf("LoadSchema() error = %v", err)
+	}
+
+	type key struct {
+		row    int
+		column string
+	}
+	want := map[key]string{
+		{5, "id"}:      "not of type integer",
+		{2, "age"}:     "greater than maximum",
+		{3, "age"}:     "Required value is missing",
+		{3, "email"}:   "does not match pattern",
+		{4, "status"}:  "not one of the allowed values",
+		{0, "country"}: "Required column is missing",
+	}
+
+	errs := table.ValidateSchema(schema)
+	if len(errs) != len(want) {
+		t.Errorf("ValidateSchema() returned %d errors, want %d: %v", len(errs), len(want), errs)
+	}
+	for _, e := range errs {
+		msg, ok := want[key{e.Row, e.Column}]
+		if !ok {
+			t.Errorf("unexpected error %v", e)
+			continue
+		}
+		if !strings.Contains(e.Message, msg) {
+			t.Errorf("error %v should contain %q", e, msg)
+		}
+	}
+}
+
+func TestLoadSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"invalid json", `[{"name": }]`},
+		{"unknown field", `[{"name": "a", "maximum": 3}]`},
+		{"missing name", `[{"type": "integer"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := pkg.LoadSchema(strings.NewReader(tt.input)); err == nil {
+				t.Error("LoadSchema() expected error")
+			}
+		})
+	}
+}
+
+func TestValidateSample(t *testing.T) {
+	// An empty name early on, a row that cannot be parsed later
+	input := "id,name\n1,John\n2,\n3,Bob\n4,\"unterminated\n"
+	table, err := pkg.ReadTableN(strings.NewReader(input), pkg.DefaultConfig(), 3)
+	if err != nil {
+		t.Fatalf("ReadTableN() error = %v", err)
+	}
+	if len(table.Rows) != 3 {
+		t.Fatalf("ReadTableN() read %d rows, want 3", len(table.Rows))
+	}
+	errs := table.Validate(true)
+	if len(errs) != 1 || errs[0].Row != 2 {
+		t.Errorf("Validate(true) = %v, want the empty name in row 2", errs)
+	}
+}
+
+func TestValidateNumberFormat(t *testing.T) {
+	input := "amt,price\n\"1,234\",$9.50\n12,\"$1,000\"\n"
+	cfg := pkg.DefaultConfig()
+	cfg.NumberFormat = pkg.NumberFormat{ThousandsSeparator: ',', CurrencySymbols: []string{"$"}}
+	table, err := pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if colType, _ := table.GetColumnType("amt"); colType != pkg.TypeInteger {
+		t.Fatalf("amt type = %v, want integer", colType)
+	}
+
+	// Columns typed through the number format pass their own validation
+	if errs := table.Validate(true); len(errs) != 0 {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+
+	lo, hi := 10.0, 1000.0
+	errs := table.ValidateSchema(pkg.Schema{
+		{Name: "amt", Type: "integer", Min: &lo},
+		{Name: "price", Type: "float", Max: &hi},
+	})
+	if len(errs) != 0 {
+		t.Errorf("ValidateSchema() = %v, want no errors", errs)
+	}
+	hi = 999
+	errs = table.ValidateSchema(pkg.Schema{{Name: "price", Max: &hi}})
+	if len(errs) != 1 || errs[0].Row != 2 {
+		t.Errorf("ValidateSchema() = %v, want only $1,000 over the maximum", errs)
+	}
+}
+
+func TestValidateBoolWords(t *testing.T) {
+	input := "id,active\n1,yes\n2,No\n3,true\n4,maybe\n"
+	cfg := pkg.DefaultConfig()
+	cfg.BoolTrue, cfg.BoolFalse = []string{"yes"}, []string{"no"}
+	table, err := pkg.ReadTable(strings.NewReader(input), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	errs := table.ValidateSchema(pkg.Schema{{Name: "active", Type: "boolean"}})
+	if len(errs) != 1 || errs[0].Value != "maybe" {
+		t.Errorf("ValidateSchema() = %v, want only maybe rejected", errs)
+	}
+
+	// A column detected as boolean through the words validates cleanly
+	table, err = pkg.ReadTable(strings.NewReader("id,active\n1,yes\n2,No\n3,true\n"), cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if colType, _ := table.GetColumnType("active"); colType != pkg.TypeBoolean {
+		t.Fatalf("active type = %v, want boolean", colType)
+	}
+	if errs := table.Validate(true); len(errs) != 0 {
+		t.Errorf("Validate() = %v, want no errors", errs)
+	}
+}
@@ -0,0 +1,153 @@
+package pkg_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+)
+
+func TestWriterQuoting(t *testing.T) {
+	records := [][]string{
+		{"id", "name", "note"},
+		{"42", "Ann", ""},
+		{"7", `say "hi"`, "a,b"},
+	}
+
+	tests := []struct {
+		name string
+		cfg  func(*pkg.Config)
+		want string
+	}{
+		{"minimal", func(*pkg.Config) {},
+			"id,name,note\n42,Ann,\n7,\"say \"\"hi\"\"\",\"a,b\"\n"},
+		{"quote all", func(c *pkg.Config) { c.QuoteAll = true },
+			"\"id\",\"name\",\"note\"\n\"42\",\"Ann\",\"\"\n\"7\",\"say \"\"hi\"\"\",\"a,b\"\n"},
+		{"quote empty", func(c *pkg.Config) { c.QuoteEmpty = true },
+			"id,name,note\n42,Ann,\"\"\n7,\"say \"\"hi\"\"\",\"a,b\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := pkg.DefaultConfig()
+			tt.cfg(&cfg)
+			var buf bytes.Buffer
+			w, err := pkg.NewWriter(&buf, cfg)
+			if err != nil {
+				t.Fatalf("NewWriter() error = %v", err)
+			}
+			for _, record := range records {
+				if err := w.Write(record); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+
+			// Every variant reads back to the same values
+			reader, err := pkg.NewReader(&buf, pkg.DefaultConfig())
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+			for i, want := range records {
+				got, err := reader.ReadRecord()
+				if err != nil {
+					t.Fatalf("ReadRecord() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("record %d = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriterCommentRoundTrip(t *testing.T) {
+	cfg := pkg.DefaultConfig()
+	cfg.Comment = '#'
+	input := "a,b\n\"#x\",1\ny,#2\n"
+
+	// A first field starting with the comment character stays quoted, so
+	// the row is not read back as a comment
+	var out bytes.Buffer
+	if err := pkg.TransformStream(strings.NewReader(input), &out, cfg, nil); err != nil {
+		t.Fatalf("TransformStream() error = %v", err)
+	}
+	if want := "a,b\n\"#x\",1\ny,#2\n"; out.String() != want {
+		t.Errorf("TransformStream() wrote %q, want %q", out.String(), want)
+	}
+	table, err := pkg.ReadTable(&out, cfg)
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if want := [][]string{{"#x", "1"}, {"y", "#2"}}; !reflect.DeepEqual(table.Rows, want) {
+		t.Errorf("rows read back = %q, want %q", table.Rows, want)
+	}
+
+	// With inline comments any field may start one
+	cfg.AllowInlineComments = true
+	out.Reset()
+	w, err := pkg.NewWriter(&out, cfg)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	if err := w.Write([]string{"y", "#2"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if want := "y,\"#2\"\n"; out.String() != want {
+		t.Errorf("output with inline comments = %q, want %q", out.String(), want)
+	}
+}
+
+func TestWriteCSVAppend(t *testing.T) {
+	batches := []string{
+		"id,name\n1,Ann\n2,Bob\n",
+		"id,name\n3,Cy\n",
+		"id,name\n4,Dee\n5,Eve\n",
+	}
+
+	var out bytes.Buffer
+	for i, batch := range batches {
+		table, err := pkg.ReadTable(strings.NewReader(batch), pkg.DefaultConfig())
+		if err != nil {
+			t.Fatalf("ReadTable() error = %v", err)
+		}
+		cfg := pkg.DefaultConfig()
+		cfg.SkipHeaderOnWrite = i > 0
+		if err := table.WriteCSV(&out, cfg); err != nil {
+			t.Fatalf("WriteCSV() error = %v", err)
+		}
+	}
+
+	want := "id,name\n1,Ann\n2,Bob\n3,Cy\n4,Dee\n5,Eve\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	table, err := pkg.ReadTable(&out, pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+	if len(table.Rows) != 5 {
+		t.Errorf("rows = %d, want 5", len(table.Rows))
+	}
+
+	// TranscodeStream honors the option too
+	var streamed bytes.Buffer
+	cfg := pkg.DefaultConfig()
+	cfg.SkipHeaderOnWrite = true
+	if err := pkg.TranscodeStream(strings.NewReader(batches[1]), &streamed, pkg.DefaultConfig(), cfg, nil); err != nil {
+		t.Fatalf("TranscodeStream() error = %v", err)
+	}
+	if streamed.String() != "3,Cy\n" {
+		t.Errorf("TranscodeStream() output = %q, want %q", streamed.String(), "3,Cy\n")
+	}
+}
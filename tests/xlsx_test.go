@@ -0,0 +1,97 @@
+package pkg_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ooyeku/csv_parser/pkg"
+	"github.com/xuri/excelize/v2"
+)
+
+// openXLSX opens a workbook written by ExportToXLSX
+func openXLSX(t *testing.T, data []byte) *excelize.File {
+	t.Helper()
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("excelize.OpenReader() error = %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestExportToXLSX(t *testing.T) {
+	input := "name,amount,active,zip\nAnn,12.5,true,02134\nBob <b>,,false,10001\nCy,7,TRUE,94103\n"
+	table, err := pkg.ReadTable(strings.NewReader(input), pkg.DefaultConfig())
+	if err != nil {
+		t.Fatalf("ReadTable() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := table.ExportToXLSX(&buf, pkg.XLSXOptions{SheetName: "Sales", FreezeHeader: true}); err != nil {
+		t.Fatalf("ExportToXLSX() error = %v", err)
+	}
+	f := openXLSX(t, buf.Bytes())
+
+	if sheets := f.GetSheetList(); !reflect.DeepEqual(sheets, []string{"Sales"}) {
+		t.Fatalf("sheets = %v, want [Sales]", sheets)
+	}
+	if panes, err := f.GetPanes("Sales"); err != nil || !panes.Freeze || panes.YSplit != 1 {
+		t.Errorf("GetPanes() = %+v, %v, want the header row frozen", panes, err)
+	}
+	if style, err := f.GetCellStyle("Sales", "A1"); err != nil || style == 0 {
+		t.Errorf("header cell style = %d, %v, want a header style", style, err)
+	} else if s, err := f.GetStyle(style); err != nil || s.Font == nil || !s.Font.Bold {
+		t.Errorf("header style = %+v, %v, want bold", s, err)
+	}
+
+	tests := []struct {
+		ref   string
+		typ   excelize.CellType
+		value string
+	}{
+		{"A1", excelize.CellTypeInlineString, "name"},
+		{"B2", excelize.CellTypeUnset, "12.5"}, // numbers are stored as numbers
+		{"B4", excelize.CellTypeUnset, "7"},    // including integers in a float column
+		{"C2", excelize.CellTypeBool, "1"},     // booleans as booleans
+		{"C4", excelize.CellTypeBool, "1"},     // in any case
+		{"A3", excelize.CellTypeInlineString, "Bob <b>"},
+		{"D2", excelize.CellTypeInlineString, "02134"}, // a leading zero would be lost
+		{"D3", excelize.CellTypeUnset, "10001"},
+		{"B3", excelize.CellTypeUnset, ""}, // null cells are left empty
+	}
+	for _, tt := range tests {
+		typ, err := f.GetCellType("Sales", tt.ref)
+		if err != nil {
+			t.Fatalf("GetCellType(%s) error = %v", tt.ref, err)
+		}
+		value, err := f.GetCellValue("Sales", tt.ref, excelize.Options{RawCellValue: true})
+		if err != nil {
+			t.Fatalf("GetCellValue(%s) error = %v", tt.ref, err)
+		}
+		if typ != tt.typ || value != tt.value {
+			t.Errorf("cell %s = type %v, value %q, want type %v, value %q", tt.ref, typ, value, tt.typ, tt.value)
+		}
+	}
+
+	// The defaults name the sheet Sheet1 and do not freeze the header
+	buf.Reset()
+	if err := table.ExportToXLSX(&buf, pkg.XLSXOptions{}); err != nil {
+		t.Fatalf("ExportToXLSX() error = %v", err)
+	}
+	f = openXLSX(t, buf.Bytes())
+	if sheets := f.GetSheetList(); !reflect.DeepEqual(sheets, []string{"Sheet1"}) {
+		t.Errorf("sheets = %v, want [Sheet1]", sheets)
+	}
+	if panes, err := f.GetPanes("Sheet1"); err != nil || panes.Freeze {
+		t.Errorf("GetPanes() = %+v, %v, want no frozen header", panes, err)
+	}
+
+	for _, name := range []string{"a/b", strings.Repeat("x", 32)} {
+		if err := table.ExportToXLSX(io.Discard, pkg.XLSXOptions{SheetName: name}); err == nil {
+			t.Errorf("ExportToXLSX() with sheet name %q error = nil, want error", name)
+		}
+	}
+}